@@ -3,15 +3,61 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// App environments. Development and test enable tooling that must never be
+// reachable in production, such as the fake payment simulator.
+const (
+	EnvProduction  = "production"
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+)
+
 type AppConfig struct {
-	AppPort          string
-	DBAddr           string
-	JWTSecret        string
-	JWTRefreshSecret string
+	AppEnv    string
+	AppPort   string
+	DBAddr    string
+	JWTSecret string
+
+	// JWTKeysDir holds the RSA or Ed25519 keys access tokens are signed
+	// with. When empty, access tokens are signed with JWTSecret.
+	JWTKeysDir string
+	// JWTSigningKeyID picks the signing key in JWTKeysDir.
+	JWTSigningKeyID string
+
+	// PaymentProvider names the provider payments go through. Production needs
+	// a real provider: the fake one keeps intents in memory and loses them on
+	// restart, so it is only accepted with APP_ENV development or test.
+	PaymentProvider      string
+	PaymentWebhookSecret string
+	PaymentCurrency      string
+
+	// SeatStreamListen makes the seat stream react to Postgres notifications
+	// instead of waiting for its next poll.
+	SeatStreamListen bool
+
+	// QueueTokenSecret signs waiting room admission tokens. It defaults to
+	// JWTSecret.
+	QueueTokenSecret string
+
+	// PublicURL is where links in emails point to.
+	PublicURL string
+
+	// EmailTokenSecret signs email verification links. It defaults to
+	// JWTSecret.
+	EmailTokenSecret string
+
+	// MailDriver is log, file or smtp.
+	MailDriver   string
+	MailFrom     string
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
 }
 
 func SetupConfig(envPath string) (*AppConfig, error) {
@@ -35,15 +81,61 @@ func SetupConfig(envPath string) (*AppConfig, error) {
 		return nil, fmt.Errorf("JWT_SECRET not found")
 	}
 
-	jwtRefreshSecret, ok := os.LookupEnv("JWT_REFRESH_SECRET")
-	if !ok {
-		return nil, fmt.Errorf("JWT_REFRESH_SECRET not found")
+	appEnv := getEnv("APP_ENV", EnvProduction)
+	switch appEnv {
+	case EnvProduction, EnvDevelopment, EnvTest:
+	default:
+		return nil, fmt.Errorf("unknown APP_ENV: %s", appEnv)
+	}
+
+	paymentProvider, ok := os.LookupEnv("PAYMENT_PROVIDER")
+	if !ok || paymentProvider == "" {
+		return nil, fmt.Errorf("PAYMENT_PROVIDER not found")
+	}
+
+	// an empty secret would let anyone sign webhooks
+	paymentWebhookSecret, ok := os.LookupEnv("PAYMENT_WEBHOOK_SECRET")
+	if !ok || paymentWebhookSecret == "" {
+		return nil, fmt.Errorf("PAYMENT_WEBHOOK_SECRET not found")
 	}
 
 	return &AppConfig{
-		AppPort:          appPort,
-		DBAddr:           dbAddr,
-		JWTSecret:        jwtSecret,
-		JWTRefreshSecret: jwtRefreshSecret,
+		AppEnv:    appEnv,
+		AppPort:   appPort,
+		DBAddr:    dbAddr,
+		JWTSecret: jwtSecret,
+
+		JWTKeysDir:      getEnv("JWT_KEYS_DIR", ""),
+		JWTSigningKeyID: getEnv("JWT_SIGNING_KEY_ID", ""),
+
+		PaymentProvider:      paymentProvider,
+		PaymentWebhookSecret: paymentWebhookSecret,
+		PaymentCurrency:      getEnv("PAYMENT_CURRENCY", "THB"),
+
+		SeatStreamListen: getEnv("SEAT_STREAM_LISTEN", "false") == "true",
+		QueueTokenSecret: getEnv("QUEUE_TOKEN_SECRET", jwtSecret),
+
+		PublicURL:        strings.TrimSuffix(getEnv("PUBLIC_URL", "http://localhost"+appPort), "/"),
+		EmailTokenSecret: getEnv("EMAIL_TOKEN_SECRET", jwtSecret),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "log"),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@localhost"),
+		MailDir:      getEnv("MAIL_DIR", "tmp/mail"),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 	}, nil
 }
+
+// IsDevelopment reports whether the app runs in development or test mode.
+func (c *AppConfig) IsDevelopment() bool {
+	return c.AppEnv == EnvDevelopment || c.AppEnv == EnvTest
+}
+
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,373 @@
+// Package allocator picks the best available seats for a group. It works on
+// plain values only, so it can be used and tested without a database.
+package allocator
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+)
+
+type Preference string
+
+const (
+	// PreferCenter favours rows in the middle of a section and seats close
+	// to the middle of a row.
+	PreferCenter Preference = "center"
+	// PreferFront favours the rows nearest the stage, then the middle of
+	// the row.
+	PreferFront Preference = "front"
+	// PreferAisle favours blocks that start or end at an aisle or the end
+	// of a row, then the middle of the section.
+	PreferAisle Preference = "aisle"
+)
+
+var (
+	ErrInvalidQuantity = errors.New("quantity must be greater than zero")
+	ErrNotEnoughSeats  = errors.New("not enough adjacent seats available")
+)
+
+// Seat is one seat of the layout. Unavailable seats are still passed in so
+// the allocator knows where the middle of each row and section is.
+type Seat struct {
+	ID        int64
+	SectionID int64
+	Row       string
+	Number    int
+	Available bool
+	// AisleAfter means the next seat in the row is across an aisle, so the
+	// two are not adjacent.
+	AisleAfter bool
+}
+
+type Request struct {
+	Quantity   int
+	Preference Preference
+	// AllowSplit lets the group be spread over nearby rows when no row
+	// has enough adjacent seats.
+	AllowSplit bool
+}
+
+// Allocate returns req.Quantity available seats, adjacent in one row when
+// possible. Seats are returned in row and seat number order.
+func Allocate(seats []Seat, req Request) ([]Seat, error) {
+	if req.Quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	rows := buildRows(seats)
+
+	if block, ok := bestBlock(rows, req.Quantity, req.Preference); ok {
+		return block.take(), nil
+	}
+
+	if !req.AllowSplit {
+		return nil, ErrNotEnoughSeats
+	}
+
+	return split(rows, req.Quantity, req.Preference)
+}
+
+type row struct {
+	sectionID int64
+	label     string
+	// index is the row's position from the front of its section, out of
+	// count rows.
+	index, count int
+	// center is the middle seat number of the whole row.
+	center float64
+	// runs are the available seats split into sequences of consecutive
+	// numbers.
+	runs [][]Seat
+	// aisle holds the seat numbers at either end of the row or next to an
+	// aisle.
+	aisle map[int]bool
+}
+
+type block struct {
+	row   *row
+	seats []Seat
+	score float64
+}
+
+func (b block) take() []Seat {
+	out := make([]Seat, len(b.seats))
+	copy(out, b.seats)
+	return out
+}
+
+func buildRows(seats []Seat) []*row {
+	type key struct {
+		section int64
+		label   string
+	}
+
+	byRow := map[key][]Seat{}
+	for _, s := range seats {
+		k := key{s.SectionID, s.Row}
+		byRow[k] = append(byRow[k], s)
+	}
+
+	labels := map[int64][]string{}
+	for k := range byRow {
+		labels[k.section] = append(labels[k.section], k.label)
+	}
+
+	var rows []*row
+	for section, ls := range labels {
+		sort.Slice(ls, func(i, j int) bool { return rowLess(ls[i], ls[j]) })
+
+		for i, label := range ls {
+			rs := byRow[key{section, label}]
+			sort.Slice(rs, func(i, j int) bool { return rs[i].Number < rs[j].Number })
+
+			rows = append(rows, &row{
+				sectionID: section,
+				label:     label,
+				index:     i,
+				count:     len(ls),
+				center:    float64(rs[0].Number+rs[len(rs)-1].Number) / 2,
+				runs:      availableRuns(rs),
+				aisle:     aisleSeats(rs),
+			})
+		}
+	}
+
+	// stable order so equal scores resolve the same way every time
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].sectionID != rows[j].sectionID {
+			return rows[i].sectionID < rows[j].sectionID
+		}
+		return rows[i].index < rows[j].index
+	})
+
+	return rows
+}
+
+func availableRuns(seats []Seat) [][]Seat {
+	var runs [][]Seat
+	var cur []Seat
+
+	for _, s := range seats {
+		if !s.Available {
+			if len(cur) > 0 {
+				runs = append(runs, cur)
+			}
+			cur = nil
+			continue
+		}
+		if len(cur) > 0 && (s.Number != cur[len(cur)-1].Number+1 || cur[len(cur)-1].AisleAfter) {
+			runs = append(runs, cur)
+			cur = nil
+		}
+		cur = append(cur, s)
+	}
+	if len(cur) > 0 {
+		runs = append(runs, cur)
+	}
+
+	return runs
+}
+
+func aisleSeats(seats []Seat) map[int]bool {
+	aisle := map[int]bool{
+		seats[0].Number:            true,
+		seats[len(seats)-1].Number: true,
+	}
+	for i, s := range seats {
+		if s.AisleAfter {
+			aisle[s.Number] = true
+			if i+1 < len(seats) {
+				aisle[seats[i+1].Number] = true
+			}
+		}
+	}
+	return aisle
+}
+
+// bestBlock finds the best scored run of n adjacent seats over all rows.
+func bestBlock(rows []*row, n int, pref Preference) (block, bool) {
+	var best block
+	found := false
+
+	for _, r := range rows {
+		if b, ok := r.bestBlock(n, pref); ok && (!found || b.score < best.score) {
+			best = b
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+func (r *row) bestBlock(n int, pref Preference) (block, bool) {
+	var best block
+	found := false
+
+	for _, run := range r.runs {
+		for i := 0; i+n <= len(run); i++ {
+			seats := run[i : i+n]
+			b := block{row: r, seats: seats, score: r.score(seats, pref)}
+			if !found || b.score < best.score {
+				best = b
+				found = true
+			}
+		}
+	}
+
+	return best, found
+}
+
+// score is lower for better blocks. Row position dominates for PreferFront
+// and reaching an aisle for PreferAisle; for PreferCenter row and seat
+// distance from the middle weigh the same.
+func (r *row) score(seats []Seat, pref Preference) float64 {
+	first, last := seats[0].Number, seats[len(seats)-1].Number
+	seatOff := math.Abs(float64(first+last)/2 - r.center)
+	rowOff := math.Abs(float64(r.index) - float64(r.count-1)/2)
+
+	switch pref {
+	case PreferFront:
+		return float64(r.index)*1000 + seatOff
+	case PreferAisle:
+		if r.aisle[first] || r.aisle[last] {
+			return rowOff + seatOff
+		}
+		return 1000 + rowOff + seatOff
+	default:
+		return rowOff + seatOff
+	}
+}
+
+func (r *row) largestRun() int {
+	largest := 0
+	for _, run := range r.runs {
+		if len(run) > largest {
+			largest = len(run)
+		}
+	}
+	return largest
+}
+
+func (r *row) remove(seats []Seat) {
+	taken := make(map[int64]bool, len(seats))
+	for _, s := range seats {
+		taken[s.ID] = true
+	}
+
+	var runs [][]Seat
+	for _, run := range r.runs {
+		var cur []Seat
+		for _, s := range run {
+			if taken[s.ID] {
+				if len(cur) > 0 {
+					runs = append(runs, cur)
+				}
+				cur = nil
+				continue
+			}
+			cur = append(cur, s)
+		}
+		if len(cur) > 0 {
+			runs = append(runs, cur)
+		}
+	}
+	r.runs = runs
+}
+
+// split seats the group in as few pieces as possible: the largest block that
+// fits goes in the best row, the rest in the rows nearest to it. Rows of other
+// sections are only used when the first section is full.
+func split(rows []*row, n int, pref Preference) ([]Seat, error) {
+	available := 0
+	largest := 0
+	for _, r := range rows {
+		for _, run := range r.runs {
+			available += len(run)
+		}
+		if l := r.largestRun(); l > largest {
+			largest = l
+		}
+	}
+	if available < n {
+		return nil, ErrNotEnoughSeats
+	}
+
+	first, _ := bestBlock(rows, min(largest, n), pref)
+	anchor := first.row
+	anchor.remove(first.seats)
+
+	out := first.take()
+	remaining := n - len(out)
+
+	// each further piece is the largest block left, nearest to the first
+	// row, then the best scored
+	for remaining > 0 {
+		var next block
+		found := false
+
+		for _, r := range rows {
+			size := min(remaining, r.largestRun())
+			if size == 0 {
+				continue
+			}
+			b, _ := r.bestBlock(size, pref)
+			if !found || betterPiece(anchor, b, next) {
+				next = b
+				found = true
+			}
+		}
+
+		next.row.remove(next.seats)
+		out = append(out, next.take()...)
+		remaining -= len(next.seats)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SectionID != out[j].SectionID {
+			return out[i].SectionID < out[j].SectionID
+		}
+		if out[i].Row != out[j].Row {
+			return rowLess(out[i].Row, out[j].Row)
+		}
+		return out[i].Number < out[j].Number
+	})
+
+	return out, nil
+}
+
+func betterPiece(anchor *row, a, b block) bool {
+	if len(a.seats) != len(b.seats) {
+		return len(a.seats) > len(b.seats)
+	}
+	if da, db := rowDistance(anchor, a.row), rowDistance(anchor, b.row); da != db {
+		return da < db
+	}
+	return a.score < b.score
+}
+
+func rowDistance(a, b *row) int {
+	if a.sectionID != b.sectionID {
+		return math.MaxInt32
+	}
+	d := a.index - b.index
+	if d < 0 {
+		d = -d
+	}
+	return d
+}
+
+// rowLess orders row labels the way venues number them: numerically when
+// both are numbers, otherwise A..Z before AA..ZZ.
+func rowLess(a, b string) bool {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	if aErr == nil && bErr == nil {
+		return an < bn
+	}
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
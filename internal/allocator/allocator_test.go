@@ -0,0 +1,238 @@
+package allocator
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+type rowSpec struct {
+	section    int64
+	label      string
+	seats      int
+	taken      []int
+	aisleAfter []int
+}
+
+// layout builds the seats of the given rows, numbered from 1.
+func layout(specs ...rowSpec) []Seat {
+	var seats []Seat
+	for _, spec := range specs {
+		for n := 1; n <= spec.seats; n++ {
+			seats = append(seats, Seat{
+				ID:         int64(len(seats) + 1),
+				SectionID:  spec.section,
+				Row:        spec.label,
+				Number:     n,
+				Available:  !slices.Contains(spec.taken, n),
+				AisleAfter: slices.Contains(spec.aisleAfter, n),
+			})
+		}
+	}
+	return seats
+}
+
+// fullRows is one section of count rows labelled from A, each with the
+// same number of free seats.
+func fullRows(count, seats int) []rowSpec {
+	specs := make([]rowSpec, count)
+	for i := range specs {
+		specs[i] = rowSpec{section: 1, label: string(rune('A' + i)), seats: seats}
+	}
+	return specs
+}
+
+func labels(seats []Seat) []string {
+	out := make([]string, len(seats))
+	for i, s := range seats {
+		out[i] = fmt.Sprintf("%s%d", s.Row, s.Number)
+	}
+	return out
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		seats   []Seat
+		req     Request
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "center picks the middle row and middle seats",
+			seats: layout(fullRows(5, 10)...),
+			req:   Request{Quantity: 2, Preference: PreferCenter},
+			want:  []string{"C5", "C6"},
+		},
+		{
+			name:  "empty preference behaves like center",
+			seats: layout(fullRows(5, 10)...),
+			req:   Request{Quantity: 2},
+			want:  []string{"C5", "C6"},
+		},
+		{
+			name:  "front picks the first row",
+			seats: layout(fullRows(5, 10)...),
+			req:   Request{Quantity: 2, Preference: PreferFront},
+			want:  []string{"A5", "A6"},
+		},
+		{
+			name: "front skips a full first row",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 4, taken: []int{1, 2, 3, 4}},
+				rowSpec{section: 1, label: "B", seats: 4},
+				rowSpec{section: 1, label: "C", seats: 4},
+			),
+			req:  Request{Quantity: 2, Preference: PreferFront},
+			want: []string{"B2", "B3"},
+		},
+		{
+			name:  "aisle picks a row end without inner aisles",
+			seats: layout(fullRows(3, 10)...),
+			req:   Request{Quantity: 2, Preference: PreferAisle},
+			want:  []string{"B1", "B2"},
+		},
+		{
+			name: "aisle picks the block next to an inner aisle closest to the middle",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 10, aisleAfter: []int{3}},
+				rowSpec{section: 1, label: "B", seats: 10, aisleAfter: []int{3}},
+				rowSpec{section: 1, label: "C", seats: 10, aisleAfter: []int{3}},
+			),
+			req:  Request{Quantity: 2, Preference: PreferAisle},
+			want: []string{"B4", "B5"},
+		},
+		{
+			name: "group does not straddle an aisle",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 6, aisleAfter: []int{3}},
+			),
+			req:     Request{Quantity: 4},
+			wantErr: ErrNotEnoughSeats,
+		},
+		{
+			name: "group straddling an aisle is split when allowed",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 6, aisleAfter: []int{3}},
+			),
+			req:  Request{Quantity: 4, AllowSplit: true},
+			want: []string{"A1", "A2", "A3", "A4"},
+		},
+		{
+			name: "aisle in another row leaves a full row usable",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 6, aisleAfter: []int{3}},
+				rowSpec{section: 1, label: "B", seats: 6},
+			),
+			req:  Request{Quantity: 4},
+			want: []string{"B2", "B3", "B4", "B5"},
+		},
+		{
+			name:    "no row fits and split is off",
+			seats:   layout(fullRows(2, 3)...),
+			req:     Request{Quantity: 4},
+			wantErr: ErrNotEnoughSeats,
+		},
+		{
+			name:  "split fills the best row then the nearest one",
+			seats: layout(fullRows(2, 3)...),
+			req:   Request{Quantity: 4, AllowSplit: true},
+			want:  []string{"A1", "A2", "A3", "B2"},
+		},
+		{
+			name: "split prefers the same section",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 3, taken: []int{1, 3}},
+				rowSpec{section: 2, label: "A", seats: 3},
+				rowSpec{section: 2, label: "B", seats: 3},
+				rowSpec{section: 2, label: "C", seats: 3},
+			),
+			req:  Request{Quantity: 4, AllowSplit: true},
+			want: []string{"A2", "B1", "B2", "B3"},
+		},
+		{
+			name:    "not enough seats even when split",
+			seats:   layout(rowSpec{section: 1, label: "A", seats: 4, taken: []int{2, 3}}),
+			req:     Request{Quantity: 3, AllowSplit: true},
+			wantErr: ErrNotEnoughSeats,
+		},
+		{
+			name:    "no seats at all",
+			seats:   nil,
+			req:     Request{Quantity: 1},
+			wantErr: ErrNotEnoughSeats,
+		},
+		{
+			name:    "zero quantity",
+			seats:   layout(fullRows(1, 4)...),
+			req:     Request{Quantity: 0},
+			wantErr: ErrInvalidQuantity,
+		},
+		{
+			name: "fragmented row keeps seats in one gap",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 10, taken: []int{3, 4, 7, 8}},
+			),
+			req:  Request{Quantity: 2},
+			want: []string{"A5", "A6"},
+		},
+		{
+			name: "fragmented row has no gap large enough",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 10, taken: []int{3, 4, 7, 8}},
+			),
+			req:     Request{Quantity: 3},
+			wantErr: ErrNotEnoughSeats,
+		},
+		{
+			name: "fragmented rows are skipped for a row with room",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 10, taken: []int{3, 4, 7, 8}},
+				rowSpec{section: 1, label: "B", seats: 10, taken: []int{3, 4, 5, 6, 7, 8, 9, 10}},
+				rowSpec{section: 1, label: "C", seats: 10},
+			),
+			req:  Request{Quantity: 3},
+			want: []string{"C4", "C5", "C6"},
+		},
+		{
+			name: "fragmented rows are combined when split",
+			seats: layout(
+				rowSpec{section: 1, label: "A", seats: 5, taken: []int{2, 4}},
+				rowSpec{section: 1, label: "B", seats: 5, taken: []int{1, 2, 4, 5}},
+			),
+			req:  Request{Quantity: 4, AllowSplit: true},
+			want: []string{"A1", "A3", "A5", "B3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Allocate(tt.seats, tt.req)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(labels(got), tt.want) {
+				t.Fatalf("seats = %v, want %v", labels(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRowLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"A", "B", true},
+		{"B", "A", false},
+		{"Z", "AA", true},
+		{"2", "10", true},
+		{"10", "2", false},
+	}
+
+	for _, tt := range tests {
+		if got := rowLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("rowLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,13 +1,13 @@
 package handler
 
 import (
-	"database/sql"
 	"errors"
 
 	"github.com/codepnw/go-ticket-booking/internal/api/rest"
 	"github.com/codepnw/go-ticket-booking/internal/dto"
 	"github.com/codepnw/go-ticket-booking/internal/errs"
 	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/codepnw/go-ticket-booking/internal/waitingroom"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
@@ -20,14 +20,12 @@ const (
 )
 
 type bookingHandler struct {
-	db        *sql.DB
 	uc        usecase.BookingUsecase
 	validator *validator.Validate
 }
 
-func NewBookingHandler(db *sql.DB, uc usecase.BookingUsecase) *bookingHandler {
+func NewBookingHandler(uc usecase.BookingUsecase) *bookingHandler {
 	return &bookingHandler{
-		db:        db,
 		uc:        uc,
 		validator: validator.New(),
 	}
@@ -43,10 +41,42 @@ func (h *bookingHandler) CreateBooking(ctx *fiber.Ctx) error {
 	if err := h.validator.Struct(req); err != nil {
 		return rest.BadRequestResponse(ctx, err.Error())
 	}
+	req.AdmissionToken = ctx.Get(waitingroom.TokenHeader)
 
 	// usecase
 	if err := h.uc.Create(ctx.Context(), &req); err != nil {
-		return rest.InternalError(ctx, err)
+		switch err {
+		case errs.ErrAdmissionRequired, errs.ErrInvalidAdmission:
+			return rest.ForbiddenErrorResponse(ctx, err)
+		case errs.ErrSeatAlreadyBooked:
+			return rest.ConflictResponse(ctx, err)
+		case errs.ErrTicketTypeSoldOut:
+			return rest.ConflictResponse(ctx, err)
+		case errs.ErrInvalidTicketTypeEvent:
+			return rest.BadRequestResponse(ctx, err.Error())
+		case errs.ErrSeatNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrSectionNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrInvalidSeatEvent:
+			return rest.BadRequestResponse(ctx, err.Error())
+		case errs.ErrEventNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrEventNotOnSale:
+			return rest.ConflictResponse(ctx, err)
+		case errs.ErrEmailNotVerified:
+			return rest.ForbiddenCodeResponse(ctx, err, errs.CodeEmailNotVerified)
+		case errs.ErrEventTicketLimit:
+			return rest.ConflictCodeResponse(ctx, err, errs.CodeEventTicketLimit)
+		case errs.ErrTicketTypeLimit:
+			return rest.ConflictCodeResponse(ctx, err, errs.CodeTicketTypeLimit)
+		case errs.ErrInvalidPromoCode, errs.ErrPromotionNotApplicable, errs.ErrPromotionNotStackable:
+			return rest.BadRequestResponse(ctx, err.Error())
+		case errs.ErrPromotionUsageLimit:
+			return rest.ConflictResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
 	}
 
 	return rest.CreatedResponse(ctx, "booking created", req)
@@ -94,10 +124,11 @@ func (h *bookingHandler) GetBookingsByStatus(ctx *fiber.Ctx) error {
 		string(dto.StatusPending):   true,
 		string(dto.StatusConfirmed): true,
 		string(dto.StatusCancelled): true,
+		string(dto.StatusExpired):   true,
 	}
 
 	if !validStatus[status] {
-		return rest.BadRequestResponse(ctx, "bookings status: ['pending', 'confirmed', 'cancelled']")
+		return rest.BadRequestResponse(ctx, "bookings status: ['pending', 'confirmed', 'cancelled', 'expired']")
 	}
 
 	bookings, err := h.uc.ListByStatus(ctx.Context(), status)
@@ -122,31 +153,6 @@ func (h *bookingHandler) GetBookingsByEvent(ctx *fiber.Ctx) error {
 	return rest.SuccessResponse(ctx, "bookings by event", bookings)
 }
 
-func (h *bookingHandler) ConfirmBooking(ctx *fiber.Ctx) error {
-	id, err := rest.GetParamsID(ctx, bookingID)
-	if err != nil {
-		return err
-	}
-
-	err = h.uc.ConfirmBooking(ctx.Context(), id)
-	if err != nil {
-		switch err {
-		case errs.ErrBookingAlreadyConfirmed:
-			return rest.ConflictResponse(ctx, err)
-		case errs.ErrBookingAlreadyCancelled:
-			return rest.ConflictResponse(ctx, err)
-		case errs.ErrSeatAlreadyBooked:
-			return rest.ConflictResponse(ctx, err)
-		case errs.ErrBookingNotFound:
-			return rest.NotFoundResponse(ctx, err.Error())
-		default:
-			return rest.InternalError(ctx, err)
-		}
-	}
-
-	return rest.SuccessResponse(ctx, "booking confirmed successfully", nil)
-}
-
 func (h *bookingHandler) CancelBooking(ctx *fiber.Ctx) error {
 	id, err := rest.GetParamsID(ctx, bookingID)
 	if err != nil {
@@ -160,6 +166,10 @@ func (h *bookingHandler) CancelBooking(ctx *fiber.Ctx) error {
 			return rest.ConflictResponse(ctx, err)
 		case errs.ErrBookingAlreadyCancelled:
 			return rest.ConflictResponse(ctx, err)
+		case errs.ErrBookingHoldExpired:
+			return rest.ConflictResponse(ctx, err)
+		case errs.ErrBookingPartOfOrder:
+			return rest.ConflictResponse(ctx, err)
 		case errs.ErrBookingNotFound:
 			return rest.NotFoundResponse(ctx, err.Error())
 		default:
@@ -208,8 +218,12 @@ func (h *bookingHandler) UpdateSeat(ctx *fiber.Ctx) error {
 			return rest.NotFoundResponse(ctx, err.Error())
 		case errs.ErrInvalidSeatEvent:
 			return rest.BadRequestResponse(ctx, err.Error())
+		case errs.ErrTicketTypeMismatch:
+			return rest.BadRequestResponse(ctx, err.Error())
 		case errs.ErrBookingNotPending:
 			return rest.BadRequestResponse(ctx, err.Error())
+		case errs.ErrBookingHoldExpired:
+			return rest.ConflictResponse(ctx, err)
 		default:
 			return rest.InternalError(ctx, err)
 		}
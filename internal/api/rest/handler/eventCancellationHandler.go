@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type eventCancellationHandler struct {
+	uc        usecase.EventCancellationUsecase
+	validator *validator.Validate
+}
+
+func NewEventCancellationHandler(uc usecase.EventCancellationUsecase) *eventCancellationHandler {
+	return &eventCancellationHandler{
+		uc:        uc,
+		validator: validator.New(),
+	}
+}
+
+func (h *eventCancellationHandler) CancelEvent(ctx *fiber.Ctx) error {
+	admin, ok := auth.GetCurrentUser(ctx)
+	if !ok || admin.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.CancelEventRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	c, err := h.uc.CancelEvent(ctx.Context(), id, admin.ID, req.Reason)
+	if err != nil {
+		switch err {
+		case errs.ErrEventNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrEventAlreadyCancelled:
+			return rest.ConflictResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.SuccessResponse(ctx, "event cancelled, bookings are being released", c)
+}
+
+func (h *eventCancellationHandler) GetCancellation(ctx *fiber.Ctx) error {
+	id, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	c, err := h.uc.GetCancellation(ctx.Context(), id)
+	if err != nil {
+		if err == errs.ErrCancellationNotFound {
+			return rest.NotFoundResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "event cancellation progress", c)
+}
+
+func (h *eventCancellationHandler) RetryCancellation(ctx *fiber.Ctx) error {
+	admin, ok := auth.GetCurrentUser(ctx)
+	if !ok || admin.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	c, err := h.uc.RetryCancellation(ctx.Context(), id)
+	if err != nil {
+		switch err {
+		case errs.ErrCancellationNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrCancellationNotRetryable:
+			return rest.ConflictResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.SuccessResponse(ctx, "event cancellation restarted, failed bookings are being released", c)
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"github.com/codepnw/go-ticket-booking/internal/api/rest"
 	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
 	"github.com/codepnw/go-ticket-booking/internal/usecase"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -26,8 +28,15 @@ func (h *eventHandler) CreateEvent(ctx *fiber.Ctx) error {
 		return rest.BadRequestResponse(ctx, err.Error())
 	}
 
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
 	event, err := h.uc.CreateEvent(ctx.Context(), &req)
 	if err != nil {
+		if err == errs.ErrInvalidSalesWindow {
+			return rest.BadRequestResponse(ctx, err.Error())
+		}
 		return rest.InternalError(ctx, err)
 	}
 
@@ -45,8 +54,19 @@ func (h *eventHandler) UpdateEvent(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
 	if err := h.uc.UpdateEvent(ctx.Context(), id, &req); err != nil {
-		return rest.InternalError(ctx, err)
+		switch err {
+		case errs.ErrEventNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrInvalidSalesWindow, errs.ErrNoFieldsToUpdate:
+			return rest.BadRequestResponse(ctx, err.Error())
+		default:
+			return rest.InternalError(ctx, err)
+		}
 	}
 
 	return rest.SuccessResponse(ctx, "event updated", nil)
@@ -82,12 +102,68 @@ func (h *eventHandler) DeleteEvent(ctx *fiber.Ctx) error {
 	}
 
 	if err := h.uc.DeleteEvent(ctx.Context(), id); err != nil {
-		return rest.InternalError(ctx, err)
+		switch err {
+		case errs.ErrEventNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrEventNotDraft:
+			return rest.ConflictResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
 	}
 
 	return rest.SuccessResponse(ctx, "event deleted", nil)
 }
 
+func (h *eventHandler) ChangeEventStatus(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.EventStatusRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	transition, err := h.uc.ChangeStatus(ctx.Context(), id, user.ID, &req)
+	if err != nil {
+		switch err {
+		case errs.ErrEventNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrInvalidEventTransition:
+			return rest.ConflictResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.SuccessResponse(ctx, "event status updated", transition)
+}
+
+func (h *eventHandler) ListEventTransitions(ctx *fiber.Ctx) error {
+	id, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	transitions, err := h.uc.ListTransitions(ctx.Context(), id)
+	if err != nil {
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "event status history", transitions)
+}
+
 func (h *eventHandler) CreateLocation(ctx *fiber.Ctx) error {
 	var req dto.LocationRequest
 
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// jwksMaxAge lets verifiers cache the key set, short enough that a new key
+// is picked up well before it starts signing.
+const jwksMaxAge = "public, max-age=300"
+
+type jwksHandler struct {
+	auth auth.Auth
+}
+
+func NewJWKSHandler(auth auth.Auth) *jwksHandler {
+	return &jwksHandler{auth: auth}
+}
+
+// GetJWKS serves the public keys access tokens are verified with. It is a
+// bare JWK Set, not the usual response envelope, so JWT libraries can read
+// it directly.
+func (h *jwksHandler) GetJWKS(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, jwksMaxAge)
+	return ctx.JSON(h.auth.JWKS())
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/codepnw/go-ticket-booking/internal/waitingroom"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const orderID = "orderID"
+
+type orderHandler struct {
+	uc        usecase.OrderUsecase
+	validator *validator.Validate
+}
+
+func NewOrderHandler(uc usecase.OrderUsecase) *orderHandler {
+	return &orderHandler{
+		uc:        uc,
+		validator: validator.New(),
+	}
+}
+
+func (h *orderHandler) CreateOrder(ctx *fiber.Ctx) error {
+	var req dto.CreateOrderRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+	req.AdmissionToken = ctx.Get(waitingroom.TokenHeader)
+
+	order, err := h.uc.Create(ctx.Context(), &req)
+	if err != nil {
+		switch err {
+		case errs.ErrAdmissionRequired, errs.ErrInvalidAdmission:
+			return rest.ForbiddenErrorResponse(ctx, err)
+		case errs.ErrSeatNotFound, errs.ErrSectionNotFound, errs.ErrEventNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrInvalidSeatEvent, errs.ErrInvalidTicketTypeEvent:
+			return rest.BadRequestResponse(ctx, err.Error())
+		case errs.ErrSeatAlreadyBooked, errs.ErrTicketTypeSoldOut, errs.ErrEventNotOnSale:
+			return rest.ConflictResponse(ctx, err)
+		case errs.ErrEmailNotVerified:
+			return rest.ForbiddenCodeResponse(ctx, err, errs.CodeEmailNotVerified)
+		case errs.ErrEventTicketLimit:
+			return rest.ConflictCodeResponse(ctx, err, errs.CodeEventTicketLimit)
+		case errs.ErrTicketTypeLimit:
+			return rest.ConflictCodeResponse(ctx, err, errs.CodeTicketTypeLimit)
+		case errs.ErrInvalidPromoCode, errs.ErrPromotionNotApplicable, errs.ErrPromotionNotStackable:
+			return rest.BadRequestResponse(ctx, err.Error())
+		case errs.ErrPromotionUsageLimit:
+			return rest.ConflictResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.CreatedResponse(ctx, "order created", order)
+}
+
+func (h *orderHandler) CreateBestAvailableOrder(ctx *fiber.Ctx) error {
+	var req dto.BestAvailableOrderRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+	req.AdmissionToken = ctx.Get(waitingroom.TokenHeader)
+
+	order, err := h.uc.CreateBestAvailable(ctx.Context(), &req)
+	if err != nil {
+		switch err {
+		case errs.ErrAdmissionRequired, errs.ErrInvalidAdmission:
+			return rest.ForbiddenErrorResponse(ctx, err)
+		case errs.ErrEventNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrNotEnoughSeats, errs.ErrSeatAlreadyBooked, errs.ErrTicketTypeSoldOut, errs.ErrEventNotOnSale:
+			return rest.ConflictResponse(ctx, err)
+		case errs.ErrEmailNotVerified:
+			return rest.ForbiddenCodeResponse(ctx, err, errs.CodeEmailNotVerified)
+		case errs.ErrEventTicketLimit:
+			return rest.ConflictCodeResponse(ctx, err, errs.CodeEventTicketLimit)
+		case errs.ErrTicketTypeLimit:
+			return rest.ConflictCodeResponse(ctx, err, errs.CodeTicketTypeLimit)
+		case errs.ErrInvalidPromoCode, errs.ErrPromotionNotApplicable, errs.ErrPromotionNotStackable:
+			return rest.BadRequestResponse(ctx, err.Error())
+		case errs.ErrPromotionUsageLimit:
+			return rest.ConflictResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.CreatedResponse(ctx, "order created", order)
+}
+
+func (h *orderHandler) GetOrderByID(ctx *fiber.Ctx) error {
+	id, err := rest.GetParamsID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	order, err := h.uc.GetByID(ctx.Context(), id)
+	if err != nil {
+		if err == errs.ErrOrderNotFound {
+			return rest.NotFoundResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "order detail fetched", order)
+}
+
+func (h *orderHandler) CancelOrder(ctx *fiber.Ctx) error {
+	id, err := rest.GetParamsID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	if err = h.uc.CancelOrder(ctx.Context(), id); err != nil {
+		return h.orderStatusError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "order cancelled successfully", nil)
+}
+
+func (h *orderHandler) orderStatusError(ctx *fiber.Ctx, err error) error {
+	switch err {
+	case errs.ErrOrderAlreadyConfirmed,
+		errs.ErrOrderAlreadyCancelled,
+		errs.ErrOrderHoldExpired,
+		errs.ErrBookingNotPending,
+		errs.ErrSeatAlreadyBooked:
+		return rest.ConflictResponse(ctx, err)
+	case errs.ErrOrderNotFound:
+		return rest.NotFoundResponse(ctx, err.Error())
+	default:
+		return rest.InternalError(ctx, err)
+	}
+}
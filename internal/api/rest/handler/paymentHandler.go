@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/payment"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	paymentID = "paymentID"
+
+	// SignatureHeader carries the provider's HMAC of the webhook body.
+	SignatureHeader = "X-Payment-Signature"
+)
+
+type paymentHandler struct {
+	uc usecase.PaymentUsecase
+}
+
+func NewPaymentHandler(uc usecase.PaymentUsecase) *paymentHandler {
+	return &paymentHandler{uc: uc}
+}
+
+func (h *paymentHandler) PayBooking(ctx *fiber.Ctx) error {
+	id, err := rest.GetParamsID(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+
+	p, err := h.uc.PayBooking(ctx.Context(), id)
+	if err != nil {
+		if err == errs.ErrPaymentPending {
+			return rest.ConflictDataResponse(ctx, err, p)
+		}
+		return h.paymentError(ctx, err)
+	}
+
+	return rest.CreatedResponse(ctx, "payment created", p)
+}
+
+func (h *paymentHandler) PayOrder(ctx *fiber.Ctx) error {
+	id, err := rest.GetParamsID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	p, err := h.uc.PayOrder(ctx.Context(), id)
+	if err != nil {
+		if err == errs.ErrPaymentPending {
+			return rest.ConflictDataResponse(ctx, err, p)
+		}
+		return h.paymentError(ctx, err)
+	}
+
+	return rest.CreatedResponse(ctx, "payment created", p)
+}
+
+func (h *paymentHandler) GetPaymentByID(ctx *fiber.Ctx) error {
+	id, err := rest.GetParamsID(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	p, err := h.uc.GetByID(ctx.Context(), id)
+	if err != nil {
+		if err == errs.ErrPaymentNotFound {
+			return rest.NotFoundResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "payment detail fetched", p)
+}
+
+func (h *paymentHandler) Webhook(ctx *fiber.Ctx) error {
+	err := h.uc.HandleWebhook(ctx.Context(), ctx.Body(), ctx.Get(SignatureHeader))
+	if err != nil {
+		switch err {
+		case payment.ErrInvalidSignature:
+			return rest.UnauthorizedResponse(ctx)
+		case errs.ErrPaymentNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrPaymentAmountMismatch:
+			return rest.BadRequestResponse(ctx, err.Error())
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.SuccessResponse(ctx, "webhook processed", nil)
+}
+
+// SimulateWebhook drives the fake provider: it settles the intent and feeds
+// the signed notification through the regular webhook path. Admin only.
+func (h *paymentHandler) SimulateWebhook(fake *payment.FakeProvider) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		admin, ok := auth.GetCurrentUser(ctx)
+		if !ok || admin.Role != string(dto.RoleAdmin) {
+			return rest.ForbiddenResponse(ctx)
+		}
+
+		var req struct {
+			IntentID string            `json:"intent_id"`
+			Type     payment.EventType `json:"type"`
+		}
+
+		if err := ctx.BodyParser(&req); err != nil {
+			return rest.BadRequestResponse(ctx, err.Error())
+		}
+
+		payload, sig, err := fake.Simulate(req.IntentID, req.Type)
+		if err != nil {
+			if err == payment.ErrIntentNotFound {
+				return rest.NotFoundResponse(ctx, err.Error())
+			}
+			return rest.BadRequestResponse(ctx, err.Error())
+		}
+
+		ctx.Request().SetBody(payload)
+		ctx.Request().Header.Set(SignatureHeader, sig)
+
+		return h.Webhook(ctx)
+	}
+}
+
+func (h *paymentHandler) paymentError(ctx *fiber.Ctx, err error) error {
+	switch err {
+	case errs.ErrBookingNotFound, errs.ErrOrderNotFound:
+		return rest.NotFoundResponse(ctx, err.Error())
+	case errs.ErrBookingNotPending,
+		errs.ErrBookingHoldExpired,
+		errs.ErrBookingPartOfOrder,
+		errs.ErrOrderHoldExpired,
+		errs.ErrSeatAlreadyBooked:
+		return rest.ConflictResponse(ctx, err)
+	default:
+		return rest.InternalError(ctx, err)
+	}
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const promotionID = "promotionID"
+
+type promotionHandler struct {
+	uc        usecase.PromotionUsecase
+	validator *validator.Validate
+}
+
+func NewPromotionHandler(uc usecase.PromotionUsecase) *promotionHandler {
+	return &promotionHandler{
+		uc:        uc,
+		validator: validator.New(),
+	}
+}
+
+func (h *promotionHandler) CreatePromotion(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	var req dto.PromotionRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	p, err := h.uc.Create(ctx.Context(), &req)
+	if err != nil {
+		return h.promotionError(ctx, err)
+	}
+
+	return rest.CreatedResponse(ctx, "promotion created", p)
+}
+
+func (h *promotionHandler) ListPromotions(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	promotions, err := h.uc.List(ctx.Context())
+	if err != nil {
+		return h.promotionError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "promotions fetched", promotions)
+}
+
+func (h *promotionHandler) GetPromotion(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, promotionID)
+	if err != nil {
+		return err
+	}
+
+	p, err := h.uc.GetByID(ctx.Context(), id)
+	if err != nil {
+		return h.promotionError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "promotion fetched", p)
+}
+
+func (h *promotionHandler) UpdatePromotion(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, promotionID)
+	if err != nil {
+		return err
+	}
+
+	var req dto.PromotionUpdateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	p, err := h.uc.Update(ctx.Context(), id, &req)
+	if err != nil {
+		return h.promotionError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "promotion updated", p)
+}
+
+func (h *promotionHandler) DeletePromotion(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, promotionID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.uc.Delete(ctx.Context(), id); err != nil {
+		return h.promotionError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "promotion deleted", nil)
+}
+
+func (h *promotionHandler) GetRedemptionReport(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, promotionID)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.uc.Report(ctx.Context(), id)
+	if err != nil {
+		return h.promotionError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "promotion report fetched", report)
+}
+
+func (h *promotionHandler) promotionError(ctx *fiber.Ctx, err error) error {
+	switch err {
+	case errs.ErrPromotionNotFound, errs.ErrEventNotFound, errs.ErrTicketTypeNotFound:
+		return rest.NotFoundResponse(ctx, err.Error())
+	case errs.ErrNoFieldsToUpdate, errs.ErrInvalidPromotion, errs.ErrInvalidTicketTypeEvent:
+		return rest.BadRequestResponse(ctx, err.Error())
+	case errs.ErrPromoCodeTaken, errs.ErrPromotionInUse:
+		return rest.ConflictResponse(ctx, err)
+	default:
+		return rest.InternalError(ctx, err)
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type refundHandler struct {
+	uc        usecase.RefundUsecase
+	validator *validator.Validate
+}
+
+func NewRefundHandler(uc usecase.RefundUsecase) *refundHandler {
+	return &refundHandler{
+		uc:        uc,
+		validator: validator.New(),
+	}
+}
+
+func (h *refundHandler) RefundBooking(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+
+	refund, err := h.uc.RefundBooking(ctx.Context(), id, user.ID)
+	if err != nil {
+		return h.refundError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "booking refunded", refund)
+}
+
+func (h *refundHandler) ListRefunds(ctx *fiber.Ctx) error {
+	id, err := rest.GetParamsID(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+
+	refunds, err := h.uc.ListByBooking(ctx.Context(), id)
+	if err != nil {
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "refunds fetched", refunds)
+}
+
+func (h *refundHandler) AdminForceRefund(ctx *fiber.Ctx) error {
+	admin, ok := auth.GetCurrentUser(ctx)
+	if !ok || admin.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+
+	var req dto.ForceRefundRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	refund, err := h.uc.ForceRefund(ctx.Context(), id, admin.ID, &req)
+	if err != nil {
+		return h.refundError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "booking refunded", refund)
+}
+
+func (h *refundHandler) refundError(ctx *fiber.Ctx, err error) error {
+	switch err {
+	case errs.ErrBookingNotFound, errs.ErrEventNotFound, errs.ErrPaymentNotFound:
+		return rest.NotFoundResponse(ctx, err.Error())
+	case errs.ErrBookingNotConfirmed, errs.ErrRefundWindowClosed:
+		return rest.ConflictResponse(ctx, err)
+	case errs.ErrRefundExceedsPaid:
+		return rest.BadRequestResponse(ctx, err.Error())
+	default:
+		return rest.InternalError(ctx, err)
+	}
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"database/sql"
 	"errors"
 
 	"github.com/codepnw/go-ticket-booking/internal/api/rest"
@@ -15,14 +14,12 @@ import (
 type seatHandler struct {
 	uc        usecase.SeatUsecase
 	validator *validator.Validate
-	db        *sql.DB
 }
 
-func NewSeatHandler(db *sql.DB, uc usecase.SeatUsecase) *seatHandler {
+func NewSeatHandler(uc usecase.SeatUsecase) *seatHandler {
 	return &seatHandler{
 		uc:        uc,
 		validator: validator.New(),
-		db:        db,
 	}
 }
 
@@ -37,24 +34,44 @@ func (h *seatHandler) CreateSeats(ctx *fiber.Ctx) error {
 		return rest.BadRequestResponse(ctx, err.Error())
 	}
 
-	// begin tx
-	tx, err := h.db.BeginTx(ctx.Context(), nil)
-	if err != nil {
+	// usecase
+	if err := h.uc.CreateSeats(ctx.Context(), &req); err != nil {
 		return rest.InternalError(ctx, err)
 	}
-	defer tx.Rollback()
 
-	// usecase
-	if err = h.uc.CreateSeats(ctx.Context(), tx, &req); err != nil {
-		return rest.InternalError(ctx, err)
+	return rest.SuccessResponse(ctx, "create seats success", req)
+}
+
+func (h *seatHandler) GenerateSeats(ctx *fiber.Ctx) error {
+	var req dto.GenerateSeatsRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
 	}
 
-	// commit tx
-	if err = tx.Commit(); err != nil {
-		return rest.InternalError(ctx, err)
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
 	}
 
-	return rest.SuccessResponse(ctx, "create seats success", req)
+	res, err := h.uc.GenerateSeats(ctx.Context(), &req)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidSeatSpec) {
+			return rest.BadRequestResponse(ctx, err.Error())
+		}
+
+		switch err {
+		case errs.ErrSectionNotFound, errs.ErrEventNotFound, errs.ErrLocationNotFound, errs.ErrTicketTypeNotFound:
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errs.ErrInvalidTicketTypeEvent:
+			return rest.BadRequestResponse(ctx, err.Error())
+		case errs.ErrSeatExists, errs.ErrSectionSeatCountExceeded, errs.ErrLocationCapacityExceeded:
+			return rest.ConflictResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.CreatedResponse(ctx, "seats generated", res)
 }
 
 func (h *seatHandler) GetSeatsBySectionID(ctx *fiber.Ctx) error {
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/seatmap"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type seatMapHandler struct {
+	uc        usecase.SeatMapUsecase
+	validator *validator.Validate
+}
+
+func NewSeatMapHandler(uc usecase.SeatMapUsecase) *seatMapHandler {
+	return &seatMapHandler{
+		uc:        uc,
+		validator: validator.New(),
+	}
+}
+
+func (h *seatMapHandler) ExportSeatMap(ctx *fiber.Ctx) error {
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	format := ctx.Query("format", seatmap.FormatJSON)
+	if format != seatmap.FormatCSV && format != seatmap.FormatJSON {
+		return rest.BadRequestResponse(ctx, seatmap.ErrUnknownFormat.Error())
+	}
+
+	m, err := h.uc.Export(ctx.Context(), eventID)
+	if err != nil {
+		if err == errs.ErrEventNotFound {
+			return rest.NotFoundResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	var buf bytes.Buffer
+	if err := seatmap.Write(&buf, m, format); err != nil {
+		return rest.InternalError(ctx, err)
+	}
+
+	if format == seatmap.FormatCSV {
+		ctx.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	} else {
+		ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	}
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="event-%d-seatmap.%s"`, eventID, format))
+
+	return ctx.Send(buf.Bytes())
+}
+
+func (h *seatMapHandler) ImportSeatMap(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.SeatMapImportRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	// without ?format= the content type decides
+	if req.Format == "" {
+		req.Format = seatmap.FormatJSON
+		if strings.HasPrefix(string(ctx.Request().Header.ContentType()), "text/csv") {
+			req.Format = seatmap.FormatCSV
+		}
+	}
+
+	m, err := seatmap.Read(bytes.NewReader(ctx.Body()), req.Format)
+	if err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	report, err := h.uc.Import(ctx.Context(), eventID, m, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrSeatMapConflicts):
+			return rest.ConflictDataResponse(ctx, err, report)
+		case errors.Is(err, errs.ErrEventNotFound):
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errors.Is(err, errs.ErrEventAlreadyCancelled), errors.Is(err, errs.ErrSeatExists):
+			return rest.ConflictResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	if req.DryRun {
+		return rest.SuccessResponse(ctx, "dry run, nothing was imported", report)
+	}
+
+	return rest.CreatedResponse(ctx, "seat map imported", report)
+}
+
+func (h *seatMapHandler) GetSeatMap(ctx *fiber.Ctx) error {
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	view, etag, err := h.uc.View(ctx.Context(), eventID)
+	if err != nil {
+		return h.viewError(ctx, err)
+	}
+
+	if notModified(ctx, etag) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	return rest.SuccessResponse(ctx, "seat map fetched", view)
+}
+
+func (h *seatMapHandler) GetSeatMapSVG(ctx *fiber.Ctx) error {
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	svg, etag, err := h.uc.SVG(ctx.Context(), eventID)
+	if err != nil {
+		return h.viewError(ctx, err)
+	}
+
+	if notModified(ctx, etag) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	ctx.Set(fiber.HeaderContentType, "image/svg+xml")
+	return ctx.Send(svg)
+}
+
+func (h *seatMapHandler) viewError(ctx *fiber.Ctx, err error) error {
+	if err == errs.ErrEventNotFound {
+		return rest.NotFoundResponse(ctx, err.Error())
+	}
+	return rest.InternalError(ctx, err)
+}
+
+// notModified sets the caching headers and reports whether the client
+// already has this version. Clients may store the map but must revalidate,
+// since seat states change all the time.
+func notModified(ctx *fiber.Ctx, etag string) bool {
+	ctx.Set(fiber.HeaderETag, etag)
+	ctx.Set(fiber.HeaderCacheControl, "private, no-cache")
+
+	return ctx.Get(fiber.HeaderIfNoneMatch) == etag
+}
+
+func (h *seatMapHandler) GetSchema(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, "application/schema+json")
+	return ctx.Send(seatmap.Schema)
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/seatstream"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// wsWriteTimeout drops WebSocket clients that stop reading.
+const wsWriteTimeout = time.Second * 10
+
+type seatStreamHandler struct {
+	uc usecase.SeatStreamUsecase
+}
+
+func NewSeatStreamHandler(uc usecase.SeatStreamUsecase) *seatStreamHandler {
+	return &seatStreamHandler{uc: uc}
+}
+
+// StreamSeats sends the event's seat changes as Server-Sent Events. Clients
+// resume with the Last-Event-ID header, or ?last_event_id= on the first
+// connection.
+func (h *seatStreamHandler) StreamSeats(ctx *fiber.Ctx) error {
+	eventID, err := rest.GetParamsID(ctx, "eventID")
+	if err != nil {
+		return err
+	}
+
+	lastID, err := lastEventID(ctx)
+	if err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.uc.CheckEvent(ctx.Context(), eventID); err != nil {
+		return seatStreamError(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	// runs after the handler returns, so it must not touch ctx
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		streamCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		h.uc.Stream(streamCtx, eventID, lastID, func(m *seatstream.Message) error {
+			if err := writeEvent(w, m); err != nil {
+				return err
+			}
+			return w.Flush()
+		})
+	})
+
+	return nil
+}
+
+// UpgradeSeatStream checks the request before StreamSeatsWS takes over the
+// connection.
+func (h *seatStreamHandler) UpgradeSeatStream(ctx *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(ctx) {
+		return fiber.ErrUpgradeRequired
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "eventID")
+	if err != nil {
+		return err
+	}
+
+	lastID, err := lastEventID(ctx)
+	if err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.uc.CheckEvent(ctx.Context(), eventID); err != nil {
+		return seatStreamError(ctx, err)
+	}
+
+	ctx.Locals("eventID", eventID)
+	ctx.Locals("lastID", lastID)
+	return ctx.Next()
+}
+
+// StreamSeatsWS sends the event's seat changes as JSON messages over a
+// WebSocket. Clients resume with ?last_event_id=.
+func (h *seatStreamHandler) StreamSeatsWS(conn *websocket.Conn) {
+	eventID, _ := conn.Locals("eventID").(int64)
+	lastID, _ := conn.Locals("lastID").(int64)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// nothing is expected from the client; reading notices it leaving
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	h.uc.Stream(ctx, eventID, lastID, func(m *seatstream.Message) error {
+		deadline := time.Now().Add(wsWriteTimeout)
+		if m.Type == seatstream.MessagePing {
+			return conn.WriteControl(websocket.PingMessage, nil, deadline)
+		}
+
+		conn.SetWriteDeadline(deadline)
+		return conn.WriteJSON(m)
+	})
+}
+
+// lastEventID reads where the client wants to resume from, 0 for nowhere.
+func lastEventID(ctx *fiber.Ctx) (int64, error) {
+	last := ctx.Get("Last-Event-ID", ctx.Query("last_event_id"))
+	if last == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || id < 0 {
+		return 0, errors.New("invalid last event id")
+	}
+	return id, nil
+}
+
+func seatStreamError(ctx *fiber.Ctx, err error) error {
+	if err == errs.ErrEventNotFound {
+		return rest.NotFoundResponse(ctx, err.Error())
+	}
+	return rest.InternalError(ctx, err)
+}
+
+func writeEvent(w *bufio.Writer, m *seatstream.Message) error {
+	if m.Type == seatstream.MessagePing {
+		_, err := w.WriteString(": ping\n\n")
+		return err
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", m.ID, m.Type, data)
+	return err
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const ticketTypeID = "ticketTypeID"
+
+type ticketTypeHandler struct {
+	uc        usecase.TicketTypeUsecase
+	validator *validator.Validate
+}
+
+func NewTicketTypeHandler(uc usecase.TicketTypeUsecase) *ticketTypeHandler {
+	return &ticketTypeHandler{
+		uc:        uc,
+		validator: validator.New(),
+	}
+}
+
+func (h *ticketTypeHandler) CreateTicketType(ctx *fiber.Ctx) error {
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.TicketTypeRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	tt, err := h.uc.CreateTicketType(ctx.Context(), eventID, &req)
+	if err != nil {
+		if errors.Is(err, errs.ErrEventNotFound) {
+			return rest.NotFoundResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.CreatedResponse(ctx, "ticket type created", tt)
+}
+
+func (h *ticketTypeHandler) ListTicketTypes(ctx *fiber.Ctx) error {
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	types, err := h.uc.ListTicketTypes(ctx.Context(), eventID)
+	if err != nil {
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "ticket types by event", types)
+}
+
+func (h *ticketTypeHandler) GetTicketType(ctx *fiber.Ctx) error {
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	id, err := rest.GetParamsID(ctx, ticketTypeID)
+	if err != nil {
+		return err
+	}
+
+	tt, err := h.uc.GetTicketType(ctx.Context(), eventID, id)
+	if err != nil {
+		if errors.Is(err, errs.ErrTicketTypeNotFound) {
+			return rest.NotFoundResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "", tt)
+}
+
+func (h *ticketTypeHandler) UpdateTicketType(ctx *fiber.Ctx) error {
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	id, err := rest.GetParamsID(ctx, ticketTypeID)
+	if err != nil {
+		return err
+	}
+
+	var req dto.TicketTypeUpdateRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	tt, err := h.uc.UpdateTicketType(ctx.Context(), eventID, id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrTicketTypeNotFound):
+			return rest.NotFoundResponse(ctx, err.Error())
+		case errors.Is(err, errs.ErrNoFieldsToUpdate):
+			return rest.BadRequestResponse(ctx, err.Error())
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.SuccessResponse(ctx, "ticket type updated", tt)
+}
+
+func (h *ticketTypeHandler) DeleteTicketType(ctx *fiber.Ctx) error {
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	id, err := rest.GetParamsID(ctx, ticketTypeID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.uc.DeleteTicketType(ctx.Context(), eventID, id); err != nil {
+		if errors.Is(err, errs.ErrTicketTypeNotFound) {
+			return rest.NotFoundResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "ticket type deleted", nil)
+}
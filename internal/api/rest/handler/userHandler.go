@@ -7,22 +7,25 @@ import (
 	"github.com/codepnw/go-ticket-booking/internal/dto"
 	"github.com/codepnw/go-ticket-booking/internal/errs"
 	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/helper/security"
 	"github.com/codepnw/go-ticket-booking/internal/usecase"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 type userHandler struct {
-	userUc    usecase.UserUsecase
-	authUc    usecase.AuthUsecase
-	validator *validator.Validate
+	userUc     usecase.UserUsecase
+	authUc     usecase.AuthUsecase
+	passwordUc usecase.PasswordUsecase
+	validator  *validator.Validate
 }
 
-func NewUserHandler(uc usecase.UserUsecase, authUc usecase.AuthUsecase) *userHandler {
+func NewUserHandler(uc usecase.UserUsecase, authUc usecase.AuthUsecase, passwordUc usecase.PasswordUsecase) *userHandler {
 	return &userHandler{
-		userUc:    uc,
-		authUc:    authUc,
-		validator: validator.New(),
+		userUc:     uc,
+		authUc:     authUc,
+		passwordUc: passwordUc,
+		validator:  validator.New(),
 	}
 }
 
@@ -38,6 +41,9 @@ func (h *userHandler) Register(ctx *fiber.Ctx) error {
 
 	user, err := h.userUc.CreateUser(ctx.Context(), &req)
 	if err != nil {
+		if errors.Is(err, security.ErrWeakPassword) {
+			return rest.BadRequestResponse(ctx, err.Error())
+		}
 		return rest.InternalError(ctx, err)
 	}
 
@@ -53,6 +59,8 @@ func (h *userHandler) Login(ctx *fiber.Ctx) error {
 	if err := h.validator.Struct(req); err != nil {
 		return rest.BadRequestResponse(ctx, errs.ErrInvalidInputData.Error())
 	}
+	req.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	req.IPAddress = ctx.IP()
 
 	accressToken, refreshToken, err := h.userUc.Login(ctx.Context(), &req)
 	if err != nil {
@@ -112,10 +120,7 @@ func (h *userHandler) UpdateProfile(ctx *fiber.Ctx) error {
 
 // ------ Auth --------
 func (h *userHandler) RefreshToken(ctx *fiber.Ctx) error {
-	var req struct {
-		RefreshToken string `json:"refresh_token" validate:"required"`
-	}
-
+	var req dto.RefreshTokenRequest
 	if err := ctx.BodyParser(&req); err != nil {
 		return rest.BadRequestResponse(ctx, err.Error())
 	}
@@ -123,13 +128,24 @@ func (h *userHandler) RefreshToken(ctx *fiber.Ctx) error {
 	if err := h.validator.Struct(req); err != nil {
 		return rest.BadRequestResponse(ctx, err.Error())
 	}
+	req.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	req.IPAddress = ctx.IP()
 
-	refreshToken, err := h.authUc.RefreshToken(ctx.Context(), req.RefreshToken)
+	accessToken, refreshToken, err := h.authUc.RefreshToken(ctx.Context(), &req)
 	if err != nil {
-		return rest.InternalError(ctx, err)
+		switch {
+		case errors.Is(err, errs.ErrInvalidRefreshToken), errors.Is(err, errs.ErrRefreshTokenReused):
+			return rest.UnauthorizedErrorResponse(ctx, err)
+		default:
+			return rest.InternalError(ctx, err)
+		}
 	}
 
-	return rest.SuccessResponse(ctx, "token refreshed", refreshToken)
+	// the old refresh token is used up
+	return rest.SuccessResponse(ctx, "token refreshed", &fiber.Map{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+	})
 }
 
 func (h *userHandler) Logout(ctx *fiber.Ctx) error {
@@ -138,13 +154,191 @@ func (h *userHandler) Logout(ctx *fiber.Ctx) error {
 		return rest.UnauthorizedResponse(ctx)
 	}
 
-	if err := h.authUc.Logout(ctx.Context(), user.ID); err != nil {
+	sessionID, ok := auth.GetSessionID(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	if err := h.authUc.Logout(ctx.Context(), user.ID, sessionID); err != nil && !errors.Is(err, errs.ErrSessionNotFound) {
 		return rest.InternalError(ctx, err)
 	}
 
 	return rest.SuccessResponse(ctx, "logout success", nil)
 }
 
+func (h *userHandler) ListSessions(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	sessionID, _ := auth.GetSessionID(ctx)
+
+	sessions, err := h.authUc.ListSessions(ctx.Context(), user.ID, sessionID)
+	if err != nil {
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "active sessions", sessions)
+}
+
+// RevokeSession signs out one device. Revoking the current session works
+// like logout.
+func (h *userHandler) RevokeSession(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, "sessionID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.authUc.RevokeSession(ctx.Context(), user.ID, id); err != nil {
+		if errors.Is(err, errs.ErrSessionNotFound) {
+			return rest.NotFoundResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "session revoked", nil)
+}
+
+// RevokeOtherSessions signs out every device but the one making the request.
+func (h *userHandler) RevokeOtherSessions(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	sessionID, ok := auth.GetSessionID(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	if err := h.authUc.RevokeOtherSessions(ctx.Context(), user.ID, sessionID); err != nil {
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "other sessions revoked", nil)
+}
+
+func (h *userHandler) ForgotPassword(ctx *fiber.Ctx) error {
+	var req dto.ForgotPasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.passwordUc.ForgotPassword(ctx.Context(), req.Email); err != nil {
+		return rest.InternalError(ctx, err)
+	}
+
+	// same answer whether or not the email is registered
+	return rest.SuccessResponse(ctx, "if the email is registered, a reset link has been sent", nil)
+}
+
+func (h *userHandler) ResetPassword(ctx *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.passwordUc.ResetPassword(ctx.Context(), &req); err != nil {
+		if errors.Is(err, errs.ErrInvalidResetToken) || errors.Is(err, security.ErrWeakPassword) {
+			return rest.BadRequestResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "password reset, please log in again", nil)
+}
+
+func (h *userHandler) ChangePassword(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+	req.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	req.IPAddress = ctx.IP()
+
+	accessToken, refreshToken, err := h.passwordUc.ChangePassword(ctx.Context(), user.ID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrWrongPassword):
+			return rest.ForbiddenErrorResponse(ctx, err)
+		case errors.Is(err, errs.ErrSamePassword), errors.Is(err, security.ErrWeakPassword):
+			return rest.BadRequestResponse(ctx, err.Error())
+		case errors.Is(err, errs.ErrUserNotFound):
+			return rest.NotFoundResponse(ctx, err.Error())
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.SuccessResponse(ctx, "password changed, other sessions were logged out", &fiber.Map{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+	})
+}
+
+func (h *userHandler) VerifyEmail(ctx *fiber.Ctx) error {
+	var req dto.VerifyEmailRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.userUc.VerifyEmail(ctx.Context(), req.Token); err != nil {
+		if errors.Is(err, errs.ErrInvalidVerificationLink) {
+			return rest.BadRequestResponse(ctx, err.Error())
+		}
+		return rest.InternalError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "email verified", nil)
+}
+
+func (h *userHandler) ResendVerification(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	if err := h.userUc.ResendVerification(ctx.Context(), user.ID); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrEmailAlreadyVerified):
+			return rest.ConflictResponse(ctx, err)
+		case errors.Is(err, errs.ErrUserNotFound):
+			return rest.NotFoundResponse(ctx, err.Error())
+		default:
+			return rest.InternalError(ctx, err)
+		}
+	}
+
+	return rest.SuccessResponse(ctx, "verification email sent", nil)
+}
+
 // ------ Admin -------
 func (h *userHandler) AdminGetUsers(ctx *fiber.Ctx) error {
 	// check role
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const templateID = "templateID"
+
+type venueTemplateHandler struct {
+	uc        usecase.VenueTemplateUsecase
+	validator *validator.Validate
+}
+
+func NewVenueTemplateHandler(uc usecase.VenueTemplateUsecase) *venueTemplateHandler {
+	return &venueTemplateHandler{
+		uc:        uc,
+		validator: validator.New(),
+	}
+}
+
+func (h *venueTemplateHandler) CreateTemplate(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	locationID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.VenueTemplateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	t, err := h.uc.Create(ctx.Context(), locationID, &req)
+	if err != nil {
+		return h.templateError(ctx, err)
+	}
+
+	return rest.CreatedResponse(ctx, "venue template created", t)
+}
+
+func (h *venueTemplateHandler) ListTemplates(ctx *fiber.Ctx) error {
+	locationID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	templates, err := h.uc.ListByLocation(ctx.Context(), locationID)
+	if err != nil {
+		return h.templateError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "venue templates fetched", templates)
+}
+
+func (h *venueTemplateHandler) GetTemplate(ctx *fiber.Ctx) error {
+	id, err := rest.GetParamsID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+
+	t, err := h.uc.GetByID(ctx.Context(), id)
+	if err != nil {
+		return h.templateError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "venue template fetched", t)
+}
+
+func (h *venueTemplateHandler) UpdateTemplate(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+
+	var req dto.VenueTemplateUpdateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	t, err := h.uc.Update(ctx.Context(), id, &req)
+	if err != nil {
+		return h.templateError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "venue template updated", t)
+}
+
+func (h *venueTemplateHandler) DeleteTemplate(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	id, err := rest.GetParamsID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.uc.Delete(ctx.Context(), id); err != nil {
+		return h.templateError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "venue template deleted", nil)
+}
+
+func (h *venueTemplateHandler) ApplyTemplate(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.ApplyTemplateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	res, err := h.uc.ApplyToEvent(ctx.Context(), eventID, req.TemplateID)
+	if err != nil {
+		return h.templateError(ctx, err)
+	}
+
+	return rest.CreatedResponse(ctx, "venue template applied", res)
+}
+
+func (h *venueTemplateHandler) templateError(ctx *fiber.Ctx, err error) error {
+	if errors.Is(err, errs.ErrInvalidVenueLayout) {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	switch err {
+	case errs.ErrTemplateNotFound, errs.ErrLocationNotFound, errs.ErrEventNotFound:
+		return rest.NotFoundResponse(ctx, err.Error())
+	case errs.ErrNoFieldsToUpdate, errs.ErrLocationCapacityExceeded, errs.ErrTemplateWrongLocation:
+		return rest.BadRequestResponse(ctx, err.Error())
+	case errs.ErrTemplateNameTaken, errs.ErrEventHasSeatMap, errs.ErrEventAlreadyCancelled:
+		return rest.ConflictResponse(ctx, err)
+	default:
+		return rest.InternalError(ctx, err)
+	}
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type waitingRoomHandler struct {
+	uc        usecase.WaitingRoomUsecase
+	validator *validator.Validate
+}
+
+func NewWaitingRoomHandler(uc usecase.WaitingRoomUsecase) *waitingRoomHandler {
+	return &waitingRoomHandler{
+		uc:        uc,
+		validator: validator.New(),
+	}
+}
+
+func (h *waitingRoomHandler) ConfigureQueue(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.QueueSettingsRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	q, err := h.uc.Configure(ctx.Context(), eventID, &req)
+	if err != nil {
+		return h.queueError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "waiting room updated", q)
+}
+
+func (h *waitingRoomHandler) GetQueueSettings(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok || user.Role != string(dto.RoleAdmin) {
+		return rest.ForbiddenResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	q, err := h.uc.GetSettings(ctx.Context(), eventID)
+	if err != nil {
+		return h.queueError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "waiting room fetched", q)
+}
+
+func (h *waitingRoomHandler) JoinQueue(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	status, err := h.uc.Join(ctx.Context(), eventID, user.ID)
+	if err != nil {
+		return h.queueError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "joined waiting room", status)
+}
+
+// GetQueueStatus is polled by users in line. Once they are admitted it
+// carries their admission token.
+func (h *waitingRoomHandler) GetQueueStatus(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	status, err := h.uc.Status(ctx.Context(), eventID, user.ID)
+	if err != nil {
+		return h.queueError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "waiting room status fetched", status)
+}
+
+func (h *waitingRoomHandler) queueError(ctx *fiber.Ctx, err error) error {
+	switch err {
+	case errs.ErrEventNotFound, errs.ErrQueueNotFound, errs.ErrNotInQueue:
+		return rest.NotFoundResponse(ctx, err.Error())
+	case errs.ErrQueueClosed:
+		return rest.ConflictResponse(ctx, err)
+	default:
+		return rest.InternalError(ctx, err)
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type waitlistHandler struct {
+	uc        usecase.WaitlistUsecase
+	validator *validator.Validate
+}
+
+func NewWaitlistHandler(uc usecase.WaitlistUsecase) *waitlistHandler {
+	return &waitlistHandler{
+		uc:        uc,
+		validator: validator.New(),
+	}
+}
+
+func (h *waitlistHandler) JoinWaitlist(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.JoinWaitlistRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return rest.BadRequestResponse(ctx, err.Error())
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return rest.BadRequestResponse(ctx, err.Error())
+	}
+
+	entry, err := h.uc.Join(ctx.Context(), eventID, user.ID, &req)
+	if err != nil {
+		return h.waitlistError(ctx, err)
+	}
+
+	return rest.CreatedResponse(ctx, "joined waitlist", entry)
+}
+
+func (h *waitlistHandler) GetWaitlistEntry(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	entry, err := h.uc.Get(ctx.Context(), eventID, user.ID)
+	if err != nil {
+		return h.waitlistError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "waitlist entry fetched", entry)
+}
+
+func (h *waitlistHandler) LeaveWaitlist(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.uc.Leave(ctx.Context(), eventID, user.ID); err != nil {
+		return h.waitlistError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "left waitlist", nil)
+}
+
+func (h *waitlistHandler) AcceptOffer(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	booking, err := h.uc.AcceptOffer(ctx.Context(), eventID, user.ID)
+	if err != nil {
+		return h.waitlistError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "offer accepted, complete payment to confirm", booking)
+}
+
+func (h *waitlistHandler) DeclineOffer(ctx *fiber.Ctx) error {
+	user, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		return rest.UnauthorizedResponse(ctx)
+	}
+
+	eventID, err := rest.GetParamsID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.uc.DeclineOffer(ctx.Context(), eventID, user.ID); err != nil {
+		return h.waitlistError(ctx, err)
+	}
+
+	return rest.SuccessResponse(ctx, "offer declined", nil)
+}
+
+func (h *waitlistHandler) waitlistError(ctx *fiber.Ctx, err error) error {
+	switch err {
+	case errs.ErrEventNotFound, errs.ErrSectionNotFound, errs.ErrWaitlistEntryNotFound:
+		return rest.NotFoundResponse(ctx, err.Error())
+	case errs.ErrAlreadyOnWaitlist,
+		errs.ErrWaitlistClosed,
+		errs.ErrNoWaitlistOffer,
+		errs.ErrBookingHoldExpired:
+		return rest.ConflictResponse(ctx, err)
+	default:
+		return rest.InternalError(ctx, err)
+	}
+}
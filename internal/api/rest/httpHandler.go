@@ -5,6 +5,11 @@ import (
 	"errors"
 
 	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/helper/security"
+	"github.com/codepnw/go-ticket-booking/internal/mailer"
+	"github.com/codepnw/go-ticket-booking/internal/payment"
+	"github.com/codepnw/go-ticket-booking/internal/seatstream"
+	"github.com/codepnw/go-ticket-booking/internal/waitingroom"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -12,6 +17,23 @@ type ConfigRestHandler struct {
 	App  *fiber.App
 	Auth auth.Auth
 	DB   *sql.DB
+
+	Payment         payment.Provider
+	PaymentCurrency string
+	// PaymentSimulation mounts the fake provider's simulate endpoint. It is
+	// only set in development and test mode.
+	PaymentSimulation bool
+
+	SeatHub *seatstream.Hub
+
+	// Admission signs waiting room admission tokens.
+	Admission *waitingroom.Signer
+
+	Mailer mailer.Mailer
+	// PublicURL is where links in emails point to.
+	PublicURL string
+	// EmailVerification signs email verification links.
+	EmailVerification *security.VerificationSigner
 }
 
 func NewRestHandler(e *ConfigRestHandler) (*ConfigRestHandler, error) {
@@ -27,5 +49,25 @@ func NewRestHandler(e *ConfigRestHandler) (*ConfigRestHandler, error) {
 		return nil, errors.New("DB is required")
 	}
 
+	if e.Payment == nil {
+		return nil, errors.New("PAYMENT is required")
+	}
+
+	if e.SeatHub == nil {
+		return nil, errors.New("SEAT HUB is required")
+	}
+
+	if e.Admission == nil {
+		return nil, errors.New("ADMISSION SIGNER is required")
+	}
+
+	if e.Mailer == nil {
+		return nil, errors.New("MAILER is required")
+	}
+
+	if e.EmailVerification == nil {
+		return nil, errors.New("EMAIL VERIFICATION SIGNER is required")
+	}
+
 	return e, nil
 }
@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	IdempotencyKeyHeader    = "Idempotency-Key"
+	idempotencyReplayHeader = "Idempotent-Replayed"
+	idempotencyKeyTTL       = time.Hour * 24
+	idempotencyQueryTimeout = time.Second * 5
+	idempotencyKeyMaxLength = 255
+)
+
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used with a different request")
+
+// IdempotencyStore persists idempotency keys and the responses they produced.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, k *domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, k *domain.IdempotencyKey) error
+	Release(ctx context.Context, k *domain.IdempotencyKey) error
+}
+
+// Idempotency replays the stored response for mutating requests that repeat
+// an Idempotency-Key header. Keys are scoped to the caller, method and path;
+// reusing a key with a different body returns 422. Requests without the
+// header, and safe methods, pass through untouched.
+func Idempotency(store IdempotencyStore) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(ctx.Method()) {
+			return ctx.Next()
+		}
+
+		if len(key) > idempotencyKeyMaxLength {
+			return BadRequestResponse(ctx, "idempotency key is too long")
+		}
+
+		c, cancel := context.WithTimeout(context.Background(), idempotencyQueryTimeout)
+		defer cancel()
+
+		record := &domain.IdempotencyKey{
+			Scope:       idempotencyScope(ctx),
+			Key:         key,
+			Method:      ctx.Method(),
+			Path:        ctx.Path(),
+			RequestHash: requestFingerprint(ctx),
+			ExpiresAt:   time.Now().Add(idempotencyKeyTTL),
+		}
+
+		stored, created, err := store.Reserve(c, record)
+		if err != nil {
+			return InternalError(ctx, err)
+		}
+
+		if !created {
+			return replayIdempotent(ctx, stored, record.RequestHash)
+		}
+
+		if err = ctx.Next(); err != nil {
+			releaseIdempotencyKey(store, record)
+			return err
+		}
+
+		// server errors are not cached so the client can retry
+		status := ctx.Response().StatusCode()
+		if status >= http.StatusInternalServerError {
+			releaseIdempotencyKey(store, record)
+			return nil
+		}
+
+		record.StatusCode = &status
+		record.ResponseBody = append([]byte(nil), ctx.Response().Body()...)
+
+		c, cancel = context.WithTimeout(context.Background(), idempotencyQueryTimeout)
+		defer cancel()
+
+		if err = store.Complete(c, record); err != nil {
+			log.Printf("save idempotency key %q failed: %v", key, err)
+		}
+
+		return nil
+	}
+}
+
+func replayIdempotent(ctx *fiber.Ctx, stored *domain.IdempotencyKey, requestHash string) error {
+	if stored.RequestHash != requestHash {
+		return UnprocessableEntityResponse(ctx, ErrIdempotencyKeyReused.Error())
+	}
+
+	if stored.CompletedAt == nil || stored.StatusCode == nil {
+		return ctx.Status(http.StatusConflict).JSON(&fiber.Map{
+			"message": "a request with this idempotency key is still in progress",
+		})
+	}
+
+	ctx.Set(idempotencyReplayHeader, "true")
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return ctx.Status(*stored.StatusCode).Send(stored.ResponseBody)
+}
+
+func releaseIdempotencyKey(store IdempotencyStore, record *domain.IdempotencyKey) {
+	c, cancel := context.WithTimeout(context.Background(), idempotencyQueryTimeout)
+	defer cancel()
+
+	if err := store.Release(c, record); err != nil {
+		log.Printf("release idempotency key %q failed: %v", record.Key, err)
+	}
+}
+
+// idempotencyScope identifies the caller: the signed-in user when auth ran
+// before the middleware, else the credentials sent, else the client address.
+func idempotencyScope(ctx *fiber.Ctx) string {
+	if user, ok := auth.GetCurrentUser(ctx); ok {
+		return fmt.Sprintf("user:%d", user.ID)
+	}
+	if header := ctx.Get(fiber.HeaderAuthorization); header != "" {
+		sum := sha256.Sum256([]byte(header))
+		return "token:" + hex.EncodeToString(sum[:])
+	}
+	return "client:" + ctx.IP()
+}
+
+func requestFingerprint(ctx *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(ctx.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(ctx.Path()))
+	h.Write([]byte{0})
+	h.Write(ctx.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -48,14 +48,51 @@ func UnauthorizedResponse(ctx *fiber.Ctx) error {
 	})
 }
 
+// UnauthorizedErrorResponse is a 401 that says why.
+func UnauthorizedErrorResponse(ctx *fiber.Ctx, err error) error {
+	return ctx.Status(http.StatusUnauthorized).JSON(&fiber.Map{"message": err.Error()})
+}
+
 func ForbiddenResponse(ctx *fiber.Ctx) error {
 	return ctx.Status(http.StatusForbidden).JSON(&fiber.Map{"message": "admin only"})
 }
 
+// ForbiddenErrorResponse is a 403 that says why.
+func ForbiddenErrorResponse(ctx *fiber.Ctx, err error) error {
+	return ctx.Status(http.StatusForbidden).JSON(&fiber.Map{"message": err.Error()})
+}
+
+// ForbiddenCodeResponse is a 403 with a machine-readable code next to the
+// message.
+func ForbiddenCodeResponse(ctx *fiber.Ctx, err error, code string) error {
+	return ctx.Status(http.StatusForbidden).JSON(&fiber.Map{
+		"message": err.Error(),
+		"code":    code,
+	})
+}
+
 func ConflictResponse(ctx *fiber.Ctx, err error) error {
 	return ctx.Status(http.StatusConflict).JSON(&fiber.Map{"message": err.Error()})
 }
 
+// ConflictDataResponse is a 409 that also carries details, e.g. a report of
+// what conflicted.
+func ConflictDataResponse(ctx *fiber.Ctx, err error, data any) error {
+	return ctx.Status(http.StatusConflict).JSON(&fiber.Map{
+		"message": err.Error(),
+		"data":    data,
+	})
+}
+
+// ConflictCodeResponse is a 409 with a machine-readable code next to the
+// message.
+func ConflictCodeResponse(ctx *fiber.Ctx, err error, code string) error {
+	return ctx.Status(http.StatusConflict).JSON(&fiber.Map{
+		"message": err.Error(),
+		"code":    code,
+	})
+}
+
 func InternalError(ctx *fiber.Ctx, err error) error {
 	return ctx.Status(http.StatusInternalServerError).JSON(&fiber.Map{"error": err.Error()})
 }
@@ -67,3 +104,7 @@ func GetParamsID(ctx *fiber.Ctx, key string) (int64, error) {
 	}
 	return int64(id), nil
 }
+
+func UnprocessableEntityResponse(ctx *fiber.Ctx, msg string) error {
+	return ctx.Status(http.StatusUnprocessableEntity).JSON(&fiber.Map{"message": msg})
+}
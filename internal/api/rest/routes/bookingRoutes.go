@@ -16,10 +16,17 @@ func SetupBookingRoutes(config *rest.ConfigRestHandler) {
 	sectRepo := repository.NewSectionRepository(db)
 	seatRepo := repository.NewSeatRepository(db)
 	bookRepo := repository.NewBookingRepository(db)
-	uc := usecase.NewBookingUsecase(tx, bookRepo, seatRepo, sectRepo)
-	handler := handler.NewBookingHandler(db, uc)
+	ttRepo := repository.NewTicketTypeRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	promoRepo := repository.NewPromotionRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	gate := usecase.NewWaitingRoomUsecase(tx, repository.NewQueueRepository(db), eventRepo, config.Admission)
+	uc := usecase.NewBookingUsecase(tx, bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, promoRepo, userRepo, gate)
+	handler := handler.NewBookingHandler(uc)
 
-	bookRoutes := app.Group("/bookings")
+	idempotency := rest.Idempotency(repository.NewIdempotencyRepository(db))
+
+	bookRoutes := app.Group("/bookings", idempotency)
 
 	bookRoutes.Post("/", handler.CreateBooking)
 	bookRoutes.Get("/:bookingID", handler.GetBookingByID)
@@ -27,7 +34,6 @@ func SetupBookingRoutes(config *rest.ConfigRestHandler) {
 	bookRoutes.Get("/user/:userID", handler.GetBookingsByUser)
 	bookRoutes.Get("/", handler.GetBookingsByStatus)
 	bookRoutes.Get("/seat/:seatID", handler.AvailableBooking)
-	bookRoutes.Put("/:bookingID/confirm", handler.ConfirmBooking)
 	bookRoutes.Put("/:bookingID/cancel", handler.CancelBooking)
 	bookRoutes.Patch("/:bookingID", handler.UpdateSeat)
 }
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupEventCancellationRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+	tx := database.NewSqlTxManager(db)
+
+	bookRepo := repository.NewBookingRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	payRepo := repository.NewPaymentRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	cancelRepo := repository.NewEventCancellationRepository(db)
+	notifyRepo := repository.NewNotificationRepository(db)
+
+	refundUc := usecase.NewRefundUsecase(tx, bookRepo, eventRepo, payRepo, refundRepo, config.Payment)
+	uc := usecase.NewEventCancellationUsecase(tx, eventRepo, bookRepo, cancelRepo, notifyRepo, refundUc)
+	handler := handler.NewEventCancellationHandler(uc)
+
+	idempotency := rest.Idempotency(repository.NewIdempotencyRepository(db))
+
+	// auth comes from the /events group
+	cancelRoutes := app.Group("/events/:id")
+	cancelRoutes.Post("/cancel", idempotency, handler.CancelEvent)
+	cancelRoutes.Get("/cancellation", handler.GetCancellation)
+	cancelRoutes.Post("/cancellation/retry", handler.RetryCancellation)
+}
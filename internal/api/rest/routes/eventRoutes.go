@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/codepnw/go-ticket-booking/internal/api/rest"
 	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/repository"
 	"github.com/codepnw/go-ticket-booking/internal/usecase"
 )
@@ -10,8 +11,9 @@ import (
 func SetupEventRoutes(rh *rest.ConfigRestHandler) {
 	app := rh.App
 
+	tx := database.NewSqlTxManager(rh.DB)
 	repo := repository.NewEventRepository(rh.DB)
-	uc := usecase.NewEventUsecase(repo)
+	uc := usecase.NewEventUsecase(tx, repo)
 	handler := handler.NewEventHandler(uc)
 
 	pvtRoutes := app.Group("/events", rh.Auth.Authorize)
@@ -20,6 +22,8 @@ func SetupEventRoutes(rh *rest.ConfigRestHandler) {
 	pvtRoutes.Get("/:id", handler.GetEventByID)
 	pvtRoutes.Patch("/:id", handler.UpdateEvent)
 	pvtRoutes.Delete("/:id", handler.DeleteEvent)
+	pvtRoutes.Put("/:id/status", handler.ChangeEventStatus)
+	pvtRoutes.Get("/:id/status-history", handler.ListEventTransitions)
 
 	// Locations
 	locRoutes := app.Group("/locations")
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+)
+
+func SetupJWKSRoutes(config *rest.ConfigRestHandler) {
+	handler := handler.NewJWKSHandler(config.Auth)
+
+	// public, so other services can verify our access tokens
+	config.App.Get("/.well-known/jwks.json", handler.GetJWKS)
+}
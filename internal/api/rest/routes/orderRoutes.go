@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupOrderRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+	tx := database.NewSqlTxManager(db)
+
+	orderRepo := repository.NewOrderRepository(db)
+	bookRepo := repository.NewBookingRepository(db)
+	seatRepo := repository.NewSeatRepository(db)
+	sectRepo := repository.NewSectionRepository(db)
+	ttRepo := repository.NewTicketTypeRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	promoRepo := repository.NewPromotionRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	gate := usecase.NewWaitingRoomUsecase(tx, repository.NewQueueRepository(db), eventRepo, config.Admission)
+	uc := usecase.NewOrderUsecase(tx, orderRepo, bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, promoRepo, userRepo, gate)
+	handler := handler.NewOrderHandler(uc)
+
+	idempotency := rest.Idempotency(repository.NewIdempotencyRepository(db))
+
+	orderRoutes := app.Group("/orders", idempotency)
+
+	orderRoutes.Post("/", handler.CreateOrder)
+	orderRoutes.Post("/best-available", handler.CreateBestAvailableOrder)
+	orderRoutes.Get("/:orderID", handler.GetOrderByID)
+	orderRoutes.Put("/:orderID/cancel", handler.CancelOrder)
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/payment"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupPaymentRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+	tx := database.NewSqlTxManager(db)
+
+	payRepo := repository.NewPaymentRepository(db)
+	orderRepo := repository.NewOrderRepository(db)
+	bookRepo := repository.NewBookingRepository(db)
+	seatRepo := repository.NewSeatRepository(db)
+	sectRepo := repository.NewSectionRepository(db)
+	ttRepo := repository.NewTicketTypeRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	promoRepo := repository.NewPromotionRepository(db)
+	userRepo := repository.NewUserRepository(db)
+
+	gate := usecase.NewWaitingRoomUsecase(tx, repository.NewQueueRepository(db), eventRepo, config.Admission)
+	bookingUc := usecase.NewBookingUsecase(tx, bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, promoRepo, userRepo, gate)
+	orderUc := usecase.NewOrderUsecase(tx, orderRepo, bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, promoRepo, userRepo, gate)
+	uc := usecase.NewPaymentUsecase(tx, payRepo, bookRepo, orderRepo, bookingUc, orderUc, config.Payment, config.PaymentCurrency)
+	handler := handler.NewPaymentHandler(uc)
+
+	idempotency := rest.Idempotency(repository.NewIdempotencyRepository(db))
+
+	payRoutes := app.Group("/payments")
+
+	// provider callbacks are authenticated by signature, not by idempotency keys
+	payRoutes.Post("/webhook", handler.Webhook)
+	if fake, ok := config.Payment.(*payment.FakeProvider); ok && config.PaymentSimulation {
+		payRoutes.Post("/fake/simulate", config.Auth.Authorize, handler.SimulateWebhook(fake))
+	}
+
+	payRoutes.Post("/bookings/:bookingID", idempotency, handler.PayBooking)
+	payRoutes.Post("/orders/:orderID", idempotency, handler.PayOrder)
+	payRoutes.Get("/:paymentID", handler.GetPaymentByID)
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupPromotionRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+
+	repo := repository.NewPromotionRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	ttRepo := repository.NewTicketTypeRepository(db)
+	uc := usecase.NewPromotionUsecase(repo, eventRepo, ttRepo)
+	handler := handler.NewPromotionHandler(uc)
+
+	promoRoutes := app.Group("/admin/promotions", config.Auth.Authorize)
+	promoRoutes.Post("/", handler.CreatePromotion)
+	promoRoutes.Get("/", handler.ListPromotions)
+	promoRoutes.Get("/:promotionID", handler.GetPromotion)
+	promoRoutes.Patch("/:promotionID", handler.UpdatePromotion)
+	promoRoutes.Delete("/:promotionID", handler.DeletePromotion)
+	promoRoutes.Get("/:promotionID/redemptions", handler.GetRedemptionReport)
+}
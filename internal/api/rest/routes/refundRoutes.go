@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupRefundRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+	tx := database.NewSqlTxManager(db)
+
+	bookRepo := repository.NewBookingRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	payRepo := repository.NewPaymentRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	uc := usecase.NewRefundUsecase(tx, bookRepo, eventRepo, payRepo, refundRepo, config.Payment)
+	handler := handler.NewRefundHandler(uc)
+
+	idempotency := rest.Idempotency(repository.NewIdempotencyRepository(db))
+
+	refundRoutes := app.Group("/bookings/:bookingID/refunds", config.Auth.Authorize)
+	// the /bookings group already applies the idempotency middleware
+	refundRoutes.Post("/", handler.RefundBooking)
+	refundRoutes.Get("/", handler.ListRefunds)
+
+	// Admin
+	admin := app.Group("/admin/bookings", config.Auth.Authorize)
+	admin.Post("/:bookingID/refunds", idempotency, handler.AdminForceRefund)
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupSeatMapRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+	tx := database.NewSqlTxManager(db)
+
+	eventRepo := repository.NewEventRepository(db)
+	sectRepo := repository.NewSectionRepository(db)
+	seatRepo := repository.NewSeatRepository(db)
+	ttRepo := repository.NewTicketTypeRepository(db)
+	uc := usecase.NewSeatMapUsecase(tx, eventRepo, sectRepo, seatRepo, ttRepo)
+	handler := handler.NewSeatMapHandler(uc)
+
+	app.Get("/seatmap/schema.json", handler.GetSchema)
+
+	// auth comes from the /events group
+	app.Get("/events/:id/seatmap.svg", handler.GetSeatMapSVG)
+
+	mapRoutes := app.Group("/events/:id/seatmap")
+	mapRoutes.Get("/", handler.GetSeatMap)
+	mapRoutes.Get("/export", handler.ExportSeatMap)
+	mapRoutes.Post("/import", handler.ImportSeatMap)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/codepnw/go-ticket-booking/internal/api/rest"
 	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/repository"
 	"github.com/codepnw/go-ticket-booking/internal/usecase"
 )
@@ -16,13 +17,19 @@ func SetupSeatRoutes(config *rest.ConfigRestHandler) {
 
 	app := config.App
 
+	tx := database.NewSqlTxManager(config.DB)
+
 	repo := repository.NewSeatRepository(config.DB)
-	uc := usecase.NewSeatRepository(repo)
-	handler := handler.NewSeatHandler(config.DB, uc)
+	sectRepo := repository.NewSectionRepository(config.DB)
+	ttRepo := repository.NewTicketTypeRepository(config.DB)
+	eventRepo := repository.NewEventRepository(config.DB)
+	uc := usecase.NewSeatRepository(tx, repo, sectRepo, ttRepo, eventRepo)
+	handler := handler.NewSeatHandler(uc)
 
 	seatRoutes := app.Group("/seats")
 
 	seatRoutes.Post("/", handler.CreateSeats)
+	seatRoutes.Post("/generate", handler.GenerateSeats)
 	seatRoutes.Get(sectionID, handler.GetSeatsBySectionID)
 	seatRoutes.Get(eventID, handler.GetAvailableSeatsByEvent)
 	seatRoutes.Patch(seatID, handler.UpdateSeat)
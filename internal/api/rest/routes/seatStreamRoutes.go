@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/gofiber/contrib/websocket"
+)
+
+func SetupSeatStreamRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+
+	uc := usecase.NewSeatStreamUsecase(
+		config.SeatHub,
+		repository.NewSeatChangeRepository(db),
+		repository.NewEventRepository(db),
+	)
+	handler := handler.NewSeatStreamHandler(uc)
+
+	// public, like the seat list they keep up to date
+	streamRoutes := app.Group("/seats/event/:eventID")
+	streamRoutes.Get("/stream", handler.StreamSeats)
+	streamRoutes.Get("/ws", handler.UpgradeSeatStream, websocket.New(handler.StreamSeatsWS))
+}
@@ -11,7 +11,8 @@ func SetupSectionRoutes(rh *rest.ConfigRestHandler) {
 	app := rh.App
 
 	repo := repository.NewSectionRepository(rh.DB)
-	uc := usecase.NewSectionUsecase(repo)
+	ttRepo := repository.NewTicketTypeRepository(rh.DB)
+	uc := usecase.NewSectionUsecase(repo, ttRepo)
 	handler := handler.NewSectionHandler(uc)
 
 	pvtRoutes := app.Group("/sections")
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupTicketTypeRoutes(rh *rest.ConfigRestHandler) {
+	app := rh.App
+
+	repo := repository.NewTicketTypeRepository(rh.DB)
+	eventRepo := repository.NewEventRepository(rh.DB)
+	uc := usecase.NewTicketTypeUsecase(repo, eventRepo)
+	handler := handler.NewTicketTypeHandler(uc)
+
+	// auth is applied by the /events group
+	ttRoutes := app.Group("/events/:id/ticket-types")
+	ttRoutes.Post("/", handler.CreateTicketType)
+	ttRoutes.Get("/", handler.ListTicketTypes)
+	ttRoutes.Get("/:ticketTypeID", handler.GetTicketType)
+	ttRoutes.Patch("/:ticketTypeID", handler.UpdateTicketType)
+	ttRoutes.Delete("/:ticketTypeID", handler.DeleteTicketType)
+}
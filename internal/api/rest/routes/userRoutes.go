@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/codepnw/go-ticket-booking/internal/api/rest"
 	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/repository"
 	"github.com/codepnw/go-ticket-booking/internal/usecase"
 )
@@ -10,29 +11,53 @@ import (
 func SetupUserRoutes(config *rest.ConfigRestHandler) {
 	app := config.App
 
-	authRepo := repository.NewAuthRepository(config.DB)
-	authUc := usecase.NewAuthUsecase(authRepo, config.Auth)
+	tx := database.NewSqlTxManager(config.DB)
 
+	authRepo := repository.NewAuthRepository(config.DB)
 	userRepo := repository.NewUserRepository(config.DB)
-	userUc := usecase.NewUserUsecase(userRepo, authRepo, config.Auth)
+	authUc := usecase.NewAuthUsecase(tx, authRepo, userRepo, config.Auth)
+
+	userUc := usecase.NewUserUsecase(
+		tx,
+		userRepo,
+		authRepo,
+		config.Auth,
+		config.Mailer,
+		config.EmailVerification,
+		config.PublicURL,
+	)
+
+	resetRepo := repository.NewPasswordResetRepository(config.DB)
+	passwordUc := usecase.NewPasswordUsecase(
+		tx,
+		userRepo,
+		authRepo,
+		resetRepo,
+		config.Mailer,
+		config.Auth,
+		config.PublicURL,
+	)
 
-	handler := handler.NewUserHandler(userUc, authUc)
+	handler := handler.NewUserHandler(userUc, authUc, passwordUc)
 
 	// Public Routes
 	app.Post("/register", handler.Register)
 	app.Post("/login", handler.Login)
 	app.Post("/auth/refresh-token", handler.RefreshToken)
-	// TODO
-	// app.Get("/auth/forgot-password", handler.ForgotPassword)
-	// app.Get("/auth/reset-password", handler.ResetPassword)
+	app.Post("/auth/forgot-password", handler.ForgotPassword)
+	app.Post("/auth/reset-password", handler.ResetPassword)
+	app.Post("/auth/verify-email", handler.VerifyEmail)
 
 	// Private Routes
 	pvt := app.Group("/users", config.Auth.Authorize)
 	pvt.Get("/profile", handler.GetProfile)
 	pvt.Patch("/profile", handler.UpdateProfile)
 	pvt.Get("/logout", handler.Logout)
-	// TODO
-	// pvt.Get("/change-password", handler.ChangePassword)
+	pvt.Get("/sessions", handler.ListSessions)
+	pvt.Delete("/sessions", handler.RevokeOtherSessions)
+	pvt.Delete("/sessions/:sessionID", handler.RevokeSession)
+	pvt.Post("/change-password", handler.ChangePassword)
+	pvt.Post("/verify-email/resend", handler.ResendVerification)
 
 	// Admin
 	admin := app.Group("/admin", config.Auth.Authorize)
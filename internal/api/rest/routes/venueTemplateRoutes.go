@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupVenueTemplateRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+	tx := database.NewSqlTxManager(db)
+
+	repo := repository.NewVenueTemplateRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	sectRepo := repository.NewSectionRepository(db)
+	seatRepo := repository.NewSeatRepository(db)
+	uc := usecase.NewVenueTemplateUsecase(tx, repo, eventRepo, sectRepo, seatRepo)
+	handler := handler.NewVenueTemplateHandler(uc)
+
+	locRoutes := app.Group("/locations/:id/templates", config.Auth.Authorize)
+	locRoutes.Post("/", handler.CreateTemplate)
+	locRoutes.Get("/", handler.ListTemplates)
+
+	templateRoutes := app.Group("/venue-templates", config.Auth.Authorize)
+	templateRoutes.Get("/:templateID", handler.GetTemplate)
+	templateRoutes.Patch("/:templateID", handler.UpdateTemplate)
+	templateRoutes.Delete("/:templateID", handler.DeleteTemplate)
+
+	// auth comes from the /events group
+	app.Post("/events/:id/apply-template", handler.ApplyTemplate)
+}
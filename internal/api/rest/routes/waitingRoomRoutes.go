@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupWaitingRoomRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+	tx := database.NewSqlTxManager(db)
+
+	queueRepo := repository.NewQueueRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	uc := usecase.NewWaitingRoomUsecase(tx, queueRepo, eventRepo, config.Admission)
+	handler := handler.NewWaitingRoomHandler(uc)
+
+	// set here as well so the routes stay protected without the /events group
+	queueRoutes := app.Group("/events/:id/queue", config.Auth.Authorize)
+	queueRoutes.Put("/", handler.ConfigureQueue)
+	queueRoutes.Get("/settings", handler.GetQueueSettings)
+	queueRoutes.Post("/", handler.JoinQueue)
+	queueRoutes.Get("/", handler.GetQueueStatus)
+}
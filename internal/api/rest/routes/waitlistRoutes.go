@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/codepnw/go-ticket-booking/internal/api/rest"
+	"github.com/codepnw/go-ticket-booking/internal/api/rest/handler"
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+)
+
+func SetupWaitlistRoutes(config *rest.ConfigRestHandler) {
+	app := config.App
+	db := config.DB
+	tx := database.NewSqlTxManager(db)
+
+	waitRepo := repository.NewWaitlistRepository(db)
+	bookRepo := repository.NewBookingRepository(db)
+	seatRepo := repository.NewSeatRepository(db)
+	sectRepo := repository.NewSectionRepository(db)
+	ttRepo := repository.NewTicketTypeRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	notifyRepo := repository.NewNotificationRepository(db)
+	uc := usecase.NewWaitlistUsecase(tx, waitRepo, bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, userRepo, notifyRepo)
+	handler := handler.NewWaitlistHandler(uc)
+
+	// auth comes from the /events group
+	waitRoutes := app.Group("/events/:id/waitlist")
+	waitRoutes.Post("/", handler.JoinWaitlist)
+	waitRoutes.Get("/", handler.GetWaitlistEntry)
+	waitRoutes.Delete("/", handler.LeaveWaitlist)
+	waitRoutes.Post("/accept", handler.AcceptOffer)
+	waitRoutes.Post("/decline", handler.DeclineOffer)
+}
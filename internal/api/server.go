@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"log"
 
 	"github.com/codepnw/go-ticket-booking/config"
@@ -8,6 +9,15 @@ import (
 	"github.com/codepnw/go-ticket-booking/internal/api/rest/routes"
 	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/helper/security"
+	"github.com/codepnw/go-ticket-booking/internal/mailer"
+	"github.com/codepnw/go-ticket-booking/internal/notification"
+	"github.com/codepnw/go-ticket-booking/internal/payment"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/seatstream"
+	"github.com/codepnw/go-ticket-booking/internal/usecase"
+	"github.com/codepnw/go-ticket-booking/internal/waitingroom"
+	"github.com/codepnw/go-ticket-booking/internal/worker"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -20,12 +30,51 @@ func StartServer(config config.AppConfig) {
 	}
 	defer db.Close()
 
-	auth := auth.SetupAuth(config.JWTSecret, config.JWTRefreshSecret)
+	var keys *auth.KeySet
+	if config.JWTKeysDir != "" {
+		keys, err = auth.LoadKeySet(config.JWTKeysDir, config.JWTSigningKeyID)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// tokens of ended sessions or issued before a password change are rejected
+	auth := auth.SetupAuth(config.JWTSecret).
+		WithKeys(keys).
+		WithRevocationCheck(repository.NewUserRepository(db)).
+		WithSessionCheck(repository.NewAuthRepository(db))
+
+	provider, err := payment.NewProvider(config.PaymentProvider, config.PaymentWebhookSecret, config.IsDevelopment())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mail, err := mailer.New(mailer.Config{
+		Driver:       config.MailDriver,
+		From:         config.MailFrom,
+		Dir:          config.MailDir,
+		SMTPHost:     config.SMTPHost,
+		SMTPPort:     config.SMTPPort,
+		SMTPUsername: config.SMTPUsername,
+		SMTPPassword: config.SMTPPassword,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	rhConfig := &rest.ConfigRestHandler{
-		App:  app,
-		DB:   db,
-		Auth: auth,
+		App:             app,
+		DB:              db,
+		Auth:            auth,
+		Payment:         provider,
+		PaymentCurrency: config.PaymentCurrency,
+		SeatHub:         seatstream.NewHub(seatstream.DefaultBuffer),
+		Admission:       waitingroom.NewSigner(config.QueueTokenSecret),
+		Mailer:          mail,
+		PublicURL:       config.PublicURL,
+
+		EmailVerification: security.NewVerificationSigner(config.EmailTokenSecret),
+		PaymentSimulation: config.IsDevelopment(),
 	}
 
 	rh, err := rest.NewRestHandler(rhConfig)
@@ -35,6 +84,19 @@ func StartServer(config config.AppConfig) {
 
 	setupRoutes(rh)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wake <-chan struct{}
+	if config.SeatStreamListen {
+		wake, err = seatstream.Listen(ctx, config.DBAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	startWorkers(ctx, rh, wake)
+
 	if err := app.Listen(config.AppPort); err != nil {
 		log.Fatal(err)
 	}
@@ -43,7 +105,85 @@ func StartServer(config config.AppConfig) {
 func setupRoutes(config *rest.ConfigRestHandler) {
 	routes.SetupUserRoutes(config)
 	routes.SetupEventRoutes(config)
+	routes.SetupTicketTypeRoutes(config)
 	routes.SetupSectionRoutes(config)
 	routes.SetupSeatRoutes(config)
 	routes.SetupBookingRoutes(config)
+	routes.SetupOrderRoutes(config)
+	routes.SetupPaymentRoutes(config)
+	routes.SetupRefundRoutes(config)
+	routes.SetupEventCancellationRoutes(config)
+	routes.SetupWaitlistRoutes(config)
+	routes.SetupVenueTemplateRoutes(config)
+	routes.SetupSeatMapRoutes(config)
+	routes.SetupSeatStreamRoutes(config)
+	routes.SetupWaitingRoomRoutes(config)
+	routes.SetupPromotionRoutes(config)
+	routes.SetupJWKSRoutes(config)
+}
+
+// startWorkers runs the background jobs. seatWake, if set, tells the seat
+// stream that new seat changes were committed.
+func startWorkers(ctx context.Context, config *rest.ConfigRestHandler, seatWake <-chan struct{}) {
+	db := config.DB
+	tx := database.NewSqlTxManager(db)
+
+	bookRepo := repository.NewBookingRepository(db)
+	seatRepo := repository.NewSeatRepository(db)
+	sectRepo := repository.NewSectionRepository(db)
+	ttRepo := repository.NewTicketTypeRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	promoRepo := repository.NewPromotionRepository(db)
+	userRepo := repository.NewUserRepository(db)
+
+	waitingRoomUc := usecase.NewWaitingRoomUsecase(tx, repository.NewQueueRepository(db), eventRepo, config.Admission)
+	worker.StartQueueAdmitter(ctx, waitingRoomUc)
+
+	bookingUc := usecase.NewBookingUsecase(tx, bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, promoRepo, userRepo, waitingRoomUc)
+	orderRepo := repository.NewOrderRepository(db)
+	orderUc := usecase.NewOrderUsecase(tx, orderRepo, bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, promoRepo, userRepo, waitingRoomUc)
+	worker.StartHoldSweeper(ctx, bookingUc, orderUc)
+
+	payRepo := repository.NewPaymentRepository(db)
+	paymentUc := usecase.NewPaymentUsecase(tx, payRepo, bookRepo, orderRepo, bookingUc, orderUc, config.Payment, config.PaymentCurrency)
+	worker.StartPaymentSettler(ctx, paymentUc)
+
+	refundUc := usecase.NewRefundUsecase(
+		tx,
+		bookRepo,
+		eventRepo,
+		payRepo,
+		repository.NewRefundRepository(db),
+		config.Payment,
+	)
+	worker.StartRefundRetrier(ctx, refundUc)
+
+	notifyRepo := repository.NewNotificationRepository(db)
+	cancelUc := usecase.NewEventCancellationUsecase(
+		tx,
+		eventRepo,
+		bookRepo,
+		repository.NewEventCancellationRepository(db),
+		notifyRepo,
+		refundUc,
+	)
+	worker.StartCancellationRunner(ctx, cancelUc)
+
+	waitlistUc := usecase.NewWaitlistUsecase(
+		tx,
+		repository.NewWaitlistRepository(db),
+		bookRepo,
+		seatRepo,
+		sectRepo,
+		ttRepo,
+		eventRepo,
+		repository.NewUserRepository(db),
+		notifyRepo,
+	)
+	worker.StartWaitlistRunner(ctx, waitlistUc)
+	worker.StartNotificationDispatcher(ctx, usecase.NewNotificationUsecase(notifyRepo, notification.NewLogNotifier()))
+
+	seatChangeRepo := repository.NewSeatChangeRepository(db)
+	go config.SeatHub.Follow(ctx, seatChangeRepo, seatWake, seatstream.DefaultPollInterval)
+	worker.StartSeatChangePruner(ctx, usecase.NewSeatStreamUsecase(config.SeatHub, seatChangeRepo, eventRepo))
 }
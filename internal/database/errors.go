@@ -0,0 +1,32 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+)
+
+// IsUniqueViolation reports whether err is a postgres unique violation on the
+// given constraint or index name.
+func IsUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == uniqueViolationCode && pqErr.Constraint == constraint
+}
+
+// IsForeignKeyViolation reports whether err is a postgres foreign key
+// violation on the given constraint name.
+func IsForeignKeyViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == foreignKeyViolationCode && pqErr.Constraint == constraint
+}
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_bookings_pending_expires_at;
+
+ALTER TABLE bookings DROP COLUMN expires_at;
+
+UPDATE bookings SET status = 'cancelled' WHERE status = 'expired';
+
+ALTER TABLE bookings
+DROP CONSTRAINT IF EXISTS bookings_status_check;
+
+ALTER TABLE bookings
+ADD CONSTRAINT bookings_status_check CHECK (status IN ('pending', 'confirmed', 'cancelled'));
@@ -0,0 +1,9 @@
+ALTER TABLE bookings
+DROP CONSTRAINT IF EXISTS bookings_status_check;
+
+ALTER TABLE bookings
+ADD CONSTRAINT bookings_status_check CHECK (status IN ('pending', 'confirmed', 'cancelled', 'expired'));
+
+ALTER TABLE bookings ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_bookings_pending_expires_at ON bookings (expires_at) WHERE status = 'pending';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_bookings_order_id;
+
+ALTER TABLE bookings DROP COLUMN order_id;
+
+DROP TABLE IF EXISTS orders;
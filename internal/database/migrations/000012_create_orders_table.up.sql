@@ -0,0 +1,17 @@
+CREATE TABLE orders (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'confirmed', 'cancelled', 'expired')),
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    confirmed_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_orders_pending_expires_at ON orders (expires_at) WHERE status = 'pending';
+
+ALTER TABLE bookings ADD COLUMN order_id BIGINT REFERENCES orders(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_bookings_order_id ON bookings (order_id);
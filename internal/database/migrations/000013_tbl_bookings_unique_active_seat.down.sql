@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS unique_active_seat_booking;
+
+ALTER TABLE bookings 
+ADD CONSTRAINT unique_booking UNIQUE (user_id, event_id, seat_id);
@@ -0,0 +1,36 @@
+-- two confirmed bookings on one seat need a refund decision, not a silent cancel
+DO $$
+DECLARE
+    dup_seats TEXT;
+BEGIN
+    SELECT string_agg(seat_id::TEXT, ', ' ORDER BY seat_id) INTO dup_seats
+    FROM (
+        SELECT seat_id
+        FROM bookings
+        WHERE status = 'confirmed'
+        GROUP BY seat_id
+        HAVING COUNT(*) > 1
+    ) dup;
+
+    IF dup_seats IS NOT NULL THEN
+        RAISE EXCEPTION 'seats with more than one confirmed booking: %. Cancel and refund the extra bookings, then re-run this migration', dup_seats;
+    END IF;
+END $$;
+
+-- keep only one active booking per seat before adding the index
+UPDATE bookings
+SET status = 'cancelled', cancelled_at = NOW()
+WHERE status = 'pending'
+AND id NOT IN (
+    SELECT DISTINCT ON (seat_id) id
+    FROM bookings
+    WHERE status IN ('pending', 'confirmed')
+    ORDER BY seat_id, (status = 'confirmed') DESC, created_at
+);
+
+CREATE UNIQUE INDEX unique_active_seat_booking
+ON bookings (seat_id) WHERE status IN ('pending', 'confirmed');
+
+-- superseded by unique_active_seat_booking, and blocked rebooking a cancelled seat
+ALTER TABLE bookings
+DROP CONSTRAINT IF EXISTS unique_booking;
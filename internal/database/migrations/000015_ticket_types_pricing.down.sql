@@ -0,0 +1,10 @@
+ALTER TABLE bookings
+DROP COLUMN ticket_type_id,
+DROP COLUMN price;
+
+ALTER TABLE seats DROP COLUMN ticket_type_id;
+
+ALTER TABLE sections DROP COLUMN ticket_type_id;
+
+ALTER TABLE ticket_types
+DROP CONSTRAINT IF EXISTS ticket_types_quantity_check;
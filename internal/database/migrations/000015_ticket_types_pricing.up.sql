@@ -0,0 +1,12 @@
+ALTER TABLE ticket_types
+ADD CONSTRAINT ticket_types_quantity_check CHECK (quantity >= 0);
+
+ALTER TABLE sections
+ADD COLUMN ticket_type_id INT REFERENCES ticket_types(id) ON DELETE SET NULL;
+
+ALTER TABLE seats
+ADD COLUMN ticket_type_id INT REFERENCES ticket_types(id) ON DELETE SET NULL;
+
+ALTER TABLE bookings
+ADD COLUMN ticket_type_id INT REFERENCES ticket_types(id) ON DELETE SET NULL,
+ADD COLUMN price DECIMAL(10,2);
@@ -0,0 +1,18 @@
+CREATE TABLE payments (
+    id SERIAL PRIMARY KEY,
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    order_id BIGINT REFERENCES orders(id) ON DELETE SET NULL,
+    provider TEXT NOT NULL,
+    provider_ref TEXT NOT NULL,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount >= 0),
+    currency VARCHAR(3) NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed', 'refunded')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT payments_target_check CHECK ((booking_id IS NULL) <> (order_id IS NULL)),
+    CONSTRAINT payments_provider_ref_key UNIQUE (provider, provider_ref)
+);
+
+CREATE INDEX idx_payments_booking_id ON payments (booking_id);
+
+CREATE INDEX idx_payments_order_id ON payments (order_id);
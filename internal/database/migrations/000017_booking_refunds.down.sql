@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS refunds;
+
+ALTER TABLE payments DROP COLUMN refunded_amount;
+
+UPDATE bookings SET status = 'cancelled' WHERE status = 'refunded';
+
+ALTER TABLE bookings
+DROP CONSTRAINT IF EXISTS bookings_status_check;
+
+ALTER TABLE bookings
+ADD CONSTRAINT bookings_status_check CHECK (status IN ('pending', 'confirmed', 'cancelled', 'expired'));
+
+ALTER TABLE events
+DROP CONSTRAINT IF EXISTS events_refund_policy_check,
+DROP COLUMN refund_full_hours,
+DROP COLUMN refund_partial_hours,
+DROP COLUMN refund_partial_percent;
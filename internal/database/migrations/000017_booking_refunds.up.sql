@@ -0,0 +1,33 @@
+ALTER TABLE events
+ADD COLUMN refund_full_hours INT NOT NULL DEFAULT 72,
+ADD COLUMN refund_partial_hours INT NOT NULL DEFAULT 24,
+ADD COLUMN refund_partial_percent INT NOT NULL DEFAULT 50,
+ADD CONSTRAINT events_refund_policy_check CHECK (
+    refund_partial_hours >= 0
+    AND refund_full_hours >= refund_partial_hours
+    AND refund_partial_percent BETWEEN 0 AND 100
+);
+
+ALTER TABLE bookings
+DROP CONSTRAINT IF EXISTS bookings_status_check;
+
+ALTER TABLE bookings
+ADD CONSTRAINT bookings_status_check CHECK (status IN ('pending', 'confirmed', 'cancelled', 'expired', 'refunded'));
+
+ALTER TABLE payments
+ADD COLUMN refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0);
+
+CREATE TABLE refunds (
+    id SERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    payment_id BIGINT REFERENCES payments(id) ON DELETE SET NULL,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount >= 0),
+    percent INT NOT NULL CHECK (percent BETWEEN 0 AND 100),
+    provider_ref TEXT,
+    reason TEXT,
+    forced BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_refunds_booking_id ON refunds (booking_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS event_status_transitions;
+
+ALTER TABLE events
+DROP CONSTRAINT IF EXISTS events_sales_window_check,
+DROP COLUMN sales_end_at,
+DROP COLUMN sales_start_at,
+DROP COLUMN status;
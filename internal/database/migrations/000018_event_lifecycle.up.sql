@@ -0,0 +1,23 @@
+ALTER TABLE events
+ADD COLUMN status TEXT NOT NULL DEFAULT 'draft'
+    CHECK (status IN ('draft', 'published', 'on_sale', 'sold_out', 'cancelled', 'postponed')),
+ADD COLUMN sales_start_at TIMESTAMPTZ,
+ADD COLUMN sales_end_at TIMESTAMPTZ,
+ADD CONSTRAINT events_sales_window_check CHECK (
+    sales_start_at IS NULL OR sales_end_at IS NULL OR sales_end_at > sales_start_at
+);
+
+-- events created before lifecycles existed were bookable right away
+UPDATE events SET status = 'on_sale';
+
+CREATE TABLE event_status_transitions (
+    id SERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_event_status_transitions_event_id ON event_status_transitions (event_id);
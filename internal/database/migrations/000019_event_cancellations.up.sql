@@ -0,0 +1,33 @@
+CREATE TABLE event_cancellations (
+    id SERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL UNIQUE REFERENCES events(id) ON DELETE CASCADE,
+    reason TEXT,
+    requested_by BIGINT NOT NULL REFERENCES users(id),
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed')),
+    last_booking_id BIGINT NOT NULL DEFAULT 0,
+    cancelled_count INT NOT NULL DEFAULT 0,
+    refunded_count INT NOT NULL DEFAULT 0,
+    failed_count INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_event_cancellations_running ON event_cancellations (id) WHERE status = 'running';
+
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id BIGINT REFERENCES events(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX unique_notification_user_event_kind ON notifications (user_id, event_id, kind);
+
+CREATE INDEX idx_notifications_unsent ON notifications (id) WHERE sent_at IS NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS unique_notification_user_event_kind;
+
+ALTER TABLE notifications DROP COLUMN ref_id;
+
+CREATE UNIQUE INDEX unique_notification_user_event_kind ON notifications (user_id, event_id, kind);
+
+DROP TABLE IF EXISTS waitlist_entries;
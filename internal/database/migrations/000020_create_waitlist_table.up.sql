@@ -0,0 +1,28 @@
+CREATE TABLE waitlist_entries (
+    id SERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    section_id BIGINT REFERENCES sections(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'offered', 'accepted', 'declined', 'expired', 'cancelled')),
+    seat_id BIGINT REFERENCES seats(id) ON DELETE SET NULL,
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    offer_expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ
+);
+
+-- one place in line per user and event
+CREATE UNIQUE INDEX unique_active_waitlist_entry ON waitlist_entries (event_id, user_id)
+WHERE status IN ('waiting', 'offered');
+
+CREATE INDEX idx_waitlist_entries_waiting ON waitlist_entries (event_id, id) WHERE status = 'waiting';
+
+CREATE INDEX idx_waitlist_entries_offer_expires_at ON waitlist_entries (offer_expires_at) WHERE status = 'offered';
+
+-- notifications about different offers to the same user must not collide
+ALTER TABLE notifications ADD COLUMN ref_id BIGINT NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS unique_notification_user_event_kind;
+
+CREATE UNIQUE INDEX unique_notification_user_event_kind ON notifications (user_id, event_id, kind, ref_id);
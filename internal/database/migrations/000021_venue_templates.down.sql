@@ -0,0 +1,9 @@
+ALTER TABLE sections
+DROP COLUMN template_version,
+DROP COLUMN template_id;
+
+DROP TABLE IF EXISTS venue_templates;
+
+ALTER TABLE seats
+DROP COLUMN aisle_after,
+DROP COLUMN is_accessible;
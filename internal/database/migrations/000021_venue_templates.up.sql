@@ -0,0 +1,22 @@
+ALTER TABLE seats
+ADD COLUMN is_accessible BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN aisle_after BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- layout holds sections, rows and seat ranges as one document, it is copied
+-- into sections and seats when applied to an event
+CREATE TABLE venue_templates (
+    id SERIAL PRIMARY KEY,
+    location_id BIGINT NOT NULL REFERENCES locations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    layout JSONB NOT NULL,
+    version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT unique_venue_template_name UNIQUE (location_id, name)
+);
+
+-- which template version a section was copied from
+ALTER TABLE sections
+ADD COLUMN template_id BIGINT REFERENCES venue_templates(id) ON DELETE SET NULL,
+ADD COLUMN template_version INT;
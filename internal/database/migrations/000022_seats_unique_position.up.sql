@@ -0,0 +1,49 @@
+-- duplicate positions without bookings are copies of the same seat: keep the
+-- booked row, or the oldest one, and drop the rest
+CREATE TEMP TABLE duplicate_seats AS
+SELECT s.id, keep.id AS keep_id
+FROM seats s
+JOIN LATERAL (
+    SELECT k.id
+    FROM seats k
+    WHERE k.section_id = s.section_id
+    AND k.row_label = s.row_label
+    AND k.seat_number = s.seat_number
+    ORDER BY EXISTS (SELECT 1 FROM bookings b WHERE b.seat_id = k.id) DESC, k.id
+    LIMIT 1
+) keep ON keep.id <> s.id
+WHERE NOT EXISTS (SELECT 1 FROM bookings b WHERE b.seat_id = s.id);
+
+UPDATE waitlist_entries w
+SET seat_id = d.keep_id
+FROM duplicate_seats d
+WHERE w.seat_id = d.id;
+
+DELETE FROM seats
+WHERE id IN (SELECT id FROM duplicate_seats);
+
+DROP TABLE duplicate_seats;
+
+-- duplicates that were booked need someone to move the bookings first
+DO $$
+DECLARE
+    dup_positions TEXT;
+BEGIN
+    SELECT string_agg(
+        format('section %s row %s seat %s (seats %s)', section_id, row_label, seat_number, ids),
+        '; ' ORDER BY section_id, row_label, seat_number
+    ) INTO dup_positions
+    FROM (
+        SELECT section_id, row_label, seat_number, string_agg(id::TEXT, ', ' ORDER BY id) AS ids
+        FROM seats
+        GROUP BY section_id, row_label, seat_number
+        HAVING COUNT(*) > 1
+    ) dup;
+
+    IF dup_positions IS NOT NULL THEN
+        RAISE EXCEPTION 'booked seats share a position: %. Move the bookings onto one seat and delete the others, then re-run this migration', dup_positions;
+    END IF;
+END $$;
+
+-- a seat position can only exist once per section
+CREATE UNIQUE INDEX unique_seat_position ON seats (section_id, row_label, seat_number);
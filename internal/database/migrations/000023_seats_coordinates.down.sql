@@ -0,0 +1,3 @@
+ALTER TABLE seats
+DROP COLUMN pos_y,
+DROP COLUMN pos_x;
@@ -0,0 +1,4 @@
+-- position of the seat on the venue drawing, in layout units
+ALTER TABLE seats
+ADD COLUMN pos_x DOUBLE PRECISION,
+ADD COLUMN pos_y DOUBLE PRECISION;
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS seat_changes_notify ON seat_changes;
+
+DROP FUNCTION IF EXISTS notify_seat_changes();
+
+DROP TABLE IF EXISTS seat_changes;
@@ -0,0 +1,26 @@
+-- every change to a seat's booking state, kept for a while so live seat
+-- stream clients can resume where they left off
+CREATE TABLE seat_changes (
+    id BIGSERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    seat_id BIGINT NOT NULL REFERENCES seats(id) ON DELETE CASCADE,
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    state TEXT NOT NULL CHECK (state IN ('held', 'confirmed', 'released')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_seat_changes_event_id ON seat_changes (event_id, id);
+
+CREATE INDEX idx_seat_changes_created_at ON seat_changes (created_at);
+
+-- wakes listening servers up; the rows themselves are read from the table
+CREATE FUNCTION notify_seat_changes() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('seat_changes', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER seat_changes_notify
+AFTER INSERT ON seat_changes
+FOR EACH STATEMENT EXECUTE FUNCTION notify_seat_changes();
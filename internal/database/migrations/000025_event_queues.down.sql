@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS queue_entries;
+
+DROP TABLE IF EXISTS event_queues;
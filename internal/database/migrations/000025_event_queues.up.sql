@@ -0,0 +1,25 @@
+CREATE TABLE event_queues (
+    event_id BIGINT PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    rate_per_minute INT NOT NULL CHECK (rate_per_minute > 0),
+    admission_minutes INT NOT NULL CHECK (admission_minutes > 0),
+    -- hands out queue positions in join order
+    last_position BIGINT NOT NULL DEFAULT 0,
+    admitted_until TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE queue_entries (
+    id BIGSERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL REFERENCES event_queues(event_id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    position BIGINT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'admitted')),
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    admitted_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    CONSTRAINT unique_queue_entry UNIQUE (event_id, user_id)
+);
+
+CREATE INDEX idx_queue_entries_waiting ON queue_entries (event_id, position) WHERE status = 'waiting';
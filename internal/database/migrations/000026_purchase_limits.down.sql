@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_bookings_user_event;
+
+ALTER TABLE ticket_types
+DROP COLUMN max_per_user;
+
+ALTER TABLE events
+DROP COLUMN max_tickets_per_user;
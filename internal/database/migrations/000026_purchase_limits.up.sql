@@ -0,0 +1,8 @@
+-- how many active tickets one user may hold, NULL for no limit
+ALTER TABLE events
+ADD COLUMN max_tickets_per_user INT CHECK (max_tickets_per_user > 0);
+
+ALTER TABLE ticket_types
+ADD COLUMN max_per_user INT CHECK (max_per_user > 0);
+
+CREATE INDEX idx_bookings_user_event ON bookings (user_id, event_id);
@@ -0,0 +1,5 @@
+ALTER TABLE bookings
+DROP COLUMN discount;
+
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,39 @@
+CREATE TABLE promotions (
+    id BIGSERIAL PRIMARY KEY,
+    -- stored upper case, codes are matched case-insensitively
+    code TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    discount_type TEXT NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    amount NUMERIC(10,2) NOT NULL CHECK (amount > 0),
+    event_id BIGINT REFERENCES events(id) ON DELETE CASCADE,
+    ticket_type_id BIGINT REFERENCES ticket_types(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    -- caps count discounted tickets, NULL for no cap
+    max_uses INT CHECK (max_uses > 0),
+    max_uses_per_user INT CHECK (max_uses_per_user > 0),
+    stackable BOOLEAN NOT NULL DEFAULT false,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT unique_promotion_code UNIQUE (code),
+    CONSTRAINT promotion_window CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at),
+    CONSTRAINT promotion_percent CHECK (discount_type <> 'percent' OR amount <= 100)
+);
+
+CREATE TABLE promotion_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    promotion_id BIGINT NOT NULL REFERENCES promotions(id) ON DELETE RESTRICT,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    discount NUMERIC(10,2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT unique_promotion_redemption UNIQUE (promotion_id, booking_id)
+);
+
+CREATE INDEX idx_promotion_redemptions_user ON promotion_redemptions (promotion_id, user_id);
+CREATE INDEX idx_promotion_redemptions_booking ON promotion_redemptions (booking_id);
+
+-- price stays the amount charged, discount is what promotions took off it
+ALTER TABLE bookings
+ADD COLUMN discount NUMERIC(10,2) NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+CREATE TABLE password_resets (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- sha256 of the emailed token, the token itself is never stored
+    token_hash TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT unique_password_reset_token UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_password_resets_user ON password_resets (user_id) WHERE used_at IS NULL;
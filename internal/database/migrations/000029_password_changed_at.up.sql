@@ -0,0 +1,3 @@
+-- tokens issued before this are rejected
+ALTER TABLE users
+ADD COLUMN password_changed_at TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+ALTER TABLE events
+DROP COLUMN require_verified_email;
+
+ALTER TABLE users
+DROP COLUMN email_verified_at;
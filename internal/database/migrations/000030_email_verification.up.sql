@@ -0,0 +1,6 @@
+-- set once the user follows the link in the verification email
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+ALTER TABLE events
+ADD COLUMN require_verified_email BOOLEAN NOT NULL DEFAULT FALSE;
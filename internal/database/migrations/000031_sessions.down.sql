@@ -0,0 +1,11 @@
+DROP TABLE refresh_tokens;
+DROP TABLE sessions;
+
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id),
+    token TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT refresh_tokens_user_id_key UNIQUE (user_id)
+);
@@ -0,0 +1,7 @@
+UPDATE event_cancellations SET status = 'completed' WHERE status = 'completed_with_errors';
+
+ALTER TABLE event_cancellations
+DROP CONSTRAINT IF EXISTS event_cancellations_status_check;
+
+ALTER TABLE event_cancellations
+ADD CONSTRAINT event_cancellations_status_check CHECK (status IN ('running', 'completed'));
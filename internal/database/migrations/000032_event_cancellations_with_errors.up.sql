@@ -0,0 +1,5 @@
+ALTER TABLE event_cancellations
+DROP CONSTRAINT IF EXISTS event_cancellations_status_check;
+
+ALTER TABLE event_cancellations
+ADD CONSTRAINT event_cancellations_status_check CHECK (status IN ('running', 'completed', 'completed_with_errors'));
@@ -0,0 +1,11 @@
+-- keys of different callers would collide without the scope
+DELETE FROM idempotency_keys;
+
+ALTER TABLE idempotency_keys
+DROP CONSTRAINT idempotency_keys_pkey;
+
+ALTER TABLE idempotency_keys
+DROP COLUMN scope;
+
+ALTER TABLE idempotency_keys
+ADD PRIMARY KEY (idempotency_key, request_method, request_path);
@@ -0,0 +1,12 @@
+-- keys are per caller, so one client cannot replay another's response
+ALTER TABLE idempotency_keys
+ADD COLUMN scope TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys
+ALTER COLUMN scope DROP DEFAULT;
+
+ALTER TABLE idempotency_keys
+DROP CONSTRAINT idempotency_keys_pkey;
+
+ALTER TABLE idempotency_keys
+ADD PRIMARY KEY (scope, idempotency_key, request_method, request_path);
@@ -0,0 +1,8 @@
+UPDATE payments SET status = 'succeeded' WHERE status = 'capturing';
+UPDATE payments SET status = 'refunded' WHERE status = 'refunding';
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check CHECK (status IN ('pending', 'succeeded', 'failed', 'refunded'));
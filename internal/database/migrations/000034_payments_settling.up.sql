@@ -0,0 +1,5 @@
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check CHECK (status IN ('pending', 'capturing', 'refunding', 'succeeded', 'failed', 'refunded'));
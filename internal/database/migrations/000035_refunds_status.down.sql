@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_refunds_pending;
+
+ALTER TABLE refunds
+DROP COLUMN status,
+DROP COLUMN attempts,
+DROP COLUMN last_error,
+DROP COLUMN updated_at;
@@ -0,0 +1,8 @@
+-- refunds are recorded before the provider is called and settled after
+ALTER TABLE refunds
+ADD COLUMN status TEXT NOT NULL DEFAULT 'succeeded' CHECK (status IN ('pending', 'succeeded', 'failed')),
+ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+ADD COLUMN last_error TEXT,
+ADD COLUMN updated_at TIMESTAMPTZ;
+
+CREATE INDEX idx_refunds_pending ON refunds (id) WHERE status = 'pending';
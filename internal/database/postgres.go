@@ -25,7 +25,9 @@ func InitPostgresDB(addr string) (*sql.DB, error) {
 }
 
 type TxManager interface {
-	WithTx(ctx context.Context, fn func(tx *sql.Tx) error) error
+	// WithTx runs fn in a transaction carried by the ctx passed to fn.
+	// Calls nested inside an active transaction join it.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type sqlTxManager struct {
@@ -36,7 +38,11 @@ func NewSqlTxManager(db *sql.DB) TxManager {
 	return &sqlTxManager{db: db}
 }
 
-func (m *sqlTxManager) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+func (m *sqlTxManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
 	tx, err := m.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -53,6 +59,6 @@ func (m *sqlTxManager) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) (e
 		}
 	}()
 
-	err = fn(tx)
+	err = fn(WithTxContext(ctx, tx))
 	return err
 }
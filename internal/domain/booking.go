@@ -3,12 +3,17 @@ package domain
 import "time"
 
 type Booking struct {
-	ID          int64      `json:"id"`
-	UserID      int64      `json:"user_id"`
-	EventID     int64      `json:"event_id"`
-	SeatID      int64      `json:"seat_id"`
-	Status      string     `json:"status"`
-	CreatedAt   time.Time  `json:"created_at"`
-	ConfirmedAt *time.Time `json:"confirmed_at"`
-	CancelledAt *time.Time `json:"cancelled_at"`
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	EventID      int64      `json:"event_id"`
+	SeatID       int64      `json:"seat_id"`
+	OrderID      *int64     `json:"order_id"`
+	TicketTypeID *int64     `json:"ticket_type_id"`
+	Price        *float64   `json:"price"`
+	Discount     float64    `json:"discount"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	CancelledAt  *time.Time `json:"cancelled_at"`
+	ExpiresAt    *time.Time `json:"expires_at"`
 }
@@ -3,12 +3,88 @@ package domain
 import "time"
 
 type Event struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	StartTime   time.Time `json:"start_time"`
-	EndTime     time.Time `json:"end_time"`
-	LocationID  int       `json:"location_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           int          `json:"id"`
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	StartTime    time.Time    `json:"start_time"`
+	EndTime      time.Time    `json:"end_time"`
+	LocationID   int          `json:"location_id"`
+	Status       string       `json:"status"`
+	SalesStartAt *time.Time   `json:"sales_start_at"`
+	SalesEndAt   *time.Time   `json:"sales_end_at"`
+	RefundPolicy RefundPolicy `json:"refund_policy"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+
+	// MaxTicketsPerUser caps a user's pending and confirmed bookings for
+	// the event; nil means no limit.
+	MaxTicketsPerUser *int `json:"max_tickets_per_user"`
+
+	// RequireVerifiedEmail only lets users with a verified email book.
+	RequireVerifiedEmail bool `json:"require_verified_email"`
+}
+
+// EventTransition records who moved an event between lifecycle states.
+type EventTransition struct {
+	ID         int64     `json:"id"`
+	EventID    int64     `json:"event_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  *int64    `json:"changed_by"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// InSalesWindow reports whether now falls inside the event's sales window.
+// An open bound means sales are not limited on that side.
+func (e *Event) InSalesWindow(now time.Time) bool {
+	if e.SalesStartAt != nil && now.Before(*e.SalesStartAt) {
+		return false
+	}
+	if e.SalesEndAt != nil && !now.Before(*e.SalesEndAt) {
+		return false
+	}
+	return true
+}
+
+// RefundPolicy decides how much of a confirmed booking is returned on
+// cancellation: everything until FullHours before the start, PartialPercent
+// until PartialHours before, nothing after that.
+type RefundPolicy struct {
+	FullHours      int `json:"full_hours"`
+	PartialHours   int `json:"partial_hours"`
+	PartialPercent int `json:"partial_percent"`
+}
+
+// RefundPercent returns the share of the price refunded when cancelling at now.
+func (e *Event) RefundPercent(now time.Time) int {
+	left := e.StartTime.Sub(now)
+	p := e.RefundPolicy
+
+	switch {
+	case left >= time.Duration(p.FullHours)*time.Hour:
+		return 100
+	case left >= time.Duration(p.PartialHours)*time.Hour:
+		return p.PartialPercent
+	default:
+		return 0
+	}
+}
+
+// EventCancellation tracks the batched cancel-and-refund of an event's
+// bookings. LastBookingID is the cursor a restarted run resumes from.
+type EventCancellation struct {
+	ID             int64      `json:"id"`
+	EventID        int64      `json:"event_id"`
+	Reason         string     `json:"reason"`
+	RequestedBy    int64      `json:"requested_by"`
+	Status         string     `json:"status"`
+	LastBookingID  int64      `json:"last_booking_id"`
+	CancelledCount int        `json:"cancelled_count"`
+	RefundedCount  int        `json:"refunded_count"`
+	FailedCount    int        `json:"failed_count"`
+	LastError      *string    `json:"last_error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
 }
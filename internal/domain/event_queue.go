@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// EventQueue is an event's waiting room. AdmittedUntil is the admission
+// clock: users are let in at RatePerMinute as it catches up with the time.
+type EventQueue struct {
+	EventID          int64      `json:"event_id"`
+	Enabled          bool       `json:"enabled"`
+	RatePerMinute    int        `json:"rate_per_minute"`
+	AdmissionMinutes int        `json:"admission_minutes"`
+	AdmittedUntil    time.Time  `json:"-"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at"`
+}
+
+type QueueEntry struct {
+	ID         int64      `json:"id"`
+	EventID    int64      `json:"event_id"`
+	UserID     int64      `json:"user_id"`
+	Position   int64      `json:"position"`
+	Status     string     `json:"status"`
+	JoinedAt   time.Time  `json:"joined_at"`
+	AdmittedAt *time.Time `json:"admitted_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// IdempotencyKey is a stored request and its response. Scope is the caller
+// that sent the key, so keys of different callers never collide.
+type IdempotencyKey struct {
+	Scope        string     `json:"scope"`
+	Key          string     `json:"key"`
+	Method       string     `json:"method"`
+	Path         string     `json:"path"`
+	RequestHash  string     `json:"request_hash"`
+	StatusCode   *int       `json:"status_code"`
+	ResponseBody []byte     `json:"response_body"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+type Notification struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Email     string     `json:"email"`
+	EventID   *int64     `json:"event_id"`
+	Kind      string     `json:"kind"`
+	RefID     int64      `json:"ref_id"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Attempts  int        `json:"attempts"`
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at"`
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+type Order struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	EventID     int64      `json:"event_id"`
+	Status      string     `json:"status"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+}
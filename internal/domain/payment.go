@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+type Payment struct {
+	ID             int64      `json:"id"`
+	BookingID      *int64     `json:"booking_id"`
+	OrderID        *int64     `json:"order_id"`
+	Provider       string     `json:"provider"`
+	ProviderRef    string     `json:"provider_ref"`
+	Amount         float64    `json:"amount"`
+	Currency       string     `json:"currency"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at"`
+}
+
+// Refund is money returned on a booking. It is pending until the provider
+// has paid it out.
+type Refund struct {
+	ID          int64     `json:"id"`
+	BookingID   int64     `json:"booking_id"`
+	PaymentID   *int64    `json:"payment_id"`
+	Amount      float64   `json:"amount"`
+	Percent     int       `json:"percent"`
+	ProviderRef *string   `json:"provider_ref"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	LastError   *string   `json:"last_error"`
+	Reason      string    `json:"reason"`
+	Forced      bool      `json:"forced"`
+	CreatedBy   *int64    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Promotion is a promo code. EventID and TicketTypeID narrow what it
+// applies to; nil means any.
+type Promotion struct {
+	ID             int64      `json:"id"`
+	Code           string     `json:"code"`
+	Description    string     `json:"description"`
+	DiscountType   string     `json:"discount_type"`
+	Amount         float64    `json:"amount"`
+	EventID        *int64     `json:"event_id"`
+	TicketTypeID   *int64     `json:"ticket_type_id"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	MaxUses        *int       `json:"max_uses"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user"`
+	Stackable      bool       `json:"stackable"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// InWindow reports whether t falls inside the promotion's validity window.
+func (p *Promotion) InWindow(t time.Time) bool {
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// PromotionRedemption records the discount one promotion gave one booking.
+type PromotionRedemption struct {
+	ID          int64     `json:"id"`
+	PromotionID int64     `json:"promotion_id"`
+	BookingID   int64     `json:"booking_id"`
+	UserID      int64     `json:"user_id"`
+	Discount    float64   `json:"discount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
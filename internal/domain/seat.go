@@ -1,9 +1,22 @@
 package domain
 
+// Seat is one seat of a section. AisleAfter marks an aisle between the seat
+// and the next one in its row; X and Y place it on the venue drawing.
 type Seat struct {
-	ID          int64  `json:"id"`
-	SectionID   int64  `json:"section_id"`
-	RowLabel    string `json:"row_label"`
-	SeatNumber  int    `json:"seat_number"`
-	IsAvailable bool   `json:"is_available"`
+	ID           int64    `json:"id"`
+	SectionID    int64    `json:"section_id"`
+	RowLabel     string   `json:"row_label"`
+	SeatNumber   int      `json:"seat_number"`
+	IsAvailable  bool     `json:"is_available"`
+	TicketTypeID *int64   `json:"ticket_type_id"`
+	IsAccessible bool     `json:"is_accessible"`
+	AisleAfter   bool     `json:"aisle_after"`
+	X            *float64 `json:"x"`
+	Y            *float64 `json:"y"`
+}
+
+// SeatState is a seat together with its current state for an event.
+type SeatState struct {
+	Seat
+	Status string `json:"status"`
 }
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// SeatChange records a seat moving between booking states: held, confirmed
+// or released.
+type SeatChange struct {
+	ID        int64     `json:"id"`
+	EventID   int64     `json:"event_id"`
+	SeatID    int64     `json:"seat_id"`
+	BookingID *int64    `json:"-"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -3,10 +3,13 @@ package domain
 import "time"
 
 type Section struct {
-	ID        int64     `json:"id"`
-	EventID   int64     `json:"event_id"`
-	Name      string    `json:"name"`
-	SeatCount int       `json:"seat_count"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}	
+	ID              int64     `json:"id"`
+	EventID         int64     `json:"event_id"`
+	Name            string    `json:"name"`
+	SeatCount       int       `json:"seat_count"`
+	TicketTypeID    *int64    `json:"ticket_type_id"`
+	TemplateID      *int64    `json:"template_id,omitempty"`
+	TemplateVersion *int      `json:"template_version,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
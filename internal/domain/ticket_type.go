@@ -3,11 +3,15 @@ package domain
 import "time"
 
 type TicketType struct {
-	ID        int       `json:"id"`
-	EventID   int       `json:"event_id"`
+	ID        int64     `json:"id"`
+	EventID   int64     `json:"event_id"`
 	Name      string    `json:"name"`
 	Price     float64   `json:"price"`
 	Quantity  int       `json:"quantity"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// MaxPerUser caps a user's pending and confirmed bookings of this
+	// ticket type; nil means no limit.
+	MaxPerUser *int `json:"max_per_user"`
 }
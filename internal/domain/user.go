@@ -13,4 +13,9 @@ type User struct {
 	LastLoginAt *time.Time `json:"last_login_at"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   *time.Time `json:"updated_at"`
+
+	// PasswordChangedAt invalidates tokens issued before it.
+	PasswordChangedAt *time.Time `json:"password_changed_at"`
+	// EmailVerifiedAt is nil until the user follows the verification link.
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
 }
\ No newline at end of file
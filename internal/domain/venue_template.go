@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+// VenueTemplate is a reusable seat map of a location. Applying it to an event
+// copies its sections and seats, so later edits do not touch that event.
+type VenueTemplate struct {
+	ID          int64       `json:"id"`
+	LocationID  int64       `json:"location_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Layout      VenueLayout `json:"layout"`
+	Version     int         `json:"version"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type VenueLayout struct {
+	Sections []LayoutSection `json:"sections"`
+}
+
+type LayoutSection struct {
+	Name string      `json:"name"`
+	Rows []LayoutRow `json:"rows"`
+}
+
+// LayoutRow lists the seat numbers of a row as ranges. AislesAfter holds the
+// seat numbers followed by an aisle and Accessible the wheelchair-accessible
+// seats.
+type LayoutRow struct {
+	Label       string      `json:"label"`
+	Ranges      []SeatRange `json:"ranges"`
+	AislesAfter []int       `json:"aisles_after,omitempty"`
+	Accessible  []int       `json:"accessible,omitempty"`
+}
+
+// SeatRange is an inclusive range of seat numbers.
+type SeatRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// SeatNumbers returns the row's seat numbers in range order.
+func (r LayoutRow) SeatNumbers() []int {
+	var numbers []int
+	for _, rg := range r.Ranges {
+		for n := rg.From; n <= rg.To; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+// SeatCount counts the row's seats without expanding the ranges. Seats
+// listed in two ranges count twice.
+func (r LayoutRow) SeatCount() int {
+	count := 0
+	for _, rg := range r.Ranges {
+		if rg.To >= rg.From {
+			count += rg.To - rg.From + 1
+		}
+	}
+	return count
+}
+
+func (s LayoutSection) SeatCount() int {
+	count := 0
+	for _, r := range s.Rows {
+		count += r.SeatCount()
+	}
+	return count
+}
+
+func (l VenueLayout) SeatCount() int {
+	count := 0
+	for _, s := range l.Sections {
+		count += s.SeatCount()
+	}
+	return count
+}
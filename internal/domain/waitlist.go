@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type WaitlistEntry struct {
+	ID             int64      `json:"id"`
+	EventID        int64      `json:"event_id"`
+	SectionID      *int64     `json:"section_id"`
+	UserID         int64      `json:"user_id"`
+	Status         string     `json:"status"`
+	SeatID         *int64     `json:"seat_id"`
+	BookingID      *int64     `json:"booking_id"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at"`
+}
@@ -8,12 +8,19 @@ const (
 	StatusPending   BookingStatus = "pending"
 	StatusConfirmed BookingStatus = "confirmed"
 	StatusCancelled BookingStatus = "cancelled"
+	StatusExpired   BookingStatus = "expired"
+	StatusRefunded  BookingStatus = "refunded"
 )
 
 type CreateBookingRequest struct {
 	UserID  int64 `json:"user_id" validate:"required"`
 	EventID int64 `json:"event_id" validate:"required"`
 	SeatID  int64 `json:"seat_id" validate:"required"`
+
+	PromoCodes []string `json:"promo_codes" validate:"omitempty,max=3,unique,dive,required"`
+
+	// AdmissionToken comes from the X-Admission-Token header.
+	AdmissionToken string `json:"-"`
 }
 
 type UpdateBookingRequest struct {
@@ -23,14 +30,20 @@ type UpdateBookingRequest struct {
 }
 
 type BookingResponse struct {
-	ID          int64        `json:"id"`
-	User        bookingUser  `json:"user"`
-	Event       bookingEvent `json:"event"`
-	Seat        bookingSeat  `json:"seat"`
-	Status      string       `json:"status"`
-	CreatedAt   time.Time    `json:"created_at"`
-	ConfirmedAt *time.Time   `json:"confirmed_at"`
-	CancelledAt *time.Time   `json:"cancelled_at"`
+	ID             int64        `json:"id"`
+	OrderID        *int64       `json:"order_id"`
+	User           bookingUser  `json:"user"`
+	Event          bookingEvent `json:"event"`
+	Seat           bookingSeat  `json:"seat"`
+	TicketTypeID   *int64       `json:"ticket_type_id"`
+	TicketTypeName *string      `json:"ticket_type_name"`
+	Price          *float64     `json:"price"`
+	Discount       float64      `json:"discount"`
+	Status         string       `json:"status"`
+	CreatedAt      time.Time    `json:"created_at"`
+	ConfirmedAt    *time.Time   `json:"confirmed_at"`
+	CancelledAt    *time.Time   `json:"cancelled_at"`
+	ExpiresAt      *time.Time   `json:"expires_at"`
 }
 
 type BookingSeatUpdateRequest struct {
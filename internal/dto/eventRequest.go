@@ -2,12 +2,32 @@ package dto
 
 import "time"
 
+type EventStatus string
+
+const (
+	EventDraft     EventStatus = "draft"
+	EventPublished EventStatus = "published"
+	EventOnSale    EventStatus = "on_sale"
+	EventSoldOut   EventStatus = "sold_out"
+	EventCancelled EventStatus = "cancelled"
+	EventPostponed EventStatus = "postponed"
+)
+
 type EventRequest struct {
 	Name        string    `json:"name" validate:"required"`
 	Description string    `json:"description"`
 	StartTime   time.Time `json:"start_time" validate:"required"`
 	EndTime     time.Time `json:"end_time" validate:"required"`
 	LocationID  int       `json:"location_id" validate:"gte=0"`
+
+	SalesStartAt *time.Time `json:"sales_start_at"`
+	SalesEndAt   *time.Time `json:"sales_end_at"`
+
+	RefundPolicy *RefundPolicyRequest `json:"refund_policy"`
+
+	MaxTicketsPerUser *int `json:"max_tickets_per_user" validate:"omitempty,min=1"`
+
+	RequireVerifiedEmail bool `json:"require_verified_email"`
 }
 
 type EventUpdateRequest struct {
@@ -16,8 +36,41 @@ type EventUpdateRequest struct {
 	StartTime   *time.Time `json:"start_time"`
 	EndTime     *time.Time `json:"end_time"`
 	LocationID  *int       `json:"location_id"`
+
+	SalesStartAt *time.Time `json:"sales_start_at"`
+	SalesEndAt   *time.Time `json:"sales_end_at"`
+
+	RefundPolicy *RefundPolicyRequest `json:"refund_policy"`
+
+	// MaxTicketsPerUser of 0 removes the limit.
+	MaxTicketsPerUser *int `json:"max_tickets_per_user" validate:"omitempty,gte=0"`
+
+	RequireVerifiedEmail *bool `json:"require_verified_email"`
+}
+
+type RefundPolicyRequest struct {
+	FullHours      int `json:"full_hours" validate:"gtefield=PartialHours"`
+	PartialHours   int `json:"partial_hours" validate:"gte=0"`
+	PartialPercent int `json:"partial_percent" validate:"gte=0,lte=100"`
+}
+
+type EventStatusRequest struct {
+	Status EventStatus `json:"status" validate:"required,oneof=draft published on_sale sold_out postponed"`
+	Reason string      `json:"reason"`
 }
 
+type CancelEventRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type CancellationStatus string
+
+const (
+	CancellationRunning             CancellationStatus = "running"
+	CancellationCompleted           CancellationStatus = "completed"
+	CancellationCompletedWithErrors CancellationStatus = "completed_with_errors"
+)
+
 type LocationRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description"`
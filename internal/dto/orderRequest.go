@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type CreateOrderRequest struct {
+	UserID  int64   `json:"user_id" validate:"required"`
+	EventID int64   `json:"event_id" validate:"required"`
+	SeatIDs []int64 `json:"seat_ids" validate:"required,min=1,max=10,unique,dive,required"`
+
+	PromoCodes []string `json:"promo_codes" validate:"omitempty,max=3,unique,dive,required"`
+
+	// AdmissionToken comes from the X-Admission-Token header.
+	AdmissionToken string `json:"-"`
+}
+
+// BestAvailableOrderRequest lets the server pick the seats. Preference is
+// "center" (default), "front" or "aisle"; AllowSplit spreads the group over
+// nearby rows when no single row has enough adjacent seats.
+type BestAvailableOrderRequest struct {
+	UserID     int64  `json:"user_id" validate:"required"`
+	EventID    int64  `json:"event_id" validate:"required"`
+	SectionID  *int64 `json:"section_id"`
+	Quantity   int    `json:"quantity" validate:"required,min=1,max=10"`
+	Preference string `json:"preference" validate:"omitempty,oneof=center front aisle"`
+	AllowSplit bool   `json:"allow_split"`
+
+	PromoCodes []string `json:"promo_codes" validate:"omitempty,max=3,unique,dive,required"`
+
+	// AdmissionToken comes from the X-Admission-Token header.
+	AdmissionToken string `json:"-"`
+}
+
+type OrderResponse struct {
+	ID          int64              `json:"id"`
+	UserID      int64              `json:"user_id"`
+	EventID     int64              `json:"event_id"`
+	Status      string             `json:"status"`
+	ExpiresAt   *time.Time         `json:"expires_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	ConfirmedAt *time.Time         `json:"confirmed_at"`
+	CancelledAt *time.Time         `json:"cancelled_at"`
+	Bookings    []*BookingResponse `json:"bookings"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentSucceeded PaymentStatus = "succeeded"
+	PaymentFailed    PaymentStatus = "failed"
+	PaymentRefunded  PaymentStatus = "refunded"
+
+	// Capturing and refunding payments are settled with the provider after
+	// the booking or order change is committed.
+	PaymentCapturing PaymentStatus = "capturing"
+	PaymentRefunding PaymentStatus = "refunding"
+)
+
+type PaymentResponse struct {
+	PaymentID    int64   `json:"payment_id"`
+	BookingID    *int64  `json:"booking_id"`
+	OrderID      *int64  `json:"order_id"`
+	Provider     string  `json:"provider"`
+	ProviderRef  string  `json:"provider_ref"`
+	ClientSecret string  `json:"client_secret,omitempty"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency"`
+	Status       string  `json:"status"`
+}
+
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending"
+	RefundSucceeded RefundStatus = "succeeded"
+	RefundFailed    RefundStatus = "failed"
+)
+
+type ForceRefundRequest struct {
+	Amount *float64 `json:"amount" validate:"omitempty,gte=0"`
+	Reason string   `json:"reason" validate:"required"`
+}
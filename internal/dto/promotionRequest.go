@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// PromotionRequest creates a promo code. A percent Amount is at most 100.
+// EventID and TicketTypeID narrow the scope; a ticket type implies its
+// event.
+type PromotionRequest struct {
+	Code           string     `json:"code" validate:"required,min=3,max=32,alphanum"`
+	Description    string     `json:"description"`
+	DiscountType   string     `json:"discount_type" validate:"required,oneof=percent fixed"`
+	Amount         float64    `json:"amount" validate:"required,gt=0"`
+	EventID        *int64     `json:"event_id" validate:"omitempty,gt=0"`
+	TicketTypeID   *int64     `json:"ticket_type_id" validate:"omitempty,gt=0"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	MaxUses        *int       `json:"max_uses" validate:"omitempty,min=1"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user" validate:"omitempty,min=1"`
+	Stackable      bool       `json:"stackable"`
+	Active         *bool      `json:"active"`
+}
+
+// PromotionUpdateRequest changes a promo code. The code, discount type and
+// scope are fixed once created. A cap of 0 removes it.
+type PromotionUpdateRequest struct {
+	Description    *string    `json:"description"`
+	Amount         *float64   `json:"amount" validate:"omitempty,gt=0"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	MaxUses        *int       `json:"max_uses" validate:"omitempty,gte=0"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user" validate:"omitempty,gte=0"`
+	Stackable      *bool      `json:"stackable"`
+	Active         *bool      `json:"active"`
+}
+
+// PromotionReport sums up how a promo code has been used. Only confirmed
+// bookings and unexpired holds count as uses; TotalDiscount and Revenue
+// cover confirmed bookings.
+type PromotionReport struct {
+	PromotionID   int64                  `json:"promotion_id"`
+	Code          string                 `json:"code"`
+	Uses          int                    `json:"uses"`
+	Confirmed     int                    `json:"confirmed"`
+	Users         int                    `json:"users"`
+	TotalDiscount float64                `json:"total_discount"`
+	Revenue       float64                `json:"revenue"`
+	Redemptions   []*PromotionRedemption `json:"redemptions"`
+}
+
+// PromotionRedemption is one discounted booking in a report.
+type PromotionRedemption struct {
+	BookingID     int64     `json:"booking_id"`
+	OrderID       *int64    `json:"order_id"`
+	UserID        int64     `json:"user_id"`
+	EventID       int64     `json:"event_id"`
+	BookingStatus string    `json:"booking_status"`
+	Discount      float64   `json:"discount"`
+	Price         *float64  `json:"price"`
+	CreatedAt     time.Time `json:"created_at"`
+}
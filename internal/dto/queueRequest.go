@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type QueueSettingsRequest struct {
+	Enabled          bool `json:"enabled"`
+	RatePerMinute    int  `json:"rate_per_minute" validate:"required,min=1,max=100000"`
+	AdmissionMinutes int  `json:"admission_minutes" validate:"required,min=1,max=240"`
+}
+
+// QueueStatusResponse tells a user where they stand. AdmissionToken is set
+// once they are admitted; it goes in the X-Admission-Token header of
+// booking requests until ExpiresAt.
+type QueueStatusResponse struct {
+	EventID              int64      `json:"event_id"`
+	Status               string     `json:"status"`
+	Position             int64      `json:"position"`
+	Ahead                int64      `json:"ahead"`
+	EstimatedWaitSeconds int64      `json:"estimated_wait_seconds"`
+	AdmissionToken       string     `json:"admission_token,omitempty"`
+	ExpiresAt            *time.Time `json:"expires_at,omitempty"`
+}
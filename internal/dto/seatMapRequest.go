@@ -0,0 +1,34 @@
+package dto
+
+type SeatMapConflictMode string
+
+const (
+	// ConflictFail rejects the import when a seat already exists.
+	ConflictFail SeatMapConflictMode = "fail"
+	// ConflictUpdate overwrites existing seats with the imported values.
+	ConflictUpdate SeatMapConflictMode = "update"
+)
+
+type SeatMapImportRequest struct {
+	Format     string              `query:"format" validate:"omitempty,oneof=csv json"`
+	DryRun     bool                `query:"dry_run"`
+	OnConflict SeatMapConflictMode `query:"on_conflict" validate:"omitempty,oneof=fail update"`
+}
+
+type SeatMapConflict struct {
+	Section string `json:"section"`
+	Row     string `json:"row,omitempty"`
+	Number  int    `json:"number,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// SeatMapImportReport says what an import did, or would do on a dry run.
+type SeatMapImportReport struct {
+	DryRun          bool               `json:"dry_run"`
+	Applied         bool               `json:"applied"`
+	SectionsCreated []string           `json:"sections_created"`
+	SeatsCreated    int                `json:"seats_created"`
+	SeatsUpdated    int                `json:"seats_updated"`
+	SeatsUnchanged  int                `json:"seats_unchanged"`
+	Conflicts       []*SeatMapConflict `json:"conflicts"`
+}
@@ -1,9 +1,14 @@
 package dto
 
 type CreateSeatRequest struct {
-	SectionID  int64  `json:"section_id" validate:"required"`
-	RowLabel   string `json:"row_label" validate:"required"`
-	SeatNumber int    `json:"seat_number" validate:"required"`
+	SectionID    int64    `json:"section_id" validate:"required"`
+	RowLabel     string   `json:"row_label" validate:"required"`
+	SeatNumber   int      `json:"seat_number" validate:"required"`
+	TicketTypeID *int64   `json:"ticket_type_id"`
+	IsAccessible bool     `json:"is_accessible"`
+	AisleAfter   bool     `json:"aisle_after"`
+	X            *float64 `json:"x"`
+	Y            *float64 `json:"y"`
 }
 
 type CreateSeatsRequest struct {
@@ -11,8 +16,62 @@ type CreateSeatsRequest struct {
 }
 
 type UpdateSeatRequest struct {
-	SectionID   *int64  `json:"section_id"`
-	RowLabel    *string `json:"row_label"`
-	SeatNumber  *int    `json:"seat_number"`
-	IsAvailable *bool   `json:"is_available"`
+	SectionID    *int64   `json:"section_id"`
+	RowLabel     *string  `json:"row_label"`
+	SeatNumber   *int     `json:"seat_number"`
+	IsAvailable  *bool    `json:"is_available"`
+	TicketTypeID *int64   `json:"ticket_type_id"`
+	IsAccessible *bool    `json:"is_accessible"`
+	AisleAfter   *bool    `json:"aisle_after"`
+	X            *float64 `json:"x"`
+	Y            *float64 `json:"y"`
+}
+
+type SeatNumbering string
+
+const (
+	NumberingAll  SeatNumbering = "all"
+	NumberingOdd  SeatNumbering = "odd"
+	NumberingEven SeatNumbering = "even"
+)
+
+// GenerateSeatsRequest describes a block of seats: every row from RowFrom to
+// RowTo (letters like "A"-"T" or numbers like "1"-"20") gets the seats
+// SeatFrom to SeatTo, filtered by Numbering and the skip lists.
+type GenerateSeatsRequest struct {
+	SectionID    int64          `json:"section_id" validate:"required"`
+	RowFrom      string         `json:"row_from" validate:"required,max=10"`
+	RowTo        string         `json:"row_to" validate:"required,max=10"`
+	SeatFrom     int            `json:"seat_from" validate:"required,min=1"`
+	SeatTo       int            `json:"seat_to" validate:"required,gtefield=SeatFrom"`
+	Numbering    SeatNumbering  `json:"numbering" validate:"omitempty,oneof=all odd even"`
+	SkipRows     []string       `json:"skip_rows"`
+	SkipNumbers  []int          `json:"skip_numbers"`
+	SkipSeats    []SeatPosition `json:"skip_seats" validate:"dive"`
+	TicketTypeID *int64         `json:"ticket_type_id"`
+}
+
+type SeatPosition struct {
+	RowLabel   string `json:"row_label" validate:"required"`
+	SeatNumber int    `json:"seat_number" validate:"required"`
 }
+
+type GenerateSeatsResponse struct {
+	SectionID        int64    `json:"section_id"`
+	Rows             []string `json:"rows"`
+	Created          int      `json:"created"`
+	Skipped          int      `json:"skipped"`
+	SectionSeats     int      `json:"section_seats"`
+	SectionSeatCount int      `json:"section_seat_count"`
+	EventSeats       int      `json:"event_seats"`
+	LocationCapacity int64    `json:"location_capacity"`
+}
+
+type SeatStatus string
+
+const (
+	SeatAvailable SeatStatus = "available"
+	SeatHeld      SeatStatus = "held"
+	SeatBooked    SeatStatus = "booked"
+	SeatBlocked   SeatStatus = "blocked"
+)
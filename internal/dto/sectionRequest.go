@@ -3,14 +3,16 @@ package dto
 import "time"
 
 type SectionRequest struct {
-	EventID   int64  `json:"event_id" validate:"required"`
-	Name      string `json:"name" validate:"required"`
-	SeatCount int    `json:"seat_count" validate:"gte=0,lte=200"`
+	EventID      int64  `json:"event_id" validate:"required"`
+	Name         string `json:"name" validate:"required"`
+	SeatCount    int    `json:"seat_count" validate:"gte=0,lte=200"`
+	TicketTypeID *int64 `json:"ticket_type_id"`
 }
 
 type SectionUpdate struct {
-	EventID   *int64    `json:"event_id"`
-	Name      *string   `json:"name"`
-	SeatCount *int      `json:"seat_count"`
-	UpdatedAt time.Time `json:"updated_at"`
+	EventID      *int64    `json:"event_id"`
+	Name         *string   `json:"name"`
+	SeatCount    *int      `json:"seat_count"`
+	TicketTypeID *int64    `json:"ticket_type_id"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+// Device describes the client a session is started from. UserAgent and
+// IPAddress are filled in by the handler.
+type Device struct {
+	Name      string `json:"device_name" validate:"max=100"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	UserAgent    string `json:"-"`
+	IPAddress    string `json:"-"`
+}
+
+type SessionResponse struct {
+	ID         int64     `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Current marks the session of the token the request was made with.
+	Current bool `json:"current"`
+}
+
+func NewSessionResponse(s *domain.Session, currentID int64) *SessionResponse {
+	return &SessionResponse{
+		ID:         s.ID,
+		DeviceName: s.DeviceName,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    s.ID == currentID,
+	}
+}
@@ -0,0 +1,18 @@
+package dto
+
+type TicketTypeRequest struct {
+	Name     string  `json:"name" validate:"required"`
+	Price    float64 `json:"price" validate:"gte=0"`
+	Quantity int     `json:"quantity" validate:"gte=0"`
+
+	MaxPerUser *int `json:"max_per_user" validate:"omitempty,min=1"`
+}
+
+type TicketTypeUpdateRequest struct {
+	Name     *string  `json:"name"`
+	Price    *float64 `json:"price" validate:"omitempty,gte=0"`
+	Quantity *int     `json:"quantity" validate:"omitempty,gte=0"`
+
+	// MaxPerUser of 0 removes the limit.
+	MaxPerUser *int `json:"max_per_user" validate:"omitempty,gte=0"`
+}
@@ -17,7 +17,7 @@ const (
 type UserRegisterRequest struct {
 	FirstName string `json:"first_name" validate:"required"`
 	LastName  string `json:"last_name" validate:"required"`
-	Email     string `json:"email" validate:"required"`
+	Email     string `json:"email" validate:"required,email"`
 	Phone     string `json:"phone" validate:"required"`
 	Password  string `json:"password" validate:"required"`
 }
@@ -25,6 +25,28 @@ type UserRegisterRequest struct {
 type UserLoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+
+	Device
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+
+	Device
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
 }
 
 type UserUpdateRequest struct {
@@ -42,6 +64,8 @@ type UserResponse struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   *time.Time `json:"updated_at"`
 	LastLoginAt *time.Time `json:"last_login_at"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
 }
 
 func NewUserResponse(u *domain.User) *UserResponse {
@@ -54,5 +78,7 @@ func NewUserResponse(u *domain.User) *UserResponse {
 		CreatedAt:   u.CreatedAt,
 		UpdatedAt:   u.UpdatedAt,
 		LastLoginAt: u.LastLoginAt,
+
+		EmailVerifiedAt: u.EmailVerifiedAt,
 	}
 }
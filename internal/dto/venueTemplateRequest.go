@@ -0,0 +1,26 @@
+package dto
+
+import "github.com/codepnw/go-ticket-booking/internal/domain"
+
+type VenueTemplateRequest struct {
+	Name        string             `json:"name" validate:"required,max=100"`
+	Description string             `json:"description"`
+	Layout      domain.VenueLayout `json:"layout"`
+}
+
+type VenueTemplateUpdateRequest struct {
+	Name        *string             `json:"name" validate:"omitempty,max=100"`
+	Description *string             `json:"description"`
+	Layout      *domain.VenueLayout `json:"layout"`
+}
+
+type ApplyTemplateRequest struct {
+	TemplateID int64 `json:"template_id" validate:"required"`
+}
+
+type ApplyTemplateResponse struct {
+	TemplateID      int64             `json:"template_id"`
+	TemplateVersion int               `json:"template_version"`
+	SeatCount       int               `json:"seat_count"`
+	Sections        []*domain.Section `json:"sections"`
+}
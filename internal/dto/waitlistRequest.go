@@ -0,0 +1,24 @@
+package dto
+
+import "github.com/codepnw/go-ticket-booking/internal/domain"
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "waiting"
+	WaitlistOffered   WaitlistStatus = "offered"
+	WaitlistAccepted  WaitlistStatus = "accepted"
+	WaitlistDeclined  WaitlistStatus = "declined"
+	WaitlistExpired   WaitlistStatus = "expired"
+	WaitlistCancelled WaitlistStatus = "cancelled"
+)
+
+type JoinWaitlistRequest struct {
+	SectionID *int64 `json:"section_id" validate:"omitempty,gt=0"`
+}
+
+type WaitlistResponse struct {
+	*domain.WaitlistEntry
+	// Position is the number of people ahead while waiting.
+	Position int `json:"position"`
+}
@@ -3,18 +3,88 @@ package errs
 import "errors"
 
 var (
-	ErrUserNotFound     = errors.New("user not found")
-	ErrSeatNotFound     = errors.New("seat not found")
-	ErrSectionNotFound  = errors.New("section not found")
-	ErrEventNotFound    = errors.New("event not found")
-	ErrLocationNotFound = errors.New("location not found")
-	ErrBookingNotFound  = errors.New("booking not found")
+	ErrUserNotFound          = errors.New("user not found")
+	ErrSeatNotFound          = errors.New("seat not found")
+	ErrSectionNotFound       = errors.New("section not found")
+	ErrEventNotFound         = errors.New("event not found")
+	ErrLocationNotFound      = errors.New("location not found")
+	ErrBookingNotFound       = errors.New("booking not found")
+	ErrOrderNotFound         = errors.New("order not found")
+	ErrTicketTypeNotFound    = errors.New("ticket type not found")
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrRefundNotFound        = errors.New("refund not found")
+	ErrCancellationNotFound  = errors.New("event cancellation not found")
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+	ErrTemplateNotFound      = errors.New("venue template not found")
+	ErrQueueNotFound         = errors.New("event has no waiting room")
+	ErrNotInQueue            = errors.New("not in the waiting room for this event")
+	ErrPromotionNotFound     = errors.New("promotion not found")
 
-	ErrNoFieldsToUpdate        = errors.New("no fields to update")
-	ErrInvalidInputData        = errors.New("invalid input data")
-	ErrInvalidSeatEvent        = errors.New("invalid seat event")
-	ErrSeatAlreadyBooked       = errors.New("seat already booked")
-	ErrBookingAlreadyConfirmed = errors.New("booking already confirmed")
-	ErrBookingAlreadyCancelled = errors.New("booking already cancelled")
-	ErrBookingNotPending       = errors.New("cannot update status confirmed or cancelled booking")
+	ErrNoFieldsToUpdate         = errors.New("no fields to update")
+	ErrInvalidInputData         = errors.New("invalid input data")
+	ErrInvalidSeatEvent         = errors.New("invalid seat event")
+	ErrSeatAlreadyBooked        = errors.New("seat already booked")
+	ErrBookingAlreadyConfirmed  = errors.New("booking already confirmed")
+	ErrBookingAlreadyCancelled  = errors.New("booking already cancelled")
+	ErrBookingNotPending        = errors.New("cannot update status confirmed or cancelled booking")
+	ErrBookingHoldExpired       = errors.New("booking hold expired")
+	ErrBookingPartOfOrder       = errors.New("booking belongs to an order, update the order instead")
+	ErrOrderAlreadyConfirmed    = errors.New("order already confirmed")
+	ErrOrderAlreadyCancelled    = errors.New("order already cancelled")
+	ErrOrderHoldExpired         = errors.New("order hold expired")
+	ErrTicketTypeSoldOut        = errors.New("ticket type sold out")
+	ErrInvalidTicketTypeEvent   = errors.New("ticket type does not belong to event")
+	ErrTicketTypeMismatch       = errors.New("new seat has a different ticket type")
+	ErrPaymentAmountMismatch    = errors.New("payment amount does not match")
+	ErrBookingNotConfirmed      = errors.New("only confirmed bookings can be refunded")
+	ErrRefundWindowClosed       = errors.New("cancellation policy allows no refund at this time")
+	ErrRefundExceedsPaid        = errors.New("refund amount exceeds amount paid")
+	ErrInvalidEventTransition   = errors.New("invalid event status transition")
+	ErrEventNotOnSale           = errors.New("event is not on sale")
+	ErrInvalidSalesWindow       = errors.New("sales end must be after sales start")
+	ErrEventAlreadyCancelled    = errors.New("event already cancelled")
+	ErrEventNotDraft            = errors.New("only draft events can be deleted, cancel the event instead")
+	ErrAlreadyOnWaitlist        = errors.New("already on the waitlist for this event")
+	ErrNoWaitlistOffer          = errors.New("no open waitlist offer")
+	ErrWaitlistClosed           = errors.New("event does not take a waitlist")
+	ErrNotEnoughSeats           = errors.New("not enough adjacent seats available")
+	ErrInvalidVenueLayout       = errors.New("invalid venue layout")
+	ErrTemplateNameTaken        = errors.New("location already has a template with this name")
+	ErrTemplateWrongLocation    = errors.New("template belongs to another location than the event")
+	ErrEventHasSeatMap          = errors.New("event already has sections")
+	ErrSeatExists               = errors.New("seat already exists at this position")
+	ErrInvalidSeatSpec          = errors.New("invalid seat specification")
+	ErrSectionSeatCountExceeded = errors.New("seats exceed the section seat count")
+	ErrLocationCapacityExceeded = errors.New("seats exceed the location capacity")
+	ErrSeatMapConflicts         = errors.New("seat map conflicts with existing seats, nothing was imported")
+	ErrQueueClosed              = errors.New("event waiting room is not open")
+	ErrAdmissionRequired        = errors.New("event is in queue mode, join the waiting room for an admission token")
+	ErrInvalidAdmission         = errors.New("admission token is invalid or expired")
+	ErrEventTicketLimit         = errors.New("ticket limit per user reached for this event")
+	ErrTicketTypeLimit          = errors.New("ticket limit per user reached for this ticket type")
+	ErrInvalidPromotion         = errors.New("invalid promotion")
+	ErrPromoCodeTaken           = errors.New("promo code already exists")
+	ErrPromotionInUse           = errors.New("promotion has redemptions, deactivate it instead")
+	ErrInvalidPromoCode         = errors.New("promo code is invalid or expired")
+	ErrPromotionNotApplicable   = errors.New("promo code does not apply to these tickets")
+	ErrPromotionNotStackable    = errors.New("promo code cannot be combined with other codes")
+	ErrPromotionUsageLimit      = errors.New("promo code usage limit reached")
+	ErrInvalidResetToken        = errors.New("reset link is invalid or expired")
+	ErrWrongPassword            = errors.New("current password is incorrect")
+	ErrSamePassword             = errors.New("new password must differ from the current one")
+	ErrInvalidVerificationLink  = errors.New("verification link is invalid or expired")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrEmailNotVerified         = errors.New("verify your email address to book this event")
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrInvalidRefreshToken      = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused       = errors.New("refresh token was already used, the session has been revoked")
+	ErrPaymentPending           = errors.New("a payment is already in progress")
+	ErrCancellationNotRetryable = errors.New("event cancellation has no failed bookings to retry")
+)
+
+// Machine-readable codes for errors clients are expected to handle.
+const (
+	CodeEventTicketLimit = "event_ticket_limit_reached"
+	CodeTicketTypeLimit  = "ticket_type_limit_reached"
+	CodeEmailNotVerified = "email_not_verified"
 )
@@ -1,9 +1,12 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,38 +15,100 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-const UserCtxKey = "user"
+const (
+	UserCtxKey    = "user"
+	SessionCtxKey = "session"
 
+	AccessTokenTTL  = time.Hour * 24
+	RefreshTokenTTL = time.Hour * 24 * 7
+
+	revocationTimeout = 2 * time.Second
+)
+
+// RevocationChecker tells when a user's tokens were last revoked, e.g. by a
+// password change. Tokens issued before that are rejected.
+type RevocationChecker interface {
+	TokensValidAfter(ctx context.Context, userID int64) (time.Time, error)
+}
+
+// SessionChecker tells whether the session a token belongs to is still
+// active. Tokens of revoked or expired sessions are rejected.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID int64) (bool, error)
+}
+
+// Token is what a verified token says about its holder.
+type Token struct {
+	User      *domain.User
+	SessionID int64
+	IssuedAt  time.Time
+}
+
+// Claims are the claims of access tokens.
+type Claims struct {
+	UserID    int64  `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID int64  `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Auth issues and checks access tokens. They are signed with the key set
+// when one is configured and with the shared secret otherwise. Refresh
+// tokens are opaque random values checked against their stored hash, so
+// they need no key.
 type Auth struct {
-	secret        string
-	refreshSecret string
+	secret      string
+	keys        *KeySet
+	revocations RevocationChecker
+	sessions    SessionChecker
 }
 
-func SetupAuth(secret, refreshSecret string) Auth {
+func SetupAuth(secret string) Auth {
 	return Auth{
-		secret:        secret,
-		refreshSecret: refreshSecret,
+		secret: secret,
 	}
 }
 
-func (a *Auth) GenerateAccessToken(id int64, email, role string) (string, error) {
-	duration := time.Hour * 24
-	return a.generateToken(id, email, role, duration, a.secret)
+// WithRevocationCheck returns a copy of a whose Authorize also rejects
+// tokens issued before the user's last revocation.
+func (a Auth) WithRevocationCheck(c RevocationChecker) Auth {
+	a.revocations = c
+	return a
 }
 
-func (a *Auth) GenerateRefreshToken(id int64, email, role string) (string, time.Time, error) {
-	duration := time.Hour * 24 * 7
-	exp := time.Now().Add(duration)
-	token, err := a.generateToken(id, email, role, duration, a.refreshSecret)
-	return token, exp, err
+// WithKeys returns a copy of a that signs access tokens with ks. A nil ks
+// keeps the shared secret.
+func (a Auth) WithKeys(ks *KeySet) Auth {
+	a.keys = ks
+	return a
 }
 
-func (a *Auth) VerifyAccessToken(token string) (*domain.User, error) {
-	return a.verifyToken(token, a.secret)
+// WithSessionCheck returns a copy of a whose Authorize also rejects tokens
+// of sessions that have ended.
+func (a Auth) WithSessionCheck(c SessionChecker) Auth {
+	a.sessions = c
+	return a
 }
 
-func (a *Auth) VerifyRefreshToken(token string) (*domain.User, error) {
-	return a.verifyToken(token, a.refreshSecret)
+func (a *Auth) GenerateAccessToken(id int64, email, role string, sessionID int64) (string, error) {
+	claims, err := newClaims(id, email, role, sessionID, AccessTokenTTL)
+	if err != nil {
+		return "", err
+	}
+
+	if a.keys != nil {
+		return a.keys.sign(claims)
+	}
+	return signHMAC(claims, a.secret)
+}
+
+func (a *Auth) VerifyAccessToken(token string) (*domain.User, error) {
+	t, err := a.verifyAccess(token)
+	if err != nil {
+		return nil, err
+	}
+	return t.User, nil
 }
 
 func (a *Auth) Authorize(ctx *fiber.Ctx) error {
@@ -55,9 +120,17 @@ func (a *Auth) Authorize(ctx *fiber.Ctx) error {
 		})
 	}
 
-	user, err := a.VerifyAccessToken(authHeader[0])
-	if err == nil && user.ID > 0 {
-		ctx.Locals(UserCtxKey, user)
+	token, err := a.verifyAccess(authHeader[0])
+	if err == nil && token.User.ID > 0 {
+		if err := a.checkRevoked(ctx.Context(), token); err != nil {
+			return ctx.Status(http.StatusUnauthorized).JSON(&fiber.Map{
+				"message": "authorization failed",
+				"error":   err.Error(),
+			})
+		}
+
+		ctx.Locals(UserCtxKey, token.User)
+		ctx.Locals(SessionCtxKey, token.SessionID)
 		return ctx.Next()
 	} else {
 		return ctx.Status(http.StatusUnauthorized).JSON(&fiber.Map{
@@ -67,25 +140,78 @@ func (a *Auth) Authorize(ctx *fiber.Ctx) error {
 	}
 }
 
+// checkRevoked fails when the token's session has ended or the token was
+// issued before the user's tokens were last revoked. Token times have
+// second precision, so the revocation time is truncated to let tokens
+// issued along with it through.
+func (a *Auth) checkRevoked(ctx context.Context, t *Token) error {
+	ctx, cancel := context.WithTimeout(ctx, revocationTimeout)
+	defer cancel()
+
+	if a.sessions != nil {
+		active, err := a.sessions.IsSessionActive(ctx, t.SessionID)
+		if err != nil {
+			return err
+		}
+		if !active {
+			return errors.New("session has ended")
+		}
+	}
+
+	if a.revocations != nil {
+		validAfter, err := a.revocations.TokensValidAfter(ctx, t.User.ID)
+		if err != nil {
+			return err
+		}
+		if t.IssuedAt.Before(validAfter.Truncate(time.Second)) {
+			return errors.New("token has been revoked")
+		}
+	}
+
+	return nil
+}
+
 func GetCurrentUser(ctx *fiber.Ctx) (*domain.User, bool) {
 	user, ok := ctx.Locals(UserCtxKey).(*domain.User)
 	return user, ok
 }
 
+// GetSessionID returns the session of the token the request was authorized
+// with.
+func GetSessionID(ctx *fiber.Ctx) (int64, bool) {
+	id, ok := ctx.Locals(SessionCtxKey).(int64)
+	return id, ok && id > 0
+}
+
 // ----- private -----
-func (a *Auth) generateToken(id int64, email, role string, duration time.Duration, key string) (string, error) {
+func newClaims(id int64, email, role string, sessionID int64, duration time.Duration) (*Claims, error) {
 	if id == 0 || email == "" {
-		return "", errors.New("required input are missing")
+		return nil, errors.New("required input are missing")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": id,
-		"email":   email,
-		"role":    role,
-		"exp":     time.Now().Add(duration).Unix(),
-	})
+	// jti keeps tokens issued within the same second distinct
+	jti, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
 
-	tokenStr, err := token.SignedString([]byte(key))
+	now := time.Now()
+	return &Claims{
+		UserID:    id,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(id, 10),
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+		},
+	}, nil
+}
+
+func signHMAC(claims *Claims, key string) (string, error) {
+	tokenStr, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
 	if err != nil {
 		return "", errors.New("signed token failed")
 	}
@@ -93,8 +219,32 @@ func (a *Auth) generateToken(id int64, email, role string, duration time.Duratio
 	return tokenStr, nil
 }
 
+func hmacKey(key string) jwt.Keyfunc {
+	return func(*jwt.Token) (any, error) {
+		return []byte(key), nil
+	}
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // Token : Verify
-func (a *Auth) verifyToken(t string, key string) (*domain.User, error) {
+func (a *Auth) verifyAccess(t string) (*Token, error) {
+	if a.keys != nil {
+		return verifyToken(t, a.keys.keyFunc, AlgRS256, AlgEdDSA)
+	}
+	return verifyToken(t, hmacKey(a.secret), jwt.SigningMethodHS256.Alg())
+}
+
+// verifyToken checks a "Bearer <token>" value signed with one of methods.
+// Tokens must carry an expiry; tokens without an iat claim count as issued
+// at the zero time, and tokens without a sid claim belong to no session.
+func verifyToken(t string, keyFunc jwt.Keyfunc, methods ...string) (*Token, error) {
 	tokenArr := strings.Split(t, " ")
 	if len(tokenArr) != 2 {
 		return nil, errors.New("invalid token format")
@@ -104,28 +254,34 @@ func (a *Auth) verifyToken(t string, key string) (*domain.User, error) {
 		return nil, errors.New("invalid token format")
 	}
 
-	token, err := jwt.Parse(tokenArr[1], func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unknow signing method: %v", t.Header)
-		}
-		return []byte(key), nil
-	})
+	var claims Claims
+	token, err := jwt.ParseWithClaims(
+		tokenArr[1],
+		&claims,
+		keyFunc,
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if float64(time.Now().Unix()) > claims["exp"].(float64) {
-			return nil, errors.New("token is expired")
-		}
-
-		user := domain.User{}
-		user.ID = int64(claims["user_id"].(float64))
-		user.Email = claims["email"].(string)
-		user.Role = claims["role"].(string)
+	if !token.Valid || claims.UserID <= 0 {
+		return nil, errors.New("token verification failed")
+	}
 
-		return &user, nil
+	res := &Token{
+		User: &domain.User{
+			ID:    claims.UserID,
+			Email: claims.Email,
+			Role:  claims.Role,
+		},
+		SessionID: claims.SessionID,
+	}
+	if claims.IssuedAt != nil {
+		res.IssuedAt = claims.IssuedAt.Time
 	}
 
-	return nil, errors.New("token verification failed")
+	return res, nil
 }
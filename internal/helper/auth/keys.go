@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	minRSABits = 2048
+
+	privateKeySuffix = ".pem"
+	publicKeySuffix  = ".pub.pem"
+)
+
+// Key is one asymmetric key. Keys without a private half can only verify,
+// e.g. a retired key whose tokens have not expired yet.
+type Key struct {
+	ID        string
+	Algorithm string
+
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	if k.Algorithm == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// KeySet holds the key access tokens are signed with and every key they
+// may be verified with, looked up by the token's kid header.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// LoadKeySet reads the keys in dir. A private key is stored as <kid>.pem
+// and a key that only verifies as <kid>.pub.pem, both PEM encoded RSA or
+// Ed25519 keys. signingID names the private key to sign with; it may be
+// empty when dir holds exactly one private key.
+//
+// To rotate, add the new key and let the JWKS pick it up, switch
+// signingID to it, and remove the old key (or keep only its .pub.pem) once
+// the tokens it signed have expired.
+func LoadKeySet(dir, signingID string) (*KeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read jwt keys: %w", err)
+	}
+
+	ks := &KeySet{keys: make(map[string]*Key)}
+	var privateIDs []string
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, privateKeySuffix) {
+			continue
+		}
+
+		public := strings.HasSuffix(name, publicKeySuffix)
+		id := strings.TrimSuffix(name, privateKeySuffix)
+		if public {
+			id = strings.TrimSuffix(name, publicKeySuffix)
+		}
+		if id == "" {
+			return nil, fmt.Errorf("jwt key %s has no key id", name)
+		}
+		if _, ok := ks.keys[id]; ok {
+			return nil, fmt.Errorf("duplicate jwt key id %q", id)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("read jwt key %s: %w", name, err)
+		}
+
+		key, err := parseKey(id, data, public)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", name, err)
+		}
+
+		ks.keys[id] = key
+		if key.private != nil {
+			privateIDs = append(privateIDs, id)
+		}
+	}
+
+	if signingID == "" {
+		if len(privateIDs) != 1 {
+			return nil, fmt.Errorf("found %d private jwt keys, set the signing key id", len(privateIDs))
+		}
+		signingID = privateIDs[0]
+	}
+
+	signing, ok := ks.keys[signingID]
+	if !ok || signing.private == nil {
+		return nil, fmt.Errorf("no private jwt key with id %q", signingID)
+	}
+	ks.signing = signing
+
+	return ks, nil
+}
+
+func parseKey(id string, data []byte, public bool) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data")
+	}
+
+	var raw any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		raw, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		raw, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		raw, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		raw, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: id}
+	switch k := raw.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.private, key.public = AlgRS256, k, &k.PublicKey
+	case ed25519.PrivateKey:
+		key.Algorithm, key.private, key.public = AlgEdDSA, k, k.Public()
+	case *rsa.PublicKey:
+		key.Algorithm, key.public = AlgRS256, k
+	case ed25519.PublicKey:
+		key.Algorithm, key.public = AlgEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", raw)
+	}
+
+	if public != (key.private == nil) {
+		return nil, errors.New("file name does not match the key: use .pem for private and .pub.pem for public keys")
+	}
+
+	if rk, ok := key.public.(*rsa.PublicKey); ok && rk.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("rsa key is %d bits, need at least %d", rk.N.BitLen(), minRSABits)
+	}
+
+	return key, nil
+}
+
+// sign signs claims with the signing key and names it in the kid header.
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.method(), claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.private)
+}
+
+// keyFunc finds the verification key named by the token's kid and checks
+// the token uses that key's algorithm.
+func (ks *KeySet) keyFunc(t *jwt.Token) (any, error) {
+	kid, ok := t.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("token has no key id")
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	if t.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("key %q is not for %s", kid, t.Method.Alg())
+	}
+
+	return key.public, nil
+}
+
+// verifyKeys returns every key, ordered by id so the JWKS is stable.
+func (ks *KeySet) verifyKeys() []*Key {
+	keys := make([]*Key, 0, len(ks.keys))
+	for _, k := range ks.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
@@ -0,0 +1,58 @@
+package security
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	MinPasswordLength = 8
+	// bcrypt only looks at the first 72 bytes
+	MaxPasswordLength = 72
+)
+
+// ErrWeakPassword is wrapped by every password policy failure.
+var ErrWeakPassword = errors.New("password does not meet the policy")
+
+//go:embed breached_passwords.txt
+var breachedList string
+
+var breached = parseBreached(breachedList)
+
+// CheckPasswordPolicy rejects passwords that are too short or too long,
+// appear in the breached password list, or match the account's email.
+func CheckPasswordPolicy(password, email string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("%w: use at least %d characters", ErrWeakPassword, MinPasswordLength)
+	}
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("%w: use at most %d bytes", ErrWeakPassword, MaxPasswordLength)
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := breached[lower]; ok {
+		return fmt.Errorf("%w: this password is known from data breaches", ErrWeakPassword)
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, _, _ := strings.Cut(email, "@")
+	if email != "" && (lower == email || lower == local) {
+		return fmt.Errorf("%w: do not use your email address", ErrWeakPassword)
+	}
+
+	return nil
+}
+
+func parseBreached(list string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,31 @@
+package notification
+
+import (
+	"context"
+	"log"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+const (
+	KindEventCancelled = "event_cancelled"
+	KindWaitlistOffer  = "waitlist_offer"
+)
+
+// Notifier delivers a notification to its user.
+type Notifier interface {
+	Send(ctx context.Context, n *domain.Notification) error
+}
+
+// LogNotifier writes notifications to the application log. It is meant for
+// local development where nothing should leave the machine.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (LogNotifier) Send(ctx context.Context, n *domain.Notification) error {
+	log.Printf("notify user %d <%s>: %s\n%s", n.UserID, n.Email, n.Subject, n.Body)
+	return nil
+}
@@ -0,0 +1,198 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const FakeProviderName = "fake"
+
+// FakeProvider is an in-process provider for development and tests. Intent
+// IDs are derived from the reference so the same input always gives the same
+// output, and webhooks are signed with HMAC-SHA256 like a real provider.
+type FakeProvider struct {
+	secret string
+
+	mu      sync.Mutex
+	intents map[string]*Intent
+	refunds int
+	// refundKeys maps idempotency keys to the refunds they made.
+	refundKeys map[string]*Refund
+}
+
+func NewFakeProvider(webhookSecret string) (*FakeProvider, error) {
+	if webhookSecret == "" {
+		return nil, errors.New("fake payment provider needs a webhook secret")
+	}
+
+	return &FakeProvider{
+		secret:     webhookSecret,
+		intents:    make(map[string]*Intent),
+		refundKeys: make(map[string]*Refund),
+	}, nil
+}
+
+func (p *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+func (p *FakeProvider) CreateIntent(ctx context.Context, amount float64, currency, reference string) (*Intent, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := "fake_pi_" + shortHash(fmt.Sprintf("%s:%.2f:%s", reference, amount, currency))
+	if intent, ok := p.intents[id]; ok {
+		return copyIntent(intent), nil
+	}
+
+	intent := &Intent{
+		ID:           id,
+		Reference:    reference,
+		Amount:       amount,
+		Currency:     currency,
+		Status:       IntentPending,
+		ClientSecret: id + "_secret",
+	}
+	p.intents[id] = intent
+
+	return copyIntent(intent), nil
+}
+
+// Capture is idempotent by itself: capturing a succeeded intent again is a
+// no-op, so the key is not recorded.
+func (p *FakeProvider) Capture(ctx context.Context, intentID, idempotencyKey string) (*Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+
+	switch intent.Status {
+	case IntentPending, IntentAuthorized:
+		intent.Status = IntentSucceeded
+	case IntentSucceeded:
+	default:
+		return nil, fmt.Errorf("cannot capture %s intent", intent.Status)
+	}
+
+	return copyIntent(intent), nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, intentID string, amount float64, idempotencyKey string) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if r, ok := p.refundKeys[idempotencyKey]; ok && idempotencyKey != "" {
+		c := *r
+		return &c, nil
+	}
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+
+	if intent.Status != IntentSucceeded {
+		return nil, fmt.Errorf("cannot refund %s intent", intent.Status)
+	}
+
+	if amount <= 0 || amount > intent.Amount {
+		return nil, ErrInvalidAmount
+	}
+
+	p.refunds++
+	if amount == intent.Amount {
+		intent.Status = IntentRefunded
+	}
+
+	r := &Refund{
+		ID:       fmt.Sprintf("fake_re_%s_%d", intentID, p.refunds),
+		IntentID: intentID,
+		Amount:   amount,
+	}
+	if idempotencyKey != "" {
+		p.refundKeys[idempotencyKey] = r
+	}
+
+	c := *r
+	return &c, nil
+}
+
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	if !hmac.Equal([]byte(p.Sign(payload)), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	return &event, nil
+}
+
+// Sign returns the webhook signature for payload.
+func (p *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Simulate moves an intent to the state of eventType and returns the signed
+// webhook the provider would send for it.
+func (p *FakeProvider) Simulate(intentID string, eventType EventType) ([]byte, string, error) {
+	p.mu.Lock()
+	intent, ok := p.intents[intentID]
+	if !ok {
+		p.mu.Unlock()
+		return nil, "", ErrIntentNotFound
+	}
+
+	switch eventType {
+	case EventAuthorized:
+		intent.Status = IntentAuthorized
+	case EventSucceeded:
+		intent.Status = IntentSucceeded
+	case EventFailed:
+		intent.Status = IntentFailed
+	default:
+		p.mu.Unlock()
+		return nil, "", fmt.Errorf("unknown event type: %s", eventType)
+	}
+	amount := intent.Amount
+	p.mu.Unlock()
+
+	payload, err := json.Marshal(&Event{
+		ID:       "fake_evt_" + shortHash(intentID+string(eventType)),
+		Type:     eventType,
+		IntentID: intentID,
+		Amount:   amount,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, p.Sign(payload), nil
+}
+
+func copyIntent(i *Intent) *Intent {
+	c := *i
+	return &c
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
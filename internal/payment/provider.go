@@ -0,0 +1,86 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type IntentStatus string
+
+const (
+	IntentPending    IntentStatus = "pending"
+	IntentAuthorized IntentStatus = "authorized"
+	IntentSucceeded  IntentStatus = "succeeded"
+	IntentFailed     IntentStatus = "failed"
+	IntentRefunded   IntentStatus = "refunded"
+)
+
+type EventType string
+
+const (
+	EventAuthorized EventType = "payment.authorized"
+	EventSucceeded  EventType = "payment.succeeded"
+	EventFailed     EventType = "payment.failed"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrIntentNotFound   = errors.New("payment intent not found")
+	ErrInvalidAmount    = errors.New("invalid payment amount")
+)
+
+// Intent is a provider-side request to collect an amount.
+type Intent struct {
+	ID           string       `json:"id"`
+	Reference    string       `json:"reference"`
+	Amount       float64      `json:"amount"`
+	Currency     string       `json:"currency"`
+	Status       IntentStatus `json:"status"`
+	ClientSecret string       `json:"client_secret"`
+}
+
+type Refund struct {
+	ID       string  `json:"id"`
+	IntentID string  `json:"intent_id"`
+	Amount   float64 `json:"amount"`
+}
+
+// Event is a verified webhook notification from a provider.
+type Event struct {
+	ID       string    `json:"id"`
+	Type     EventType `json:"type"`
+	IntentID string    `json:"intent_id"`
+	Amount   float64   `json:"amount"`
+}
+
+// Provider collects and returns money through an external payment service.
+// Capture and Refund take an idempotency key: repeating a call with the same
+// key returns the first result instead of moving money again.
+type Provider interface {
+	Name() string
+	CreateIntent(ctx context.Context, amount float64, currency, reference string) (*Intent, error)
+	Capture(ctx context.Context, intentID, idempotencyKey string) (*Intent, error)
+	Refund(ctx context.Context, intentID string, amount float64, idempotencyKey string) (*Refund, error)
+	VerifyWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// NewProvider returns the provider registered under name. There is no
+// default, so a missing setting cannot silently enable the fake provider,
+// and the fake is refused unless allowFake is set for development or tests:
+// it keeps intents in memory, so a restart loses every open payment.
+func NewProvider(name, webhookSecret string, allowFake bool) (Provider, error) {
+	switch name {
+	case FakeProviderName:
+		if !allowFake {
+			return nil, fmt.Errorf("payment provider %s is only allowed in development and test", name)
+		}
+		fake, err := NewFakeProvider(webhookSecret)
+		if err != nil {
+			return nil, err
+		}
+		return fake, nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider: %s", name)
+	}
+}
@@ -0,0 +1,98 @@
+// Package promotion works out what promo codes take off a ticket price. It
+// works on plain values only, so it can be used and tested without a
+// database.
+package promotion
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+type DiscountType string
+
+const (
+	// Percent takes Amount percent off the price.
+	Percent DiscountType = "percent"
+	// Fixed takes Amount off the price.
+	Fixed DiscountType = "fixed"
+)
+
+var ErrNotStackable = errors.New("promo code cannot be combined with other codes")
+
+type Discount struct {
+	ID        int64
+	Type      DiscountType
+	Amount    float64
+	Stackable bool
+}
+
+// Line is what one discount took off.
+type Line struct {
+	ID     int64
+	Amount float64
+}
+
+type Result struct {
+	Price    float64
+	Discount float64
+	Lines    []Line
+}
+
+// CheckStacking allows a single discount of any kind, or several that are
+// all stackable.
+func CheckStacking(discounts []Discount) error {
+	if len(discounts) < 2 {
+		return nil
+	}
+	for _, d := range discounts {
+		if !d.Stackable {
+			return ErrNotStackable
+		}
+	}
+	return nil
+}
+
+// Apply discounts price. Percentages go first, each on the price left by
+// the one before, then fixed amounts; the price never drops below zero.
+// Amounts are rounded to cents.
+func Apply(price float64, discounts []Discount) (Result, error) {
+	if err := CheckStacking(discounts); err != nil {
+		return Result{}, err
+	}
+
+	ordered := make([]Discount, len(discounts))
+	copy(ordered, discounts)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Type == Percent && ordered[j].Type != Percent
+	})
+
+	res := Result{Price: round(price)}
+	for _, d := range ordered {
+		var off float64
+		switch d.Type {
+		case Percent:
+			off = round(res.Price * math.Min(d.Amount, 100) / 100)
+		case Fixed:
+			off = round(d.Amount)
+		}
+		off = math.Min(off, res.Price)
+
+		res.Price = round(res.Price - off)
+		res.Discount = round(res.Discount + off)
+		res.Lines = append(res.Lines, Line{ID: d.ID, Amount: off})
+	}
+
+	return res, nil
+}
+
+// NormalizeCode trims a code and upper-cases it, which is how codes are
+// stored.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
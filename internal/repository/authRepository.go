@@ -3,13 +3,29 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
 )
 
 type AuthRepository interface {
-	SaveRefreshToken(ctx context.Context, userID int64, token string, expiresAt time.Time) error
-	DeleteRefreshToken(ctx context.Context, userId int64) error
-	IsRefreshTokenValid(ctx context.Context, token string) (bool, error)
+	// Sessions
+	CreateSession(ctx context.Context, s *domain.Session) error
+	GetSessionForUpdate(ctx context.Context, id int64) (*domain.Session, error)
+	ListActiveSessions(ctx context.Context, userID int64) ([]*domain.Session, error)
+	TouchSession(ctx context.Context, s *domain.Session) error
+	RevokeSession(ctx context.Context, userID, sessionID int64) error
+	RevokeOtherSessions(ctx context.Context, userID, keepID int64) error
+	RevokeUserSessions(ctx context.Context, userID int64) error
+	IsSessionActive(ctx context.Context, id int64) (bool, error)
+
+	// Refresh tokens
+	SaveRefreshToken(ctx context.Context, sessionID int64, tokenHash string, expiresAt time.Time) error
+	GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id int64) error
 }
 
 type authRepository struct {
@@ -20,33 +36,83 @@ func NewAuthRepository(db *sql.DB) AuthRepository {
 	return &authRepository{db: db}
 }
 
-func (r *authRepository) SaveRefreshToken(ctx context.Context, userID int64, token string, expiresAt time.Time) error {
+const sessionColumns = `id, user_id, device_name, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at`
+
+func (r *authRepository) CreateSession(ctx context.Context, s *domain.Session) error {
 	query := `
-		INSERT INTO refresh_tokens (user_id, token, expires_at)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (user_id) DO UPDATE
-		SET token = $2, expires_at = $3
+		INSERT INTO sessions (user_id, device_name, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, last_used_at
 	`
-	res, err := r.db.ExecContext(ctx, query, userID, token, expiresAt)
-	if err != nil {
-		return err
-	}
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		s.UserID,
+		s.DeviceName,
+		s.UserAgent,
+		s.IPAddress,
+		s.ExpiresAt,
+	).Scan(&s.ID, &s.CreatedAt, &s.LastUsedAt)
+}
 
-	rows, err := res.RowsAffected()
+func (r *authRepository) GetSessionForUpdate(ctx context.Context, id int64) (*domain.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1 FOR UPDATE`
+	return scanSession(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan)
+}
+
+// ListActiveSessions returns the user's sessions that are neither revoked
+// nor expired, most recently used first.
+func (r *authRepository) ListActiveSessions(ctx context.Context, userID int64) ([]*domain.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + ` FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	if rows == 0 {
-		return err
+	var sessions []*domain.Session
+	for rows.Next() {
+		s, err := scanSession(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
 	}
 
-	return nil
+	return sessions, rows.Err()
 }
 
-func (r *authRepository) DeleteRefreshToken(ctx context.Context, userID int64) error {
-	query := `DELETE FROM refresh_tokens WHERE user_id = $1`
-	res, err := r.db.ExecContext(ctx, query, userID)
+// TouchSession stores when and from where the session was last used and
+// how long it now lasts.
+func (r *authRepository) TouchSession(ctx context.Context, s *domain.Session) error {
+	query := `
+		UPDATE sessions SET user_agent = $1, ip_address = $2, last_used_at = $3, expires_at = $4
+		WHERE id = $5
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		s.UserAgent,
+		s.IPAddress,
+		s.LastUsedAt,
+		s.ExpiresAt,
+		s.ID,
+	)
+	return err
+}
+
+// RevokeSession ends one active session of the user. It returns
+// errs.ErrSessionNotFound if there is none with that id.
+func (r *authRepository) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, sessionID, userID)
 	if err != nil {
 		return err
 	}
@@ -57,20 +123,97 @@ func (r *authRepository) DeleteRefreshToken(ctx context.Context, userID int64) e
 	}
 
 	if rows == 0 {
-		return err
+		return errs.ErrSessionNotFound
 	}
 
 	return nil
 }
 
-func (r *authRepository) IsRefreshTokenValid(ctx context.Context, token string) (bool, error) {
-	query := `SELECT COUNT(*) FROM refresh_tokens WHERE token = $1 AND expires_at > NOW()`
+func (r *authRepository) RevokeOtherSessions(ctx context.Context, userID, keepID int64) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, userID, keepID)
+	return err
+}
 
-	var count int
-	err := r.db.QueryRowContext(ctx, query, token).Scan(&count)
-	if err != nil {
+func (r *authRepository) RevokeUserSessions(ctx context.Context, userID int64) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, userID)
+	return err
+}
+
+func (r *authRepository) IsSessionActive(ctx context.Context, id int64) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW())`
+
+	var active bool
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&active); err != nil {
 		return false, err
 	}
 
-	return count > 0, nil
+	return active, nil
+}
+
+func (r *authRepository) SaveRefreshToken(ctx context.Context, sessionID int64, tokenHash string, expiresAt time.Time) error {
+	query := `INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, sessionID, tokenHash, expiresAt)
+	return err
+}
+
+// GetRefreshTokenForUpdate locks the refresh token with the given hash,
+// used or not, so reuse of a rotated token can be detected. It returns
+// errs.ErrInvalidRefreshToken for unknown or expired tokens.
+func (r *authRepository) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, token_hash, expires_at, used_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1 AND expires_at > NOW()
+		FOR UPDATE
+	`
+	var t domain.RefreshToken
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
+		&t.ID,
+		&t.SessionID,
+		&t.TokenHash,
+		&t.ExpiresAt,
+		&t.UsedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *authRepository) MarkRefreshTokenUsed(ctx context.Context, id int64) error {
+	query := `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
+
+func scanSession(scan func(dest ...any) error) (*domain.Session, error) {
+	var s domain.Session
+
+	err := scan(
+		&s.ID,
+		&s.UserID,
+		&s.DeviceName,
+		&s.UserAgent,
+		&s.IPAddress,
+		&s.CreatedAt,
+		&s.LastUsedAt,
+		&s.ExpiresAt,
+		&s.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &s, nil
 }
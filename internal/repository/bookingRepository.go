@@ -5,27 +5,43 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/domain"
 	"github.com/codepnw/go-ticket-booking/internal/dto"
 	"github.com/codepnw/go-ticket-booking/internal/errs"
 )
 
 type BookingRepository interface {
-	Create(ctx context.Context, tx *sql.Tx, b *domain.Booking) error
+	Create(ctx context.Context, b *domain.Booking) error
 	GetByID(ctx context.Context, id int64) (*dto.BookingResponse, error)
 	ListByUserID(ctx context.Context, userID int64) ([]*dto.BookingResponse, error)
 	ListByEventID(ctx context.Context, eventID int64) ([]*dto.BookingResponse, error)
 	ListByStatus(ctx context.Context, status string) ([]*dto.BookingResponse, error)
-	UpdateSeat(ctx context.Context, tx *sql.Tx, bookingID, seatID int64) error
-	GetForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*domain.Booking, error)
-	IsSeatConfirmed(ctx context.Context, tx *sql.Tx, seatID int64) (bool, error)
-	Confirm(ctx context.Context, tx *sql.Tx, bookingID int64) error
-	Cancel(ctx context.Context, tx *sql.Tx, bookingID int64) error
-	CancelOtherBooking(ctx context.Context, tx *sql.Tx, seatID, bookingID int64) error
+	ListByOrderID(ctx context.Context, orderID int64) ([]*dto.BookingResponse, error)
+	ListForUpdateByOrder(ctx context.Context, orderID int64) ([]*domain.Booking, error)
+	ListActiveByEvent(ctx context.Context, eventID, afterID int64, limit int) ([]*domain.Booking, error)
+	UpdateSeat(ctx context.Context, bookingID, seatID int64) error
+	GetForUpdate(ctx context.Context, id int64) (*domain.Booking, error)
+	IsSeatConfirmed(ctx context.Context, seatID int64) (bool, error)
+	Confirm(ctx context.Context, bookingID int64) error
+	Cancel(ctx context.Context, bookingID int64) error
+	Refund(ctx context.Context, bookingID int64) error
+	CancelOtherBooking(ctx context.Context, seatID, bookingID int64) error
+	ConfirmByOrder(ctx context.Context, orderID int64) error
+	CancelByOrder(ctx context.Context, orderID int64) error
 	IsAvailable(ctx context.Context, seatID int64) (bool, error)
+	LockUserEvent(ctx context.Context, userID, eventID int64) error
+	CountActiveByUser(ctx context.Context, userID, eventID int64, ticketTypeID *int64) (int, int, error)
+	ExpireHolds(ctx context.Context) (int64, error)
+	ExpireSeatHold(ctx context.Context, seatID int64) error
+	ExpireHold(ctx context.Context, bookingID int64) error
+	ExtendHold(ctx context.Context, bookingID int64, expiresAt time.Time) error
 }
 
+const uniqueActiveSeatBooking = "unique_active_seat_booking"
+
 type bookingRepository struct {
 	db *sql.DB
 }
@@ -34,31 +50,44 @@ func NewBookingRepository(db *sql.DB) BookingRepository {
 	return &bookingRepository{db: db}
 }
 
-func (r *bookingRepository) Create(ctx context.Context, tx *sql.Tx, b *domain.Booking) error {
+func (r *bookingRepository) Create(ctx context.Context, b *domain.Booking) error {
 	query := `
-		INSERT INTO bookings (user_id, event_id, seat_id, status)
-		VALUES ($1, $2, $3, $4) RETURNING id
+		WITH created AS (
+			INSERT INTO bookings (user_id, event_id, seat_id, order_id, ticket_type_id, price, discount, status, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, event_id, seat_id, status
+		),
+		` + recordSeatChanges("created", seatHeldOrConfirmed) + `
+		SELECT id FROM created
 	`
-	err := tx.QueryRowContext(
+	err := database.Conn(ctx, r.db).QueryRowContext(
 		ctx,
 		query,
 		&b.UserID,
 		&b.EventID,
 		&b.SeatID,
+		&b.OrderID,
+		&b.TicketTypeID,
+		&b.Price,
+		&b.Discount,
 		&b.Status,
+		&b.ExpiresAt,
 	).Scan(&b.ID)
 
+	if database.IsUniqueViolation(err, uniqueActiveSeatBooking) {
+		return errs.ErrSeatAlreadyBooked
+	}
 	return err
 }
 
 var selectQuery = `
-	SELECT b.id, b.user_id, u.first_name, u.last_name, u.email, b.event_id, e.name, b.seat_id,
-			s.row_label, s.seat_number, b.status,
-			b.created_at, b.confirmed_at, b.cancelled_at
+	SELECT b.id, b.order_id, b.user_id, u.first_name, u.last_name, u.email, b.event_id, e.name, b.seat_id,
+			s.row_label, s.seat_number, b.ticket_type_id, tt.name, b.price, b.discount, b.status,
+			b.created_at, b.confirmed_at, b.cancelled_at, b.expires_at
 	FROM bookings b
 	JOIN events e ON b.event_id = e.id
 	JOIN seats s ON b.seat_id = s.id
 	JOIN users u ON b.user_id = u.id
+	LEFT JOIN ticket_types tt ON b.ticket_type_id = tt.id
 	WHERE b.
 `
 
@@ -66,8 +95,9 @@ func (r *bookingRepository) GetByID(ctx context.Context, id int64) (*dto.Booking
 	var res dto.BookingResponse
 
 	query := selectQuery + "id = $1"
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&res.ID,
+		&res.OrderID,
 		&res.User.UserID,
 		&res.User.FirstName,
 		&res.User.LastName,
@@ -77,10 +107,15 @@ func (r *bookingRepository) GetByID(ctx context.Context, id int64) (*dto.Booking
 		&res.Seat.SeatID,
 		&res.Seat.RowLabel,
 		&res.Seat.SeatNumber,
+		&res.TicketTypeID,
+		&res.TicketTypeName,
+		&res.Price,
+		&res.Discount,
 		&res.Status,
 		&res.CreatedAt,
 		&res.ConfirmedAt,
 		&res.CancelledAt,
+		&res.ExpiresAt,
 	)
 	if err != nil {
 		return nil, err
@@ -116,18 +151,116 @@ func (r *bookingRepository) ListByStatus(ctx context.Context, status string) ([]
 	return bookings, nil
 }
 
-func (r *bookingRepository) GetForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*domain.Booking, error) {
+func (r *bookingRepository) ListByOrderID(ctx context.Context, orderID int64) ([]*dto.BookingResponse, error) {
+	bookings, err := r.listBookings(ctx, "order_id", orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func (r *bookingRepository) ListForUpdateByOrder(ctx context.Context, orderID int64) ([]*domain.Booking, error) {
+	query := `
+		SELECT id, user_id, event_id, seat_id, order_id, ticket_type_id, price, status, expires_at
+		FROM bookings
+		WHERE order_id = $1 ORDER BY id FOR UPDATE
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*domain.Booking
+
+	for rows.Next() {
+		var b domain.Booking
+		err := rows.Scan(
+			&b.ID,
+			&b.UserID,
+			&b.EventID,
+			&b.SeatID,
+			&b.OrderID,
+			&b.TicketTypeID,
+			&b.Price,
+			&b.Status,
+			&b.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, &b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// ListActiveByEvent pages through an event's pending and confirmed bookings
+// in id order, starting after afterID.
+func (r *bookingRepository) ListActiveByEvent(ctx context.Context, eventID, afterID int64, limit int) ([]*domain.Booking, error) {
+	query := `
+		SELECT id, user_id, event_id, seat_id, order_id, ticket_type_id, price, status, expires_at
+		FROM bookings
+		WHERE event_id = $1 AND id > $2 AND status IN ('pending', 'confirmed')
+		ORDER BY id
+		LIMIT $3
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, eventID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*domain.Booking
+
+	for rows.Next() {
+		var b domain.Booking
+		err := rows.Scan(
+			&b.ID,
+			&b.UserID,
+			&b.EventID,
+			&b.SeatID,
+			&b.OrderID,
+			&b.TicketTypeID,
+			&b.Price,
+			&b.Status,
+			&b.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, &b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func (r *bookingRepository) GetForUpdate(ctx context.Context, id int64) (*domain.Booking, error) {
 	query := `
-		SELECT id, user_id, event_id, seat_id, status FROM bookings
+		SELECT id, user_id, event_id, seat_id, order_id, ticket_type_id, price, status, expires_at
+		FROM bookings
 		WHERE id = $1 FOR UPDATE
 	`
 	var b domain.Booking
-	err := tx.QueryRowContext(ctx, query, id).Scan(
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&b.ID,
 		&b.UserID,
 		&b.EventID,
 		&b.SeatID,
+		&b.OrderID,
+		&b.TicketTypeID,
+		&b.Price,
 		&b.Status,
+		&b.ExpiresAt,
 	)
 	if err != nil {
 		return nil, err
@@ -136,14 +269,14 @@ func (r *bookingRepository) GetForUpdate(ctx context.Context, tx *sql.Tx, id int
 	return &b, nil
 }
 
-func (r *bookingRepository) IsSeatConfirmed(ctx context.Context, tx *sql.Tx, seatID int64) (bool, error) {
+func (r *bookingRepository) IsSeatConfirmed(ctx context.Context, seatID int64) (bool, error) {
 	query := `
 		SELECT COUNT(*) 
 		FROM bookings 
 		WHERE seat_id = $1 AND status = 'confirmed'
 	`
 	var count int
-	err := tx.QueryRowContext(ctx, query, seatID).Scan(&count)
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, seatID).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -151,17 +284,12 @@ func (r *bookingRepository) IsSeatConfirmed(ctx context.Context, tx *sql.Tx, sea
 	return count > 0, nil
 }
 
-func (r *bookingRepository) Confirm(ctx context.Context, tx *sql.Tx, bookingID int64) error {
+func (r *bookingRepository) Confirm(ctx context.Context, bookingID int64) error {
 	query := `
 		UPDATE bookings SET status = 'confirmed', confirmed_at = NOW()
-		WHERE id = $1 AND status = 'pending'
+		WHERE id = $1 AND status = 'pending' AND (expires_at IS NULL OR expires_at > NOW())
 	`
-	res, err := tx.ExecContext(ctx, query, bookingID)
-	if err != nil {
-		return err
-	}
-
-	rows, err := res.RowsAffected()
+	rows, err := r.confirmBookings(ctx, query, bookingID)
 	if err != nil {
 		return err
 	}
@@ -173,17 +301,12 @@ func (r *bookingRepository) Confirm(ctx context.Context, tx *sql.Tx, bookingID i
 	return nil
 }
 
-func (r *bookingRepository) Cancel(ctx context.Context, tx *sql.Tx, bookingID int64) error {
+func (r *bookingRepository) Cancel(ctx context.Context, bookingID int64) error {
 	query := `
 		UPDATE bookings SET status = 'cancelled', cancelled_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND status IN ('pending', 'confirmed')
 	`
-	res, err := tx.ExecContext(ctx, query, bookingID)
-	if err != nil {
-		return err
-	}
-
-	rows, err := res.RowsAffected()
+	rows, err := r.releaseBookings(ctx, query, bookingID)
 	if err != nil {
 		return err
 	}
@@ -195,18 +318,54 @@ func (r *bookingRepository) Cancel(ctx context.Context, tx *sql.Tx, bookingID in
 	return nil
 }
 
-func (r *bookingRepository) UpdateSeat(ctx context.Context, tx *sql.Tx, bookingID, seatID int64) error {
+// Refund marks a confirmed booking refunded and puts its seat back on sale.
+func (r *bookingRepository) Refund(ctx context.Context, bookingID int64) error {
 	query := `
-		UPDATE bookings SET seat_id = $1, updated_at = NOW() 
-		WHERE id = $2
+		UPDATE bookings SET status = 'refunded', cancelled_at = NOW()
+		WHERE id = $1 AND status = 'confirmed'
 	`
-	res, err := tx.ExecContext(ctx, query, seatID, bookingID)
+	rows, err := r.releaseBookings(ctx, query, bookingID)
 	if err != nil {
 		return err
 	}
 
-	rows, err := res.RowsAffected()
+	if rows == 0 {
+		return errs.ErrBookingNotConfirmed
+	}
+
+	return nil
+}
+
+func (r *bookingRepository) UpdateSeat(ctx context.Context, bookingID, seatID int64) error {
+	// the old seat is released and the new one taken over in the same state
+	query := `
+		WITH previous AS (
+			SELECT id, seat_id FROM bookings WHERE id = $2
+		),
+		moved AS (
+			UPDATE bookings SET seat_id = $1, updated_at = NOW()
+			WHERE id = $2
+			RETURNING id, event_id, seat_id, status
+		),
+		active AS (
+			SELECT m.id, m.event_id, m.seat_id, m.status, p.seat_id AS previous_seat_id
+			FROM moved m JOIN previous p ON p.id = m.id
+			WHERE m.status IN ('pending', 'confirmed') AND m.seat_id <> p.seat_id
+		),
+		changes AS (
+			INSERT INTO seat_changes (event_id, seat_id, booking_id, state)
+			SELECT event_id, previous_seat_id, id, 'released' FROM active
+			UNION ALL
+			SELECT event_id, seat_id, id, ` + seatHeldOrConfirmed + ` FROM active
+		)
+		SELECT COUNT(*) FROM moved
+	`
+	var rows int64
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, seatID, bookingID).Scan(&rows)
 	if err != nil {
+		if database.IsUniqueViolation(err, uniqueActiveSeatBooking) {
+			return errs.ErrSeatAlreadyBooked
+		}
 		return err
 	}
 
@@ -217,13 +376,31 @@ func (r *bookingRepository) UpdateSeat(ctx context.Context, tx *sql.Tx, bookingI
 	return nil
 }
 
-func (r *bookingRepository) CancelOtherBooking(ctx context.Context, tx *sql.Tx, seatID, bookingID int64) error {
+func (r *bookingRepository) CancelOtherBooking(ctx context.Context, seatID, bookingID int64) error {
 	query := `
 		UPDATE bookings 
 		SET status = 'cancelled', cancelled_at = NOW() 
 		WHERE seat_id = $1 AND status = 'pending' AND id != $2
 	`
-	_, err := tx.ExecContext(ctx, query, seatID, bookingID)
+	_, err := r.releaseBookings(ctx, query, seatID, bookingID)
+	return err
+}
+
+func (r *bookingRepository) ConfirmByOrder(ctx context.Context, orderID int64) error {
+	query := `
+		UPDATE bookings SET status = 'confirmed', confirmed_at = NOW()
+		WHERE order_id = $1 AND status = 'pending'
+	`
+	_, err := r.confirmBookings(ctx, query, orderID)
+	return err
+}
+
+func (r *bookingRepository) CancelByOrder(ctx context.Context, orderID int64) error {
+	query := `
+		UPDATE bookings SET status = 'cancelled', cancelled_at = NOW()
+		WHERE order_id = $1 AND status IN ('pending', 'confirmed')
+	`
+	_, err := r.releaseBookings(ctx, query, orderID)
 	return err
 }
 
@@ -232,19 +409,153 @@ func (r *bookingRepository) IsAvailable(ctx context.Context, seatID int64) (bool
 
 	query := `
 		SELECT COUNT(*) FROM bookings
-		WHERE seat_id = $1 AND status = 'confirmed'
+		WHERE seat_id = $1
+		AND (status = 'confirmed' OR (status = 'pending' AND expires_at > NOW()))
 	`
-	err := r.db.QueryRowContext(ctx, query, seatID).Scan(&count)
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, seatID).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check available: %w", err)
 	}
 	return count == 0, nil
 }
 
+// LockUserEvent serializes purchases by one user for one event until the
+// transaction ends, so concurrent requests cannot both pass a limit check.
+func (r *bookingRepository) LockUserEvent(ctx context.Context, userID, eventID int64) error {
+	query := `SELECT pg_advisory_xact_lock(hashtextextended('purchase:' || $1::text || ':' || $2::text, 0))`
+	if _, err := database.Conn(ctx, r.db).ExecContext(ctx, query, userID, eventID); err != nil {
+		return fmt.Errorf("failed to lock user purchases: %w", err)
+	}
+	return nil
+}
+
+// CountActiveByUser counts the user's confirmed bookings and unexpired holds
+// for the event, and of those the ones for ticketTypeID when it is set.
+func (r *bookingRepository) CountActiveByUser(ctx context.Context, userID, eventID int64, ticketTypeID *int64) (int, int, error) {
+	var event, ticketType int
+
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE ticket_type_id = $3)
+		FROM bookings
+		WHERE user_id = $1 AND event_id = $2
+		AND (status = 'confirmed' OR (status = 'pending' AND expires_at > NOW()))
+	`
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, userID, eventID, ticketTypeID).Scan(&event, &ticketType)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count user bookings: %w", err)
+	}
+	return event, ticketType, nil
+}
+
+func (r *bookingRepository) ExpireHolds(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE bookings SET status = 'expired', updated_at = NOW()
+		WHERE status = 'pending' AND expires_at <= NOW()
+	`
+	rows, err := r.releaseBookings(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire holds: %w", err)
+	}
+
+	return rows, nil
+}
+
+// ExpireSeatHold expires a lapsed pending hold on the seat that the sweeper
+// has not picked up yet, so it no longer occupies the active booking slot.
+func (r *bookingRepository) ExpireSeatHold(ctx context.Context, seatID int64) error {
+	query := `
+		UPDATE bookings SET status = 'expired', updated_at = NOW()
+		WHERE seat_id = $1 AND status = 'pending' AND expires_at <= NOW()
+	`
+	_, err := r.releaseBookings(ctx, query, seatID)
+	return err
+}
+
+// ExpireHold ends a single pending hold early.
+func (r *bookingRepository) ExpireHold(ctx context.Context, bookingID int64) error {
+	query := `
+		UPDATE bookings SET status = 'expired'
+		WHERE id = $1 AND status = 'pending'
+	`
+	_, err := r.releaseBookings(ctx, query, bookingID)
+	return err
+}
+
+func (r *bookingRepository) ExtendHold(ctx context.Context, bookingID int64, expiresAt time.Time) error {
+	query := `
+		UPDATE bookings SET expires_at = $1
+		WHERE id = $2 AND status = 'pending' AND expires_at > NOW()
+	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, expiresAt, bookingID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrBookingHoldExpired
+	}
+
+	return nil
+}
+
+// seatHeldOrConfirmed is the seat change state matching a booking status.
+const seatHeldOrConfirmed = `CASE status WHEN 'confirmed' THEN 'confirmed' ELSE 'held' END`
+
+// recordSeatChanges is a CTE logging a seat change for every booking returned
+// by the CTE named from, which must return id, event_id and seat_id. The
+// changes commit with the booking update that caused them.
+func recordSeatChanges(from, state string) string {
+	return `changes AS (
+			INSERT INTO seat_changes (event_id, seat_id, booking_id, state)
+			SELECT event_id, seat_id, id, ` + state + ` FROM ` + from + `
+		)`
+}
+
+// confirmBookings runs an UPDATE that confirms pending bookings and records
+// their seats as confirmed. It returns the bookings confirmed.
+func (r *bookingRepository) confirmBookings(ctx context.Context, update string, args ...any) (int64, error) {
+	query := `
+		WITH confirmed AS (` + update + ` RETURNING id, event_id, seat_id),
+		` + recordSeatChanges("confirmed", "'confirmed'") + `
+		SELECT COUNT(*) FROM confirmed
+	`
+	var rows int64
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&rows)
+	return rows, err
+}
+
+// releaseBookings runs an UPDATE that moves bookings out of an active status
+// and puts their ticket type inventory back. It returns the bookings released.
+func (r *bookingRepository) releaseBookings(ctx context.Context, update string, args ...any) (int64, error) {
+	query := `
+		WITH released AS (` + update + ` RETURNING id, event_id, seat_id, ticket_type_id),
+		` + recordSeatChanges("released", "'released'") + `,
+		restock AS (
+			UPDATE ticket_types t SET quantity = t.quantity + r.n, updated_at = NOW()
+			FROM (
+				SELECT ticket_type_id, COUNT(*) AS n FROM released
+				WHERE ticket_type_id IS NOT NULL GROUP BY ticket_type_id
+			) r
+			WHERE t.id = r.ticket_type_id
+		)
+		SELECT COUNT(*) FROM released
+	`
+	var rows int64
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&rows)
+	return rows, err
+}
+
 func (r *bookingRepository) listBookings(ctx context.Context, where string, data any) ([]*dto.BookingResponse, error) {
 	query := fmt.Sprintf("%s%s%s", selectQuery, where, "=$1")
 
-	rows, err := r.db.QueryContext(ctx, query, data)
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, data)
 	if err != nil {
 		return nil, err
 	}
@@ -256,6 +567,7 @@ func (r *bookingRepository) listBookings(ctx context.Context, where string, data
 		var res dto.BookingResponse
 		err := rows.Scan(
 			&res.ID,
+			&res.OrderID,
 			&res.User.UserID,
 			&res.User.FirstName,
 			&res.User.LastName,
@@ -265,10 +577,15 @@ func (r *bookingRepository) listBookings(ctx context.Context, where string, data
 			&res.Seat.SeatID,
 			&res.Seat.RowLabel,
 			&res.Seat.SeatNumber,
+			&res.TicketTypeID,
+			&res.TicketTypeName,
+			&res.Price,
+			&res.Discount,
 			&res.Status,
 			&res.CreatedAt,
 			&res.ConfirmedAt,
 			&res.CancelledAt,
+			&res.ExpiresAt,
 		)
 		if err != nil {
 			return nil, err
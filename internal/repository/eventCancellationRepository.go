@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+)
+
+type EventCancellationRepository interface {
+	Create(ctx context.Context, c *domain.EventCancellation) error
+	GetByEvent(ctx context.Context, eventID int64) (*domain.EventCancellation, error)
+	ListRunning(ctx context.Context) ([]*domain.EventCancellation, error)
+	SaveProgress(ctx context.Context, c *domain.EventCancellation) error
+	Finish(ctx context.Context, id int64, status string) error
+	Retry(ctx context.Context, eventID int64) (*domain.EventCancellation, error)
+}
+
+type eventCancellationRepository struct {
+	db *sql.DB
+}
+
+func NewEventCancellationRepository(db *sql.DB) EventCancellationRepository {
+	return &eventCancellationRepository{db: db}
+}
+
+func (r *eventCancellationRepository) Create(ctx context.Context, c *domain.EventCancellation) error {
+	query := `
+		INSERT INTO event_cancellations (event_id, reason, requested_by)
+		VALUES ($1, $2, $3) RETURNING id, status, created_at
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		c.EventID,
+		c.Reason,
+		c.RequestedBy,
+	).Scan(&c.ID, &c.Status, &c.CreatedAt)
+}
+
+const selectCancellationQuery = `
+	SELECT id, event_id, COALESCE(reason, ''), requested_by, status, last_booking_id,
+		cancelled_count, refunded_count, failed_count, last_error, created_at, updated_at, completed_at
+	FROM event_cancellations
+`
+
+func (r *eventCancellationRepository) GetByEvent(ctx context.Context, eventID int64) (*domain.EventCancellation, error) {
+	query := selectCancellationQuery + "WHERE event_id = $1"
+
+	c, err := scanCancellation(database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrCancellationNotFound
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r *eventCancellationRepository) ListRunning(ctx context.Context) ([]*domain.EventCancellation, error) {
+	query := selectCancellationQuery + "WHERE status = 'running' ORDER BY id"
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*domain.EventCancellation
+
+	for rows.Next() {
+		c, err := scanCancellation(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *eventCancellationRepository) SaveProgress(ctx context.Context, c *domain.EventCancellation) error {
+	query := `
+		UPDATE event_cancellations SET
+			last_booking_id = $1, cancelled_count = $2, refunded_count = $3,
+			failed_count = $4, last_error = $5, updated_at = NOW()
+		WHERE id = $6
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		c.LastBookingID,
+		c.CancelledCount,
+		c.RefundedCount,
+		c.FailedCount,
+		c.LastError,
+		c.ID,
+	)
+	return err
+}
+
+func (r *eventCancellationRepository) Finish(ctx context.Context, id int64, status string) error {
+	query := `
+		UPDATE event_cancellations SET status = $1, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $2
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, status, id)
+	return err
+}
+
+// Retry puts a cancellation that finished with errors back to running from
+// the start. Bookings released on the earlier pass are no longer active, so
+// only the failed ones are picked up again.
+func (r *eventCancellationRepository) Retry(ctx context.Context, eventID int64) (*domain.EventCancellation, error) {
+	query := `
+		UPDATE event_cancellations SET
+			status = 'running', last_booking_id = 0, failed_count = 0, last_error = NULL,
+			completed_at = NULL, updated_at = NOW()
+		WHERE event_id = $1 AND status = 'completed_with_errors'
+		RETURNING id, event_id, COALESCE(reason, ''), requested_by, status, last_booking_id,
+			cancelled_count, refunded_count, failed_count, last_error, created_at, updated_at, completed_at
+	`
+	c, err := scanCancellation(database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrCancellationNotRetryable
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func scanCancellation(scan func(dest ...any) error) (*domain.EventCancellation, error) {
+	var c domain.EventCancellation
+
+	err := scan(
+		&c.ID,
+		&c.EventID,
+		&c.Reason,
+		&c.RequestedBy,
+		&c.Status,
+		&c.LastBookingID,
+		&c.CancelledCount,
+		&c.RefundedCount,
+		&c.FailedCount,
+		&c.LastError,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/domain"
 	"github.com/codepnw/go-ticket-booking/internal/errs"
 )
@@ -17,6 +18,11 @@ type EventRepository interface {
 	GetEventByID(ctx context.Context, id int64) (*domain.Event, error)
 	UpdateEvent(ctx context.Context, e *domain.Event) error
 	DeleteEvent(ctx context.Context, id int64) error
+	GetEventForUpdate(ctx context.Context, id int64) (*domain.Event, error)
+	GetEventForShare(ctx context.Context, id int64) (*domain.Event, error)
+	UpdateEventStatus(ctx context.Context, id int64, from, to string) error
+	CreateTransition(ctx context.Context, t *domain.EventTransition) error
+	ListTransitions(ctx context.Context, eventID int64) ([]*domain.EventTransition, error)
 
 	// Location
 	CreateLocation(ctx context.Context, l *domain.Location) error
@@ -36,10 +42,14 @@ func NewEventRepository(db *sql.DB) EventRepository {
 
 func (r *eventRepository) CreateEvent(ctx context.Context, e *domain.Event) error {
 	query := `
-		INSERT INTO events (name, description, start_time, end_time, location_id)
-		VALUES ($1, $2, $3, $4, $5) RETURNING id;
+		INSERT INTO events (
+			name, description, start_time, end_time, location_id, status, sales_start_at, sales_end_at,
+			refund_full_hours, refund_partial_hours, refund_partial_percent, max_tickets_per_user,
+			require_verified_email
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id;
 	`
-	return r.db.QueryRowContext(
+	return database.Conn(ctx, r.db).QueryRowContext(
 		ctx,
 		query,
 		e.Name,
@@ -47,15 +57,26 @@ func (r *eventRepository) CreateEvent(ctx context.Context, e *domain.Event) erro
 		e.StartTime,
 		e.EndTime,
 		e.LocationID,
+		e.Status,
+		e.SalesStartAt,
+		e.SalesEndAt,
+		e.RefundPolicy.FullHours,
+		e.RefundPolicy.PartialHours,
+		e.RefundPolicy.PartialPercent,
+		e.MaxTicketsPerUser,
+		e.RequireVerifiedEmail,
 	).Scan(&e.ID)
 }
 
+const selectEventQuery = `
+	SELECT id, name, description, start_time, end_time, location_id, status, sales_start_at, sales_end_at,
+		refund_full_hours, refund_partial_hours, refund_partial_percent, max_tickets_per_user,
+		require_verified_email, created_at, updated_at
+	FROM events
+`
+
 func (r *eventRepository) ListEvents(ctx context.Context) ([]*domain.Event, error) {
-	query := `
-		SELECT id, name, description, start_time, end_time, location_id, created_at, updated_at
-		FROM events
-	`
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, selectEventQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -64,60 +85,42 @@ func (r *eventRepository) ListEvents(ctx context.Context) ([]*domain.Event, erro
 	var events []*domain.Event
 
 	for rows.Next() {
-		var e domain.Event
-		err := rows.Scan(
-			&e.ID,
-			&e.Name,
-			&e.Description,
-			&e.StartTime,
-			&e.EndTime,
-			&e.LocationID,
-			&e.CreatedAt,
-			&e.UpdatedAt,
-		)
+		e, err := scanEvent(rows.Scan)
 		if err != nil {
 			return nil, err
 		}
-		events = append(events, &e)
+		events = append(events, e)
 	}
 
 	return events, nil
 }
 
 func (r *eventRepository) GetEventByID(ctx context.Context, id int64) (*domain.Event, error) {
-	query := `
-		SELECT id, name, description, start_time, end_time, location_id, created_at, updated_at
-		FROM events WHERE id = $1
-	`
-	var e domain.Event
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&e.ID,
-		&e.Name,
-		&e.Description,
-		&e.StartTime,
-		&e.EndTime,
-		&e.LocationID,
-		&e.CreatedAt,
-		&e.UpdatedAt,
-	)
+	query := selectEventQuery + "WHERE id = $1"
+	return scanEvent(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan)
+}
 
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errs.ErrEventNotFound
-		}
-		return nil, err
-	}
+func (r *eventRepository) GetEventForUpdate(ctx context.Context, id int64) (*domain.Event, error) {
+	query := selectEventQuery + "WHERE id = $1 FOR UPDATE"
+	return scanEvent(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan)
+}
 
-	return &e, err
+// GetEventForShare reads the event and keeps its status from changing until
+// the transaction ends, without blocking other readers.
+func (r *eventRepository) GetEventForShare(ctx context.Context, id int64) (*domain.Event, error) {
+	query := selectEventQuery + "WHERE id = $1 FOR SHARE"
+	return scanEvent(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan)
 }
 
 func (r *eventRepository) UpdateEvent(ctx context.Context, e *domain.Event) error {
 	query := `
-		UPDATE events SET name = $1, description = $2, start_time = $3, end_time = $4, location_id = $5
-		WHERE id = $6
+		UPDATE events SET name = $1, description = $2, start_time = $3, end_time = $4, location_id = $5,
+			sales_start_at = $6, sales_end_at = $7,
+			refund_full_hours = $8, refund_partial_hours = $9, refund_partial_percent = $10,
+			max_tickets_per_user = $11, require_verified_email = $12, updated_at = NOW()
+		WHERE id = $13
 	`
-	res, err := r.db.ExecContext(
+	res, err := database.Conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		e.Name,
@@ -125,6 +128,13 @@ func (r *eventRepository) UpdateEvent(ctx context.Context, e *domain.Event) erro
 		e.StartTime,
 		e.EndTime,
 		e.LocationID,
+		e.SalesStartAt,
+		e.SalesEndAt,
+		e.RefundPolicy.FullHours,
+		e.RefundPolicy.PartialHours,
+		e.RefundPolicy.PartialPercent,
+		e.MaxTicketsPerUser,
+		e.RequireVerifiedEmail,
 		e.ID,
 	)
 	if err != nil {
@@ -144,7 +154,7 @@ func (r *eventRepository) UpdateEvent(ctx context.Context, e *domain.Event) erro
 }
 
 func (r *eventRepository) DeleteEvent(ctx context.Context, id int64) error {
-	res, err := r.db.ExecContext(ctx, "DELETE FROM events WHERE id = $1", id)
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM events WHERE id = $1", id)
 	if err != nil {
 		return err
 	}
@@ -161,13 +171,114 @@ func (r *eventRepository) DeleteEvent(ctx context.Context, id int64) error {
 	return nil
 }
 
+// UpdateEventStatus moves the event from one status to another. It fails if
+// the event is no longer in the expected status.
+func (r *eventRepository) UpdateEventStatus(ctx context.Context, id int64, from, to string) error {
+	query := `UPDATE events SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, to, id, from)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errs.ErrInvalidEventTransition
+	}
+
+	return nil
+}
+
+func (r *eventRepository) CreateTransition(ctx context.Context, t *domain.EventTransition) error {
+	query := `
+		INSERT INTO event_status_transitions (event_id, from_status, to_status, changed_by, reason)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		t.EventID,
+		t.FromStatus,
+		t.ToStatus,
+		t.ChangedBy,
+		t.Reason,
+	).Scan(&t.ID, &t.CreatedAt)
+}
+
+func (r *eventRepository) ListTransitions(ctx context.Context, eventID int64) ([]*domain.EventTransition, error) {
+	query := `
+		SELECT id, event_id, from_status, to_status, changed_by, COALESCE(reason, ''), created_at
+		FROM event_status_transitions WHERE event_id = $1 ORDER BY id
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []*domain.EventTransition
+
+	for rows.Next() {
+		var t domain.EventTransition
+		err := rows.Scan(
+			&t.ID,
+			&t.EventID,
+			&t.FromStatus,
+			&t.ToStatus,
+			&t.ChangedBy,
+			&t.Reason,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, &t)
+	}
+
+	return transitions, rows.Err()
+}
+
+func scanEvent(scan func(dest ...any) error) (*domain.Event, error) {
+	var e domain.Event
+
+	err := scan(
+		&e.ID,
+		&e.Name,
+		&e.Description,
+		&e.StartTime,
+		&e.EndTime,
+		&e.LocationID,
+		&e.Status,
+		&e.SalesStartAt,
+		&e.SalesEndAt,
+		&e.RefundPolicy.FullHours,
+		&e.RefundPolicy.PartialHours,
+		&e.RefundPolicy.PartialPercent,
+		&e.MaxTicketsPerUser,
+		&e.RequireVerifiedEmail,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrEventNotFound
+		}
+		return nil, err
+	}
+
+	return &e, nil
+}
+
 // Location
 func (r *eventRepository) CreateLocation(ctx context.Context, l *domain.Location) error {
 	query := `
 		INSERT INTO locations (name, description, address, capacity, owner_id)
 		VALUES ($1, $2, $3, $4, $5) RETURNING id;
 	`
-	return r.db.QueryRowContext(
+	return database.Conn(ctx, r.db).QueryRowContext(
 		ctx,
 		query,
 		l.Name,
@@ -183,7 +294,7 @@ func (r *eventRepository) ListLocations(ctx context.Context, limit, offset int)
 		SELECT id, name, description, address, capacity, owner_id, created_at, updated_at
 		FROM locations LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +331,7 @@ func (r *eventRepository) GetLocationByID(ctx context.Context, id int64) (*domai
 		SELECT id, name, description, address, capacity, owner_id, created_at, updated_at
 		FROM locations WHERE id = $1;
 	`
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&loc.ID,
 		&loc.Name,
 		&loc.Description,
@@ -233,7 +344,7 @@ func (r *eventRepository) GetLocationByID(ctx context.Context, id int64) (*domai
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errs.ErrEventNotFound
+			return nil, errs.ErrLocationNotFound
 		}
 		return nil, err
 	}
@@ -246,7 +357,7 @@ func (r *eventRepository) UpdateLocation(ctx context.Context, l *domain.Location
 		UPDATE locations SET name = $1, description = $2, address = $3, capacity = $4, owner_id = $5
 		WHERE id = $6
 	`
-	res, err := r.db.ExecContext(
+	res, err := database.Conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		l.Name,
@@ -273,7 +384,7 @@ func (r *eventRepository) UpdateLocation(ctx context.Context, l *domain.Location
 }
 
 func (r *eventRepository) DeleteLocation(ctx context.Context, id int64) error {
-	res, err := r.db.ExecContext(ctx, "DELETE FROM locations WHERE id = $1", id)
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM locations WHERE id = $1", id)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, k *domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, k *domain.IdempotencyKey) error
+	Release(ctx context.Context, k *domain.IdempotencyKey) error
+}
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve stores k if no live record exists for the same scope, key, method
+// and path. It returns the stored record and whether it was created by this
+// call.
+func (r *idempotencyRepository) Reserve(ctx context.Context, k *domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, idempotency_key, request_method, request_path, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (scope, idempotency_key, request_method, request_path) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL,
+			created_at = NOW(), completed_at = NULL, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING created_at
+	`
+	err := database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		k.Scope,
+		k.Key,
+		k.Method,
+		k.Path,
+		k.RequestHash,
+		k.ExpiresAt,
+	).Scan(&k.CreatedAt)
+
+	if err == nil {
+		return k, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	// key already in use
+	existing, err := r.get(ctx, k.Scope, k.Key, k.Method, k.Path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return existing, false, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, k *domain.IdempotencyKey) error {
+	query := `
+		UPDATE idempotency_keys SET status_code = $1, response_body = $2, completed_at = NOW()
+		WHERE scope = $3 AND idempotency_key = $4 AND request_method = $5 AND request_path = $6
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		k.StatusCode,
+		k.ResponseBody,
+		k.Scope,
+		k.Key,
+		k.Method,
+		k.Path,
+	)
+	return err
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, k *domain.IdempotencyKey) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2 AND request_method = $3 AND request_path = $4
+			AND completed_at IS NULL
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, k.Scope, k.Key, k.Method, k.Path)
+	return err
+}
+
+func (r *idempotencyRepository) get(ctx context.Context, scope, key, method, path string) (*domain.IdempotencyKey, error) {
+	query := `
+		SELECT scope, idempotency_key, request_method, request_path, request_hash, status_code,
+			response_body, created_at, completed_at, expires_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2 AND request_method = $3 AND request_path = $4
+	`
+	var k domain.IdempotencyKey
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, scope, key, method, path).Scan(
+		&k.Scope,
+		&k.Key,
+		&k.Method,
+		&k.Path,
+		&k.RequestHash,
+		&k.StatusCode,
+		&k.ResponseBody,
+		&k.CreatedAt,
+		&k.CompletedAt,
+		&k.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, n *domain.Notification) error
+	ListUnsent(ctx context.Context, maxAttempts, limit int) ([]*domain.Notification, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64) error
+}
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Create queues a notification. A user gets at most one notification of a
+// kind per event and reference, so replays of the same work are no-ops.
+func (r *notificationRepository) Create(ctx context.Context, n *domain.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, event_id, kind, ref_id, subject, body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, event_id, kind, ref_id) DO NOTHING
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, n.UserID, n.EventID, n.Kind, n.RefID, n.Subject, n.Body)
+	return err
+}
+
+func (r *notificationRepository) ListUnsent(ctx context.Context, maxAttempts, limit int) ([]*domain.Notification, error) {
+	query := `
+		SELECT n.id, n.user_id, u.email, n.event_id, n.kind, n.ref_id, n.subject, n.body, n.attempts, n.created_at
+		FROM notifications n
+		JOIN users u ON u.id = n.user_id
+		WHERE n.sent_at IS NULL AND n.attempts < $1
+		ORDER BY n.id
+		LIMIT $2
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, maxAttempts, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*domain.Notification
+
+	for rows.Next() {
+		var n domain.Notification
+		err := rows.Scan(
+			&n.ID,
+			&n.UserID,
+			&n.Email,
+			&n.EventID,
+			&n.Kind,
+			&n.RefID,
+			&n.Subject,
+			&n.Body,
+			&n.Attempts,
+			&n.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &n)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *notificationRepository) MarkSent(ctx context.Context, id int64) error {
+	query := `UPDATE notifications SET sent_at = NOW(), attempts = attempts + 1 WHERE id = $1`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *notificationRepository) MarkFailed(ctx context.Context, id int64) error {
+	query := `UPDATE notifications SET attempts = attempts + 1 WHERE id = $1`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+)
+
+type OrderRepository interface {
+	Create(ctx context.Context, o *domain.Order) error
+	GetByID(ctx context.Context, id int64) (*domain.Order, error)
+	GetForUpdate(ctx context.Context, id int64) (*domain.Order, error)
+	Confirm(ctx context.Context, orderID int64) error
+	Cancel(ctx context.Context, orderID int64) error
+	ExpireHolds(ctx context.Context) (int64, error)
+}
+
+type orderRepository struct {
+	db *sql.DB
+}
+
+func NewOrderRepository(db *sql.DB) OrderRepository {
+	return &orderRepository{db: db}
+}
+
+func (r *orderRepository) Create(ctx context.Context, o *domain.Order) error {
+	query := `
+		INSERT INTO orders (user_id, event_id, status, expires_at)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		o.UserID,
+		o.EventID,
+		o.Status,
+		o.ExpiresAt,
+	).Scan(&o.ID, &o.CreatedAt)
+}
+
+const selectOrderQuery = `
+	SELECT id, user_id, event_id, status, expires_at, created_at, confirmed_at, cancelled_at
+	FROM orders WHERE id = $1
+`
+
+func (r *orderRepository) GetByID(ctx context.Context, id int64) (*domain.Order, error) {
+	return scanOrder(database.Conn(ctx, r.db).QueryRowContext(ctx, selectOrderQuery, id))
+}
+
+func (r *orderRepository) GetForUpdate(ctx context.Context, id int64) (*domain.Order, error) {
+	return scanOrder(database.Conn(ctx, r.db).QueryRowContext(ctx, selectOrderQuery+" FOR UPDATE", id))
+}
+
+func (r *orderRepository) Confirm(ctx context.Context, orderID int64) error {
+	query := `
+		UPDATE orders SET status = 'confirmed', confirmed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, orderID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrOrderNotFound
+	}
+
+	return nil
+}
+
+func (r *orderRepository) Cancel(ctx context.Context, orderID int64) error {
+	query := `
+		UPDATE orders SET status = 'cancelled', cancelled_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, orderID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrOrderNotFound
+	}
+
+	return nil
+}
+
+func (r *orderRepository) ExpireHolds(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE orders SET status = 'expired', updated_at = NOW()
+		WHERE status = 'pending' AND expires_at <= NOW()
+	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire orders: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+func scanOrder(row *sql.Row) (*domain.Order, error) {
+	var o domain.Order
+
+	err := row.Scan(
+		&o.ID,
+		&o.UserID,
+		&o.EventID,
+		&o.Status,
+		&o.ExpiresAt,
+		&o.CreatedAt,
+		&o.ConfirmedAt,
+		&o.CancelledAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	return &o, nil
+}
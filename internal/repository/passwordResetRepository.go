@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, pr *domain.PasswordReset) error
+	GetValidForUpdate(ctx context.Context, tokenHash string) (*domain.PasswordReset, error)
+	InvalidateForUser(ctx context.Context, userID int64) error
+}
+
+type passwordResetRepository struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, pr *domain.PasswordReset) error {
+	query := `
+		INSERT INTO password_resets (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3) RETURNING id, created_at
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		pr.UserID,
+		pr.TokenHash,
+		pr.ExpiresAt,
+	).Scan(&pr.ID, &pr.CreatedAt)
+}
+
+// GetValidForUpdate locks the unused, unexpired reset with the given token
+// hash. It returns errs.ErrInvalidResetToken for anything else.
+func (r *passwordResetRepository) GetValidForUpdate(ctx context.Context, tokenHash string) (*domain.PasswordReset, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_resets
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`
+	var pr domain.PasswordReset
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
+		&pr.ID,
+		&pr.UserID,
+		&pr.TokenHash,
+		&pr.ExpiresAt,
+		&pr.UsedAt,
+		&pr.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrInvalidResetToken
+		}
+		return nil, err
+	}
+
+	return &pr, nil
+}
+
+// InvalidateForUser uses up every outstanding reset of the user, so only
+// the newest link works and none work after a reset.
+func (r *passwordResetRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	query := `UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, p *domain.Payment) error
+	GetByID(ctx context.Context, id int64) (*domain.Payment, error)
+	GetByProviderRefForUpdate(ctx context.Context, provider, ref string) (*domain.Payment, error)
+	GetSettledForUpdate(ctx context.Context, bookingID int64, orderID *int64) (*domain.Payment, error)
+	GetPending(ctx context.Context, bookingID, orderID *int64) (*domain.Payment, error)
+	ListSettling(ctx context.Context) ([]*domain.Payment, error)
+	UpdateStatus(ctx context.Context, id int64, status string) error
+	AddRefunded(ctx context.Context, id int64, amount float64) error
+}
+
+type paymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+func (r *paymentRepository) Create(ctx context.Context, p *domain.Payment) error {
+	query := `
+		INSERT INTO payments (booking_id, order_id, provider, provider_ref, amount, currency, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		p.BookingID,
+		p.OrderID,
+		p.Provider,
+		p.ProviderRef,
+		p.Amount,
+		p.Currency,
+		p.Status,
+	).Scan(&p.ID, &p.CreatedAt)
+}
+
+const selectPaymentQuery = `
+	SELECT id, booking_id, order_id, provider, provider_ref, amount, currency, refunded_amount, status, created_at, updated_at
+	FROM payments
+`
+
+func (r *paymentRepository) GetByID(ctx context.Context, id int64) (*domain.Payment, error) {
+	query := selectPaymentQuery + "WHERE id = $1"
+	return scanPayment(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan)
+}
+
+func (r *paymentRepository) GetByProviderRefForUpdate(ctx context.Context, provider, ref string) (*domain.Payment, error) {
+	query := selectPaymentQuery + "WHERE provider = $1 AND provider_ref = $2 FOR UPDATE"
+	return scanPayment(database.Conn(ctx, r.db).QueryRowContext(ctx, query, provider, ref).Scan)
+}
+
+// GetSettledForUpdate locks the successful payment that covered a booking,
+// either directly or through its order.
+func (r *paymentRepository) GetSettledForUpdate(ctx context.Context, bookingID int64, orderID *int64) (*domain.Payment, error) {
+	query := selectPaymentQuery + `
+		WHERE status IN ('succeeded', 'refunded') AND (booking_id = $1 OR order_id = $2)
+		ORDER BY id DESC LIMIT 1 FOR UPDATE
+	`
+	return scanPayment(database.Conn(ctx, r.db).QueryRowContext(ctx, query, bookingID, orderID).Scan)
+}
+
+// GetPending returns the latest pending payment started for a booking or an
+// order.
+func (r *paymentRepository) GetPending(ctx context.Context, bookingID, orderID *int64) (*domain.Payment, error) {
+	query := selectPaymentQuery + `
+		WHERE status = 'pending' AND (booking_id = $1 OR order_id = $2)
+		ORDER BY id DESC LIMIT 1
+	`
+	return scanPayment(database.Conn(ctx, r.db).QueryRowContext(ctx, query, bookingID, orderID).Scan)
+}
+
+// ListSettling returns the payments still waiting for a capture or refund
+// at the provider.
+func (r *paymentRepository) ListSettling(ctx context.Context) ([]*domain.Payment, error) {
+	query := selectPaymentQuery + "WHERE status IN ('capturing', 'refunding') ORDER BY id"
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*domain.Payment
+
+	for rows.Next() {
+		p, err := scanPayment(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *paymentRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
+	query := `UPDATE payments SET status = $1, updated_at = NOW() WHERE id = $2`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, status, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrPaymentNotFound
+	}
+
+	return nil
+}
+
+// AddRefunded records money returned on a payment; once everything is back
+// the payment itself becomes refunded.
+func (r *paymentRepository) AddRefunded(ctx context.Context, id int64, amount float64) error {
+	query := `
+		UPDATE payments SET
+			refunded_amount = refunded_amount + $1,
+			status = CASE WHEN refunded_amount + $1 >= amount THEN 'refunded' ELSE status END,
+			updated_at = NOW()
+		WHERE id = $2 AND refunded_amount + $1 <= amount
+	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, amount, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrRefundExceedsPaid
+	}
+
+	return nil
+}
+
+func scanPayment(scan func(dest ...any) error) (*domain.Payment, error) {
+	var p domain.Payment
+
+	err := scan(
+		&p.ID,
+		&p.BookingID,
+		&p.OrderID,
+		&p.Provider,
+		&p.ProviderRef,
+		&p.Amount,
+		&p.Currency,
+		&p.RefundedAmount,
+		&p.Status,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
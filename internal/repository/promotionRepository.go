@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/lib/pq"
+)
+
+const (
+	uniquePromotionCode     = "unique_promotion_code"
+	redemptionPromotionFkey = "promotion_redemptions_promotion_id_fkey"
+)
+
+type PromotionRepository interface {
+	Create(ctx context.Context, p *domain.Promotion) error
+	GetByID(ctx context.Context, id int64) (*domain.Promotion, error)
+	List(ctx context.Context) ([]*domain.Promotion, error)
+	ListByCodesForUpdate(ctx context.Context, codes []string) ([]*domain.Promotion, error)
+	Update(ctx context.Context, p *domain.Promotion) error
+	Delete(ctx context.Context, id int64) error
+	CountUses(ctx context.Context, promotionID, userID int64) (int, int, error)
+	CreateRedemption(ctx context.Context, rd *domain.PromotionRedemption) error
+	Report(ctx context.Context, promotionID int64) (*dto.PromotionReport, error)
+}
+
+type promotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+func (r *promotionRepository) Create(ctx context.Context, p *domain.Promotion) error {
+	query := `
+		INSERT INTO promotions (code, description, discount_type, amount, event_id, ticket_type_id,
+			starts_at, ends_at, max_uses, max_uses_per_user, stackable, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at, updated_at
+	`
+	err := database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		p.Code,
+		p.Description,
+		p.DiscountType,
+		p.Amount,
+		p.EventID,
+		p.TicketTypeID,
+		p.StartsAt,
+		p.EndsAt,
+		p.MaxUses,
+		p.MaxUsesPerUser,
+		p.Stackable,
+		p.Active,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if database.IsUniqueViolation(err, uniquePromotionCode) {
+		return errs.ErrPromoCodeTaken
+	}
+	return err
+}
+
+const selectPromotionQuery = `
+	SELECT id, code, description, discount_type, amount, event_id, ticket_type_id, starts_at, ends_at,
+		max_uses, max_uses_per_user, stackable, active, created_at, updated_at
+	FROM promotions
+`
+
+func (r *promotionRepository) GetByID(ctx context.Context, id int64) (*domain.Promotion, error) {
+	row := database.Conn(ctx, r.db).QueryRowContext(ctx, selectPromotionQuery+"WHERE id = $1", id)
+	return scanPromotion(row.Scan)
+}
+
+func (r *promotionRepository) List(ctx context.Context) ([]*domain.Promotion, error) {
+	return r.listPromotions(ctx, selectPromotionQuery+"ORDER BY id DESC")
+}
+
+// ListByCodesForUpdate locks the promotions with the given codes, in id
+// order so concurrent checkouts cannot deadlock. Unknown codes are left
+// out.
+func (r *promotionRepository) ListByCodesForUpdate(ctx context.Context, codes []string) ([]*domain.Promotion, error) {
+	return r.listPromotions(ctx, selectPromotionQuery+"WHERE code = ANY($1) ORDER BY id FOR UPDATE", pq.Array(codes))
+}
+
+func (r *promotionRepository) listPromotions(ctx context.Context, query string, args ...any) ([]*domain.Promotion, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []*domain.Promotion
+
+	for rows.Next() {
+		p, err := scanPromotion(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	return promotions, rows.Err()
+}
+
+func (r *promotionRepository) Update(ctx context.Context, p *domain.Promotion) error {
+	query := `
+		UPDATE promotions SET description = $1, amount = $2, starts_at = $3, ends_at = $4,
+			max_uses = $5, max_uses_per_user = $6, stackable = $7, active = $8, updated_at = NOW()
+		WHERE id = $9
+		RETURNING updated_at
+	`
+	err := database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		p.Description,
+		p.Amount,
+		p.StartsAt,
+		p.EndsAt,
+		p.MaxUses,
+		p.MaxUsesPerUser,
+		p.Stackable,
+		p.Active,
+		p.ID,
+	).Scan(&p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errs.ErrPromotionNotFound
+	}
+	return err
+}
+
+// Delete removes a promotion that was never redeemed. Redeemed ones have to
+// be deactivated instead so the report keeps its history.
+func (r *promotionRepository) Delete(ctx context.Context, id int64) error {
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM promotions WHERE id = $1", id)
+	if err != nil {
+		if database.IsForeignKeyViolation(err, redemptionPromotionFkey) {
+			return errs.ErrPromotionInUse
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrPromotionNotFound
+	}
+
+	return nil
+}
+
+// CountUses counts the promotion's redemptions on confirmed bookings and
+// unexpired holds, in total and for the user.
+func (r *promotionRepository) CountUses(ctx context.Context, promotionID, userID int64) (int, int, error) {
+	var total, user int
+
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE pr.user_id = $2)
+		FROM promotion_redemptions pr
+		JOIN bookings b ON b.id = pr.booking_id
+		WHERE pr.promotion_id = $1
+		AND (b.status = 'confirmed' OR (b.status = 'pending' AND b.expires_at > NOW()))
+	`
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, promotionID, userID).Scan(&total, &user)
+	if err != nil {
+		return 0, 0, err
+	}
+	return total, user, nil
+}
+
+func (r *promotionRepository) CreateRedemption(ctx context.Context, rd *domain.PromotionRedemption) error {
+	query := `
+		INSERT INTO promotion_redemptions (promotion_id, booking_id, user_id, discount)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		rd.PromotionID,
+		rd.BookingID,
+		rd.UserID,
+		rd.Discount,
+	).Scan(&rd.ID, &rd.CreatedAt)
+}
+
+func (r *promotionRepository) Report(ctx context.Context, promotionID int64) (*dto.PromotionReport, error) {
+	p, err := r.GetByID(ctx, promotionID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.PromotionReport{
+		PromotionID: p.ID,
+		Code:        p.Code,
+		Redemptions: []*dto.PromotionRedemption{},
+	}
+
+	query := `
+		SELECT b.id, b.order_id, pr.user_id, b.event_id, b.status, pr.discount, b.price, pr.created_at,
+			b.status = 'confirmed' OR (b.status = 'pending' AND b.expires_at > NOW())
+		FROM promotion_redemptions pr
+		JOIN bookings b ON b.id = pr.booking_id
+		WHERE pr.promotion_id = $1
+		ORDER BY pr.id
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, promotionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make(map[int64]struct{})
+
+	for rows.Next() {
+		var (
+			rd     dto.PromotionRedemption
+			active bool
+		)
+		err := rows.Scan(
+			&rd.BookingID,
+			&rd.OrderID,
+			&rd.UserID,
+			&rd.EventID,
+			&rd.BookingStatus,
+			&rd.Discount,
+			&rd.Price,
+			&rd.CreatedAt,
+			&active,
+		)
+		if err != nil {
+			return nil, err
+		}
+		report.Redemptions = append(report.Redemptions, &rd)
+
+		if !active {
+			continue
+		}
+		report.Uses++
+		users[rd.UserID] = struct{}{}
+
+		if rd.BookingStatus == string(dto.StatusConfirmed) {
+			report.Confirmed++
+			report.TotalDiscount += rd.Discount
+			if rd.Price != nil {
+				report.Revenue += *rd.Price
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.Users = len(users)
+	report.TotalDiscount = math.Round(report.TotalDiscount*100) / 100
+	report.Revenue = math.Round(report.Revenue*100) / 100
+	return report, nil
+}
+
+func scanPromotion(scan func(dest ...any) error) (*domain.Promotion, error) {
+	var p domain.Promotion
+
+	err := scan(
+		&p.ID,
+		&p.Code,
+		&p.Description,
+		&p.DiscountType,
+		&p.Amount,
+		&p.EventID,
+		&p.TicketTypeID,
+		&p.StartsAt,
+		&p.EndsAt,
+		&p.MaxUses,
+		&p.MaxUsesPerUser,
+		&p.Stackable,
+		&p.Active,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrPromotionNotFound
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+)
+
+// QueueRepository is the Postgres store behind event waiting rooms.
+type QueueRepository interface {
+	GetQueue(ctx context.Context, eventID int64) (*domain.EventQueue, error)
+	GetQueueForUpdate(ctx context.Context, eventID int64) (*domain.EventQueue, error)
+	SaveQueue(ctx context.Context, q *domain.EventQueue) error
+	ListEnabledQueues(ctx context.Context) ([]int64, error)
+	SetAdmissionClock(ctx context.Context, eventID int64, at time.Time) error
+	Join(ctx context.Context, eventID, userID int64) (*domain.QueueEntry, error)
+	GetEntry(ctx context.Context, eventID, userID int64) (*domain.QueueEntry, error)
+	CountAhead(ctx context.Context, eventID, position int64) (int64, error)
+	AdmitNext(ctx context.Context, eventID int64, n int, expiresAt time.Time) (int64, error)
+}
+
+type queueRepository struct {
+	db *sql.DB
+}
+
+func NewQueueRepository(db *sql.DB) QueueRepository {
+	return &queueRepository{db: db}
+}
+
+const selectQueueQuery = `
+	SELECT event_id, enabled, rate_per_minute, admission_minutes, admitted_until, created_at, updated_at
+	FROM event_queues
+	WHERE event_id = $1
+`
+
+func (r *queueRepository) GetQueue(ctx context.Context, eventID int64) (*domain.EventQueue, error) {
+	return scanEventQueue(database.Conn(ctx, r.db).QueryRowContext(ctx, selectQueueQuery, eventID).Scan)
+}
+
+func (r *queueRepository) GetQueueForUpdate(ctx context.Context, eventID int64) (*domain.EventQueue, error) {
+	query := selectQueueQuery + " FOR UPDATE"
+	return scanEventQueue(database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID).Scan)
+}
+
+// SaveQueue creates or updates the event's queue settings.
+func (r *queueRepository) SaveQueue(ctx context.Context, q *domain.EventQueue) error {
+	query := `
+		INSERT INTO event_queues (event_id, enabled, rate_per_minute, admission_minutes, admitted_until)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			rate_per_minute = EXCLUDED.rate_per_minute,
+			admission_minutes = EXCLUDED.admission_minutes,
+			admitted_until = EXCLUDED.admitted_until,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		q.EventID,
+		q.Enabled,
+		q.RatePerMinute,
+		q.AdmissionMinutes,
+		q.AdmittedUntil,
+	).Scan(&q.CreatedAt, &q.UpdatedAt)
+}
+
+func (r *queueRepository) ListEnabledQueues(ctx context.Context) ([]int64, error) {
+	query := `SELECT event_id FROM event_queues WHERE enabled`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *queueRepository) SetAdmissionClock(ctx context.Context, eventID int64, at time.Time) error {
+	query := `UPDATE event_queues SET admitted_until = $1 WHERE event_id = $2`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, at, eventID)
+	return err
+}
+
+// Join puts the user at the back of an enabled queue. A user whose
+// admission lapsed joins again; anyone else keeps their place and gets nil.
+func (r *queueRepository) Join(ctx context.Context, eventID, userID int64) (*domain.QueueEntry, error) {
+	query := `
+		WITH next AS (
+			UPDATE event_queues SET last_position = last_position + 1
+			WHERE event_id = $1 AND enabled
+			RETURNING last_position
+		)
+		INSERT INTO queue_entries (event_id, user_id, position)
+		SELECT $1, $2, last_position FROM next
+		ON CONFLICT (event_id, user_id) DO UPDATE SET
+			position = EXCLUDED.position,
+			status = 'waiting',
+			joined_at = NOW(),
+			admitted_at = NULL,
+			expires_at = NULL
+		WHERE queue_entries.status = 'admitted' AND queue_entries.expires_at <= NOW()
+		RETURNING ` + queueEntryColumns
+	e, err := scanQueueEntry(database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID, userID).Scan)
+	if errors.Is(err, errs.ErrNotInQueue) {
+		return nil, nil
+	}
+	return e, err
+}
+
+const queueEntryColumns = `id, event_id, user_id, position, status, joined_at, admitted_at, expires_at`
+
+func (r *queueRepository) GetEntry(ctx context.Context, eventID, userID int64) (*domain.QueueEntry, error) {
+	query := `SELECT ` + queueEntryColumns + ` FROM queue_entries WHERE event_id = $1 AND user_id = $2`
+	return scanQueueEntry(database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID, userID).Scan)
+}
+
+// CountAhead counts the users still waiting in front of position.
+func (r *queueRepository) CountAhead(ctx context.Context, eventID, position int64) (int64, error) {
+	var n int64
+	query := `
+		SELECT COUNT(*) FROM queue_entries
+		WHERE event_id = $1 AND status = 'waiting' AND position < $2
+	`
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID, position).Scan(&n)
+	return n, err
+}
+
+// AdmitNext admits the n users at the front of the line until expiresAt.
+func (r *queueRepository) AdmitNext(ctx context.Context, eventID int64, n int, expiresAt time.Time) (int64, error) {
+	query := `
+		UPDATE queue_entries SET status = 'admitted', admitted_at = NOW(), expires_at = $3
+		WHERE id IN (
+			SELECT id FROM queue_entries
+			WHERE event_id = $1 AND status = 'waiting'
+			ORDER BY position
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, eventID, n, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanEventQueue(scan func(dest ...any) error) (*domain.EventQueue, error) {
+	var q domain.EventQueue
+
+	err := scan(
+		&q.EventID,
+		&q.Enabled,
+		&q.RatePerMinute,
+		&q.AdmissionMinutes,
+		&q.AdmittedUntil,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrQueueNotFound
+		}
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+func scanQueueEntry(scan func(dest ...any) error) (*domain.QueueEntry, error) {
+	var e domain.QueueEntry
+
+	err := scan(
+		&e.ID,
+		&e.EventID,
+		&e.UserID,
+		&e.Position,
+		&e.Status,
+		&e.JoinedAt,
+		&e.AdmittedAt,
+		&e.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrNotInQueue
+		}
+		return nil, err
+	}
+
+	return &e, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+type RefundRepository interface {
+	Create(ctx context.Context, rf *domain.Refund) error
+	ListByBooking(ctx context.Context, bookingID int64) ([]*domain.Refund, error)
+	ListPending(ctx context.Context, limit int) ([]*domain.Refund, error)
+	MarkSucceeded(ctx context.Context, id int64, providerRef string) error
+	MarkAttemptFailed(ctx context.Context, id int64, lastError string, maxAttempts int) error
+}
+
+type refundRepository struct {
+	db *sql.DB
+}
+
+func NewRefundRepository(db *sql.DB) RefundRepository {
+	return &refundRepository{db: db}
+}
+
+func (r *refundRepository) Create(ctx context.Context, rf *domain.Refund) error {
+	query := `
+		INSERT INTO refunds (booking_id, payment_id, amount, percent, provider_ref, reason, forced, created_by, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		rf.BookingID,
+		rf.PaymentID,
+		rf.Amount,
+		rf.Percent,
+		rf.ProviderRef,
+		rf.Reason,
+		rf.Forced,
+		rf.CreatedBy,
+		rf.Status,
+	).Scan(&rf.ID, &rf.CreatedAt)
+}
+
+const selectRefundQuery = `
+	SELECT id, booking_id, payment_id, amount, percent, provider_ref, status, attempts, last_error,
+		COALESCE(reason, ''), forced, created_by, created_at
+	FROM refunds
+`
+
+func (r *refundRepository) ListByBooking(ctx context.Context, bookingID int64) ([]*domain.Refund, error) {
+	query := selectRefundQuery + "WHERE booking_id = $1 ORDER BY id"
+	return r.list(ctx, query, bookingID)
+}
+
+// ListPending returns refunds the provider has not paid out yet, oldest
+// first.
+func (r *refundRepository) ListPending(ctx context.Context, limit int) ([]*domain.Refund, error) {
+	query := selectRefundQuery + "WHERE status = 'pending' ORDER BY id LIMIT $1"
+	return r.list(ctx, query, limit)
+}
+
+func (r *refundRepository) MarkSucceeded(ctx context.Context, id int64, providerRef string) error {
+	query := `
+		UPDATE refunds SET status = 'succeeded', provider_ref = $1, last_error = NULL, updated_at = NOW()
+		WHERE id = $2 AND status = 'pending'
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, providerRef, id)
+	return err
+}
+
+// MarkAttemptFailed records a failed provider call. After maxAttempts the
+// refund is failed and left for an admin.
+func (r *refundRepository) MarkAttemptFailed(ctx context.Context, id int64, lastError string, maxAttempts int) error {
+	query := `
+		UPDATE refunds SET
+			attempts = attempts + 1, last_error = $1, updated_at = NOW(),
+			status = CASE WHEN attempts + 1 >= $2 THEN 'failed' ELSE status END
+		WHERE id = $3 AND status = 'pending'
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, lastError, maxAttempts, id)
+	return err
+}
+
+func (r *refundRepository) list(ctx context.Context, query string, args ...any) ([]*domain.Refund, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []*domain.Refund
+
+	for rows.Next() {
+		var rf domain.Refund
+		err := rows.Scan(
+			&rf.ID,
+			&rf.BookingID,
+			&rf.PaymentID,
+			&rf.Amount,
+			&rf.Percent,
+			&rf.ProviderRef,
+			&rf.Status,
+			&rf.Attempts,
+			&rf.LastError,
+			&rf.Reason,
+			&rf.Forced,
+			&rf.CreatedBy,
+			&rf.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, &rf)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
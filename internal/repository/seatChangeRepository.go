@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+// SeatChangeRepository reads the seat changes that bookingRepository records
+// alongside every booking state change.
+type SeatChangeRepository interface {
+	ListAfter(ctx context.Context, afterID int64, limit int) ([]*domain.SeatChange, error)
+	ListByEventAfter(ctx context.Context, eventID, afterID, upToID int64, limit int) ([]*domain.SeatChange, error)
+	LatestID(ctx context.Context) (int64, error)
+	OldestID(ctx context.Context) (int64, error)
+	Horizon(ctx context.Context) (xmin, xmax int64, err error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type seatChangeRepository struct {
+	db *sql.DB
+}
+
+func NewSeatChangeRepository(db *sql.DB) SeatChangeRepository {
+	return &seatChangeRepository{db: db}
+}
+
+const seatChangeColumns = `id, event_id, seat_id, booking_id, state, created_at`
+
+func (r *seatChangeRepository) ListAfter(ctx context.Context, afterID int64, limit int) ([]*domain.SeatChange, error) {
+	query := `
+		SELECT ` + seatChangeColumns + ` FROM seat_changes
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	return r.list(ctx, query, afterID, limit)
+}
+
+// ListByEventAfter lists an event's changes with afterID < id <= upToID.
+func (r *seatChangeRepository) ListByEventAfter(ctx context.Context, eventID, afterID, upToID int64, limit int) ([]*domain.SeatChange, error) {
+	query := `
+		SELECT ` + seatChangeColumns + ` FROM seat_changes
+		WHERE event_id = $1 AND id > $2 AND id <= $3
+		ORDER BY id
+		LIMIT $4
+	`
+	return r.list(ctx, query, eventID, afterID, upToID, limit)
+}
+
+func (r *seatChangeRepository) LatestID(ctx context.Context) (int64, error) {
+	var id int64
+	query := `SELECT COALESCE(MAX(id), 0) FROM seat_changes`
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query).Scan(&id)
+	return id, err
+}
+
+// OldestID returns the oldest change still kept, or 0 when there is none.
+func (r *seatChangeRepository) OldestID(ctx context.Context) (int64, error) {
+	var id int64
+	query := `SELECT COALESCE(MIN(id), 0) FROM seat_changes`
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query).Scan(&id)
+	return id, err
+}
+
+// Horizon returns the xmin and xmax of a fresh snapshot. Every transaction
+// below xmin has finished; every transaction that has written so far is
+// below xmax.
+func (r *seatChangeRepository) Horizon(ctx context.Context) (int64, int64, error) {
+	var xmin, xmax int64
+	query := `
+		SELECT pg_snapshot_xmin(s)::text::bigint, pg_snapshot_xmax(s)::text::bigint
+		FROM pg_current_snapshot() s
+	`
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query).Scan(&xmin, &xmax)
+	return xmin, xmax, err
+}
+
+func (r *seatChangeRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM seat_changes WHERE created_at < $1`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *seatChangeRepository) list(ctx context.Context, query string, args ...any) ([]*domain.SeatChange, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*domain.SeatChange
+
+	for rows.Next() {
+		var c domain.SeatChange
+		err := rows.Scan(&c.ID, &c.EventID, &c.SeatID, &c.BookingID, &c.State, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &c)
+	}
+
+	return list, rows.Err()
+}
@@ -4,21 +4,40 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/domain"
 	"github.com/codepnw/go-ticket-booking/internal/errs"
 )
 
 type SeatRepository interface {
-	Create(ctx context.Context, tx *sql.Tx, seat *domain.Seat) error
+	Create(ctx context.Context, seat *domain.Seat) error
+	CreateBatch(ctx context.Context, seats []*domain.Seat) error
+	CountBySection(ctx context.Context, sectionID int64) (int, error)
+	CountByEvent(ctx context.Context, eventID int64) (int, error)
 	GetSeatsBySectionID(ctx context.Context, sectionID int64) ([]*domain.Seat, error)
 	GetAvailableSeatsByEvent(ctx context.Context, eventID int64) ([]*domain.Seat, error)
 	GetSeatByID(ctx context.Context, id int64) (*domain.Seat, error)
+	FindAvailableSeat(ctx context.Context, eventID int64, sectionID *int64) (*domain.Seat, error)
+	ListSeatStatesByEvent(ctx context.Context, eventID int64, sectionID *int64) ([]*domain.SeatState, error)
 	UpdateSeat(ctx context.Context, s *domain.Seat) error
 	DeleteSeat(ctx context.Context, seatID int64) error
 	DeleteSeatsBySection(ctx context.Context, sectionID int64) error
 }
 
+const uniqueSeatPosition = "unique_seat_position"
+
+// seatBatchSize keeps a multi-row insert well under the 65535 bind
+// parameter limit.
+const seatBatchSize = 1000
+
+const (
+	seatColumns  = "id, section_id, row_label, seat_number, is_available, ticket_type_id, is_accessible, aisle_after, pos_x, pos_y"
+	seatColumnsS = "s.id, s.section_id, s.row_label, s.seat_number, s.is_available, s.ticket_type_id, s.is_accessible, s.aisle_after, s.pos_x, s.pos_y"
+)
+
 type seatRepository struct {
 	db *sql.DB
 }
@@ -27,13 +46,27 @@ func NewSeatRepository(db *sql.DB) SeatRepository {
 	return &seatRepository{db: db}
 }
 
-func (r *seatRepository) Create(ctx context.Context, tx *sql.Tx, seat *domain.Seat) error {
+func (r *seatRepository) Create(ctx context.Context, seat *domain.Seat) error {
 	query := `
-		INSERT INTO seats (section_id, row_label, seat_number) 
-		VALUES ($1, $2, $3)
+		INSERT INTO seats (section_id, row_label, seat_number, ticket_type_id, is_accessible, aisle_after, pos_x, pos_y) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	res, err := tx.ExecContext(ctx, query, seat.SectionID, seat.RowLabel, seat.SeatNumber)
+	res, err := database.Conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		seat.SectionID,
+		seat.RowLabel,
+		seat.SeatNumber,
+		seat.TicketTypeID,
+		seat.IsAccessible,
+		seat.AisleAfter,
+		seat.X,
+		seat.Y,
+	)
 	if err != nil {
+		if database.IsUniqueViolation(err, uniqueSeatPosition) {
+			return errs.ErrSeatExists
+		}
 		return err
 	}
 
@@ -49,12 +82,79 @@ func (r *seatRepository) Create(ctx context.Context, tx *sql.Tx, seat *domain.Se
 	return err
 }
 
+// CreateBatch inserts the seats with multi-row inserts and sets their IDs.
+// Unlike Create it writes IsAvailable, so callers must set it.
+func (r *seatRepository) CreateBatch(ctx context.Context, seats []*domain.Seat) error {
+	for start := 0; start < len(seats); start += seatBatchSize {
+		batch := seats[start:min(start+seatBatchSize, len(seats))]
+
+		var sb strings.Builder
+		sb.WriteString("INSERT INTO seats (section_id, row_label, seat_number, is_available, ticket_type_id, is_accessible, aisle_after, pos_x, pos_y) VALUES ")
+
+		args := make([]any, 0, len(batch)*9)
+		for i, s := range batch {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9)
+			args = append(args, s.SectionID, s.RowLabel, s.SeatNumber, s.IsAvailable, s.TicketTypeID, s.IsAccessible, s.AisleAfter, s.X, s.Y)
+		}
+		// rows come back in VALUES order
+		sb.WriteString(" RETURNING id")
+
+		rows, err := database.Conn(ctx, r.db).QueryContext(ctx, sb.String(), args...)
+		if err != nil {
+			if database.IsUniqueViolation(err, uniqueSeatPosition) {
+				return errs.ErrSeatExists
+			}
+			return err
+		}
+
+		i := 0
+		for rows.Next() {
+			if err := rows.Scan(&batch[i].ID); err != nil {
+				rows.Close()
+				return err
+			}
+			i++
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			if database.IsUniqueViolation(err, uniqueSeatPosition) {
+				return errs.ErrSeatExists
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *seatRepository) CountBySection(ctx context.Context, sectionID int64) (int, error) {
+	var count int
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM seats WHERE section_id = $1", sectionID).Scan(&count)
+	return count, err
+}
+
+func (r *seatRepository) CountByEvent(ctx context.Context, eventID int64) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM seats s
+		INNER JOIN sections sec ON s.section_id = sec.id
+		WHERE sec.event_id = $1
+	`
+	var count int
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID).Scan(&count)
+	return count, err
+}
+
 func (r *seatRepository) GetSeatsBySectionID(ctx context.Context, sectionID int64) ([]*domain.Seat, error) {
 	query := `
-		SELECT id, section_id, row_label, seat_number, is_available
-		FROM seats WHERE section_id = $1
+		SELECT ` + seatColumns + `
+		FROM seats WHERE section_id = $1 ORDER BY id
 	`
-	rows, err := r.db.QueryContext(ctx, query, sectionID)
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, sectionID)
 	if err != nil {
 		return nil, err
 	}
@@ -63,18 +163,11 @@ func (r *seatRepository) GetSeatsBySectionID(ctx context.Context, sectionID int6
 	var seats []*domain.Seat
 
 	for rows.Next() {
-		var s domain.Seat
-		err := rows.Scan(
-			&s.ID,
-			&s.SectionID,
-			&s.RowLabel,
-			&s.SeatNumber,
-			&s.IsAvailable,
-		)
+		s, err := scanSeat(rows.Scan)
 		if err != nil {
 			return nil, err
 		}
-		seats = append(seats, &s)
+		seats = append(seats, s)
 	}
 
 	return seats, nil
@@ -82,12 +175,17 @@ func (r *seatRepository) GetSeatsBySectionID(ctx context.Context, sectionID int6
 
 func (r *seatRepository) GetAvailableSeatsByEvent(ctx context.Context, eventID int64) ([]*domain.Seat, error) {
 	query := `
-		SELECT s.id, s.section_id, s.row_label, s.seat_number, s.is_available
+		SELECT ` + seatColumnsS + `
 		FROM seats s
 		INNER JOIN sections sec ON s.section_id = sec.id
 		WHERE sec.event_id = $1 AND s.is_available = true
+		AND NOT EXISTS (
+			SELECT 1 FROM bookings b
+			WHERE b.seat_id = s.id
+			AND (b.status = 'confirmed' OR (b.status = 'pending' AND b.expires_at > NOW()))
+		)
 	`
-	rows, err := r.db.QueryContext(ctx, query, eventID)
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -96,55 +194,105 @@ func (r *seatRepository) GetAvailableSeatsByEvent(ctx context.Context, eventID i
 	var seats []*domain.Seat
 
 	for rows.Next() {
-		var s domain.Seat
-		err := rows.Scan(
-			&s.ID,
-			&s.SectionID,
-			&s.RowLabel,
-			&s.SeatNumber,
-			&s.IsAvailable,
-		)
+		s, err := scanSeat(rows.Scan)
 		if err != nil {
 			return nil, err
 		}
-		seats = append(seats, &s)
+		seats = append(seats, s)
 	}
 
 	return seats, nil
 }
 
-func (r *seatRepository) GetSeatByID(ctx context.Context, id int64) (*domain.Seat, error) {
-	s := domain.Seat{}
+// FindAvailableSeat locks one free seat of the event, optionally limited to a
+// section. Seats locked by a concurrent caller are skipped.
+func (r *seatRepository) FindAvailableSeat(ctx context.Context, eventID int64, sectionID *int64) (*domain.Seat, error) {
 	query := `
-		SELECT id, section_id, row_label, seat_number, is_available
-		FROM seats WHERE id = $1
+		SELECT ` + seatColumnsS + `
+		FROM seats s
+		INNER JOIN sections sec ON s.section_id = sec.id
+		WHERE sec.event_id = $1 AND ($2::BIGINT IS NULL OR sec.id = $2) AND s.is_available = true
+		AND NOT EXISTS (
+			SELECT 1 FROM bookings b
+			WHERE b.seat_id = s.id
+			AND (b.status = 'confirmed' OR (b.status = 'pending' AND b.expires_at > NOW()))
+		)
+		ORDER BY s.id
+		LIMIT 1
+		FOR UPDATE OF s SKIP LOCKED
 	`
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&s.ID,
-		&s.SectionID,
-		&s.RowLabel,
-		&s.SeatNumber,
-		&s.IsAvailable,
-	)
+	return scanSeat(database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID, sectionID).Scan)
+}
+
+// ListSeatStatesByEvent returns every seat of the event, optionally limited
+// to a section, with its status: blocked, booked, held or available.
+func (r *seatRepository) ListSeatStatesByEvent(ctx context.Context, eventID int64, sectionID *int64) ([]*domain.SeatState, error) {
+	query := `
+		SELECT ` + seatColumnsS + `,
+			CASE
+				WHEN NOT s.is_available THEN 'blocked'
+				WHEN EXISTS (
+					SELECT 1 FROM bookings b WHERE b.seat_id = s.id AND b.status = 'confirmed'
+				) THEN 'booked'
+				WHEN EXISTS (
+					SELECT 1 FROM bookings b
+					WHERE b.seat_id = s.id AND b.status = 'pending' AND b.expires_at > NOW()
+				) THEN 'held'
+				ELSE 'available'
+			END
+		FROM seats s
+		INNER JOIN sections sec ON s.section_id = sec.id
+		WHERE sec.event_id = $1 AND ($2::BIGINT IS NULL OR sec.id = $2)
+		ORDER BY s.section_id, s.row_label, s.seat_number
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, eventID, sectionID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return &s, nil
+	var seats []*domain.SeatState
+
+	for rows.Next() {
+		var status string
+		seat, err := scanSeat(func(dest ...any) error {
+			return rows.Scan(append(dest, &status)...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		seats = append(seats, &domain.SeatState{Seat: *seat, Status: status})
+	}
+
+	return seats, rows.Err()
+}
+
+func (r *seatRepository) GetSeatByID(ctx context.Context, id int64) (*domain.Seat, error) {
+	query := `
+		SELECT ` + seatColumns + `
+		FROM seats WHERE id = $1
+	`
+	return scanSeat(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan)
 }
 
 func (r *seatRepository) UpdateSeat(ctx context.Context, s *domain.Seat) error {
 	query := `
-		UPDATE seats SET section_id = $1, row_label = $2, seat_number = $3, is_available = $4
-		WHERE id = $5
+		UPDATE seats SET section_id = $1, row_label = $2, seat_number = $3, is_available = $4,
+			ticket_type_id = $5, is_accessible = $6, aisle_after = $7, pos_x = $8, pos_y = $9
+		WHERE id = $10
 	`
-	res, err := r.db.ExecContext(
+	res, err := database.Conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		s.SectionID,
 		s.RowLabel,
 		s.SeatNumber,
 		s.IsAvailable,
+		s.TicketTypeID,
+		s.IsAccessible,
+		s.AisleAfter,
+		s.X,
+		s.Y,
 		s.ID,
 	)
 	if err != nil {
@@ -164,7 +312,7 @@ func (r *seatRepository) UpdateSeat(ctx context.Context, s *domain.Seat) error {
 }
 
 func (r *seatRepository) DeleteSeat(ctx context.Context, seatID int64) error {
-	res, err := r.db.ExecContext(ctx, "DELETE FROM seats WHERE id = $1", seatID)
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM seats WHERE id = $1", seatID)
 	if err != nil {
 		return err
 	}
@@ -182,7 +330,7 @@ func (r *seatRepository) DeleteSeat(ctx context.Context, seatID int64) error {
 }
 
 func (r *seatRepository) DeleteSeatsBySection(ctx context.Context, sectionID int64) error {
-	res, err := r.db.ExecContext(ctx, "DELETE FROM seats WHERE section_id = $1", sectionID)
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM seats WHERE section_id = $1", sectionID)
 	if err != nil {
 		return err
 	}
@@ -198,3 +346,28 @@ func (r *seatRepository) DeleteSeatsBySection(ctx context.Context, sectionID int
 
 	return nil
 }
+
+func scanSeat(scan func(dest ...any) error) (*domain.Seat, error) {
+	var s domain.Seat
+
+	err := scan(
+		&s.ID,
+		&s.SectionID,
+		&s.RowLabel,
+		&s.SeatNumber,
+		&s.IsAvailable,
+		&s.TicketTypeID,
+		&s.IsAccessible,
+		&s.AisleAfter,
+		&s.X,
+		&s.Y,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrSeatNotFound
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/domain"
 )
 
@@ -14,6 +15,8 @@ type SectionRepository interface {
 	GetByID(ctx context.Context, id int64) (*domain.Section, error)
 	Update(ctx context.Context, s *domain.Section) error
 	Delete(ctx context.Context, id int64) error
+	CountByEvent(ctx context.Context, eventID int64) (int, error)
+	ListByEvent(ctx context.Context, eventID int64) ([]*domain.Section, error)
 }
 
 type sectionRepository struct {
@@ -26,24 +29,27 @@ func NewSectionRepository(db *sql.DB) SectionRepository {
 
 func (r *sectionRepository) Create(ctx context.Context, s *domain.Section) error {
 	query := `
-		INSERT INTO sections (event_id, name, seat_count)
-		VALUES ($1, $2, $3) RETURNING id
+		INSERT INTO sections (event_id, name, seat_count, ticket_type_id, template_id, template_version)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
 	`
-	return r.db.QueryRowContext(
+	return database.Conn(ctx, r.db).QueryRowContext(
 		ctx,
 		query,
 		s.EventID,
 		s.Name,
 		s.SeatCount,
+		s.TicketTypeID,
+		s.TemplateID,
+		s.TemplateVersion,
 	).Scan(&s.ID)
 }
 
 func (r *sectionRepository) List(ctx context.Context, limit, offset int) ([]*domain.Section, error) {
 	query := `
-		SELECT id, event_id, name, seat_count, created_at, updated_at
+		SELECT id, event_id, name, seat_count, ticket_type_id, template_id, template_version, created_at, updated_at
 		FROM sections LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +65,9 @@ func (r *sectionRepository) List(ctx context.Context, limit, offset int) ([]*dom
 			&s.EventID,
 			&s.Name,
 			&s.SeatCount,
+			&s.TicketTypeID,
+			&s.TemplateID,
+			&s.TemplateVersion,
 			&s.CreatedAt,
 			&s.UpdatedAt,
 		)
@@ -71,18 +80,57 @@ func (r *sectionRepository) List(ctx context.Context, limit, offset int) ([]*dom
 	return sections, nil
 }
 
+func (r *sectionRepository) ListByEvent(ctx context.Context, eventID int64) ([]*domain.Section, error) {
+	query := `
+		SELECT id, event_id, name, seat_count, ticket_type_id, template_id, template_version, created_at, updated_at
+		FROM sections WHERE event_id = $1 ORDER BY id
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sections []*domain.Section
+
+	for rows.Next() {
+		var s domain.Section
+
+		err := rows.Scan(
+			&s.ID,
+			&s.EventID,
+			&s.Name,
+			&s.SeatCount,
+			&s.TicketTypeID,
+			&s.TemplateID,
+			&s.TemplateVersion,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		sections = append(sections, &s)
+	}
+	return sections, rows.Err()
+}
+
 func (r *sectionRepository) GetByID(ctx context.Context, id int64) (*domain.Section, error) {
 	var sec domain.Section
 
 	query := `
-		SELECT id, event_id, name, seat_count, created_at, updated_at
+		SELECT id, event_id, name, seat_count, ticket_type_id, template_id, template_version, created_at, updated_at
 		FROM sections WHERE id = $1
 	`
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&sec.ID,
 		&sec.EventID,
 		&sec.Name,
 		&sec.SeatCount,
+		&sec.TicketTypeID,
+		&sec.TemplateID,
+		&sec.TemplateVersion,
 		&sec.CreatedAt,
 		&sec.UpdatedAt,
 	)
@@ -92,15 +140,16 @@ func (r *sectionRepository) GetByID(ctx context.Context, id int64) (*domain.Sect
 
 func (r *sectionRepository) Update(ctx context.Context, s *domain.Section) error {
 	query := `
-		UPDATE sections SET event_id = $1, name = $2, seat_count = $3, updated_at = $4
-		WHERE id = $5
+		UPDATE sections SET event_id = $1, name = $2, seat_count = $3, ticket_type_id = $4, updated_at = $5
+		WHERE id = $6
 	`
-	res, err := r.db.ExecContext(
+	res, err := database.Conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		s.EventID,
 		s.Name,
 		s.SeatCount,
+		s.TicketTypeID,
 		s.UpdatedAt,
 		s.ID,
 	)
@@ -121,7 +170,7 @@ func (r *sectionRepository) Update(ctx context.Context, s *domain.Section) error
 }
 
 func (r *sectionRepository) Delete(ctx context.Context, id int64) error {
-	res, err := r.db.ExecContext(ctx, "DELETE FROM section WHERE id = $1", id)
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM section WHERE id = $1", id)
 	if err != nil {
 		return err
 	}
@@ -137,3 +186,9 @@ func (r *sectionRepository) Delete(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+func (r *sectionRepository) CountByEvent(ctx context.Context, eventID int64) (int, error) {
+	var count int
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM sections WHERE event_id = $1", eventID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+)
+
+type TicketTypeRepository interface {
+	Create(ctx context.Context, t *domain.TicketType) error
+	ListByEvent(ctx context.Context, eventID int64) ([]*domain.TicketType, error)
+	GetByID(ctx context.Context, id int64) (*domain.TicketType, error)
+	Update(ctx context.Context, t *domain.TicketType) error
+	Delete(ctx context.Context, id int64) error
+	Reserve(ctx context.Context, id int64) (*domain.TicketType, error)
+}
+
+type ticketTypeRepository struct {
+	db *sql.DB
+}
+
+func NewTicketTypeRepository(db *sql.DB) TicketTypeRepository {
+	return &ticketTypeRepository{db: db}
+}
+
+func (r *ticketTypeRepository) Create(ctx context.Context, t *domain.TicketType) error {
+	query := `
+		INSERT INTO ticket_types (event_id, name, price, quantity, max_per_user)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		t.EventID,
+		t.Name,
+		t.Price,
+		t.Quantity,
+		t.MaxPerUser,
+	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+const ticketTypeColumns = `id, event_id, name, price, quantity, max_per_user, created_at, updated_at`
+
+func (r *ticketTypeRepository) ListByEvent(ctx context.Context, eventID int64) ([]*domain.TicketType, error) {
+	query := `SELECT ` + ticketTypeColumns + ` FROM ticket_types WHERE event_id = $1 ORDER BY id`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []*domain.TicketType
+
+	for rows.Next() {
+		t, err := scanTicketType(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return types, nil
+}
+
+func (r *ticketTypeRepository) GetByID(ctx context.Context, id int64) (*domain.TicketType, error) {
+	query := `SELECT ` + ticketTypeColumns + ` FROM ticket_types WHERE id = $1`
+	return scanTicketType(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan)
+}
+
+func (r *ticketTypeRepository) Update(ctx context.Context, t *domain.TicketType) error {
+	query := `
+		UPDATE ticket_types SET name = $1, price = $2, quantity = $3, max_per_user = $4, updated_at = NOW()
+		WHERE id = $5 RETURNING updated_at
+	`
+	err := database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		t.Name,
+		t.Price,
+		t.Quantity,
+		t.MaxPerUser,
+		t.ID,
+	).Scan(&t.UpdatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return errs.ErrTicketTypeNotFound
+	}
+	return err
+}
+
+func (r *ticketTypeRepository) Delete(ctx context.Context, id int64) error {
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM ticket_types WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrTicketTypeNotFound
+	}
+
+	return nil
+}
+
+// Reserve takes one unit of inventory and returns the ticket type with its
+// current price. It returns errs.ErrTicketTypeSoldOut when none are left.
+func (r *ticketTypeRepository) Reserve(ctx context.Context, id int64) (*domain.TicketType, error) {
+	query := `
+		UPDATE ticket_types SET quantity = quantity - 1, updated_at = NOW()
+		WHERE id = $1 AND quantity > 0
+		RETURNING ` + ticketTypeColumns + `
+	`
+	t, err := scanTicketType(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan)
+	if err == errs.ErrTicketTypeNotFound {
+		return nil, errs.ErrTicketTypeSoldOut
+	}
+	return t, err
+}
+
+func scanTicketType(scan func(dest ...any) error) (*domain.TicketType, error) {
+	var t domain.TicketType
+
+	err := scan(
+		&t.ID,
+		&t.EventID,
+		&t.Name,
+		&t.Price,
+		&t.Quantity,
+		&t.MaxPerUser,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrTicketTypeNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
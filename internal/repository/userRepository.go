@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/domain"
 	"github.com/codepnw/go-ticket-booking/internal/errs"
 )
@@ -14,6 +17,11 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id int64) (*domain.User, error)
 	UpdateLastLogin(ctx context.Context, u *domain.User) error
 	UpdateUser(ctx context.Context, u *domain.User) error
+	UpdatePassword(ctx context.Context, id int64, hashed string, changedAt time.Time) error
+	GetPasswordHash(ctx context.Context, id int64) (string, error)
+	TokensValidAfter(ctx context.Context, id int64) (time.Time, error)
+	MarkEmailVerified(ctx context.Context, id int64, at time.Time) error
+	IsEmailVerified(ctx context.Context, id int64) (bool, error)
 	ListUsers(ctx context.Context, limit, offset int) ([]*domain.User, error)
 	DeleteUser(ctx context.Context, id int64) error
 	SetUserRole(ctx context.Context, id int64, role string) error
@@ -33,7 +41,7 @@ func (r *userRepository) Create(ctx context.Context, u *domain.User) (*domain.Us
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at
 	`
-	err := r.db.QueryRowContext(
+	err := database.Conn(ctx, r.db).QueryRowContext(
 		ctx,
 		query,
 		u.FirstName,
@@ -58,7 +66,7 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain
 		SELECT id, first_name, last_name, email, password, phone, role
 		FROM users WHERE email = $1;
 	`
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.FirstName,
 		&user.LastName,
@@ -76,7 +84,7 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain
 
 func (r *userRepository) UpdateLastLogin(ctx context.Context, u *domain.User) error {
 	query := `UPDATE users SET last_login_at = $1 WHERE id = $2`
-	res, err := r.db.ExecContext(ctx, query, u.LastLoginAt, u.ID)
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, u.LastLoginAt, u.ID)
 	if err != nil {
 		return err
 	}
@@ -97,10 +105,11 @@ func (r *userRepository) FindByID(ctx context.Context, id int64) (*domain.User,
 	var user domain.User
 
 	query := `
-		SELECT id, first_name, last_name, email, phone, role, created_at, updated_at, last_login_at
+		SELECT id, first_name, last_name, email, phone, role, created_at, updated_at, last_login_at,
+			password_changed_at, email_verified_at
 		FROM users WHERE id = $1;
 	`
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.FirstName,
 		&user.LastName,
@@ -110,6 +119,8 @@ func (r *userRepository) FindByID(ctx context.Context, id int64) (*domain.User,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.LastLoginAt,
+		&user.PasswordChangedAt,
+		&user.EmailVerifiedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -123,7 +134,7 @@ func (r *userRepository) UpdateUser(ctx context.Context, u *domain.User) error {
 		UPDATE users SET first_name = $1, last_name = $2, phone = $3, updated_at = $4 
 		WHERE id = $5
 	`
-	res, err := r.db.ExecContext(
+	res, err := database.Conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		u.FirstName,
@@ -148,12 +159,99 @@ func (r *userRepository) UpdateUser(ctx context.Context, u *domain.User) error {
 	return nil
 }
 
+// UpdatePassword stores a new password hash and records changedAt, which
+// invalidates every token issued before it.
+func (r *userRepository) UpdatePassword(ctx context.Context, id int64, hashed string, changedAt time.Time) error {
+	query := `UPDATE users SET password = $1, password_changed_at = $2, updated_at = NOW() WHERE id = $3`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, hashed, changedAt, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) GetPasswordHash(ctx context.Context, id int64) (string, error) {
+	var hashed string
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT password FROM users WHERE id = $1`, id).Scan(&hashed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errs.ErrUserNotFound
+		}
+		return "", err
+	}
+
+	return hashed, nil
+}
+
+// TokensValidAfter returns when the user's password last changed, or the
+// zero time if it never did.
+func (r *userRepository) TokensValidAfter(ctx context.Context, id int64) (time.Time, error) {
+	var changedAt sql.NullTime
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT password_changed_at FROM users WHERE id = $1`, id).Scan(&changedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, errs.ErrUserNotFound
+		}
+		return time.Time{}, err
+	}
+
+	return changedAt.Time, nil
+}
+
+// MarkEmailVerified records when the user verified their email. An
+// address verified earlier keeps its original time.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id int64, at time.Time) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, $1) WHERE id = $2`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, at, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) IsEmailVerified(ctx context.Context, id int64) (bool, error) {
+	var verified bool
+
+	query := `SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1`
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&verified)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, errs.ErrUserNotFound
+		}
+		return false, err
+	}
+
+	return verified, nil
+}
+
 func (r *userRepository) ListUsers(ctx context.Context, limit, offset int) ([]*domain.User, error) {
 	query := `
 		SELECT id, first_name, last_name, email, phone, created_at, updated_at, last_login_at
 		FROM users ORDER BY created_at DESC LIMIT = $1 OFFSET = $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -188,7 +286,7 @@ func (r *userRepository) ListUsers(ctx context.Context, limit, offset int) ([]*d
 
 func (r *userRepository) DeleteUser(ctx context.Context, id int64) error {
 	query := `DELETE FROM users WHERE id = $1`
-	res, err := r.db.ExecContext(ctx, query, id)
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -207,7 +305,7 @@ func (r *userRepository) DeleteUser(ctx context.Context, id int64) error {
 
 func (r *userRepository) SetUserRole(ctx context.Context, id int64, role string) error {
 	query := `UPDATE users SET role = $1 WHERE id = $2`
-	res, err := r.db.ExecContext(ctx, query, role, id)
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, role, id)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+)
+
+const uniqueTemplateName = "unique_venue_template_name"
+
+type VenueTemplateRepository interface {
+	Create(ctx context.Context, t *domain.VenueTemplate) error
+	GetByID(ctx context.Context, id int64) (*domain.VenueTemplate, error)
+	ListByLocation(ctx context.Context, locationID int64) ([]*domain.VenueTemplate, error)
+	Update(ctx context.Context, t *domain.VenueTemplate) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type venueTemplateRepository struct {
+	db *sql.DB
+}
+
+func NewVenueTemplateRepository(db *sql.DB) VenueTemplateRepository {
+	return &venueTemplateRepository{db: db}
+}
+
+func (r *venueTemplateRepository) Create(ctx context.Context, t *domain.VenueTemplate) error {
+	layout, err := json.Marshal(t.Layout)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO venue_templates (location_id, name, description, layout)
+		VALUES ($1, $2, $3, $4) RETURNING id, version, created_at, updated_at
+	`
+	err = database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		t.LocationID,
+		t.Name,
+		t.Description,
+		layout,
+	).Scan(&t.ID, &t.Version, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if database.IsUniqueViolation(err, uniqueTemplateName) {
+			return errs.ErrTemplateNameTaken
+		}
+		return err
+	}
+
+	return nil
+}
+
+const selectVenueTemplateQuery = `
+	SELECT id, location_id, name, COALESCE(description, ''), layout, version, created_at, updated_at
+	FROM venue_templates
+`
+
+func (r *venueTemplateRepository) GetByID(ctx context.Context, id int64) (*domain.VenueTemplate, error) {
+	row := database.Conn(ctx, r.db).QueryRowContext(ctx, selectVenueTemplateQuery+"WHERE id = $1", id)
+	return scanVenueTemplate(row.Scan)
+}
+
+func (r *venueTemplateRepository) ListByLocation(ctx context.Context, locationID int64) ([]*domain.VenueTemplate, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, selectVenueTemplateQuery+"WHERE location_id = $1 ORDER BY id", locationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*domain.VenueTemplate
+
+	for rows.Next() {
+		t, err := scanVenueTemplate(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+// Update replaces the template and bumps its version.
+func (r *venueTemplateRepository) Update(ctx context.Context, t *domain.VenueTemplate) error {
+	layout, err := json.Marshal(t.Layout)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE venue_templates SET name = $1, description = $2, layout = $3,
+			version = version + 1, updated_at = now()
+		WHERE id = $4
+		RETURNING version, updated_at
+	`
+	err = database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		t.Name,
+		t.Description,
+		layout,
+		t.ID,
+	).Scan(&t.Version, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrTemplateNotFound
+		}
+		if database.IsUniqueViolation(err, uniqueTemplateName) {
+			return errs.ErrTemplateNameTaken
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *venueTemplateRepository) Delete(ctx context.Context, id int64) error {
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM venue_templates WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errs.ErrTemplateNotFound
+	}
+
+	return nil
+}
+
+func scanVenueTemplate(scan func(dest ...any) error) (*domain.VenueTemplate, error) {
+	var t domain.VenueTemplate
+	var layout []byte
+
+	err := scan(
+		&t.ID,
+		&t.LocationID,
+		&t.Name,
+		&t.Description,
+		&layout,
+		&t.Version,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrTemplateNotFound
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(layout, &t.Layout); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
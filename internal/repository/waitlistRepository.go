@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+)
+
+type WaitlistRepository interface {
+	Create(ctx context.Context, w *domain.WaitlistEntry) error
+	GetActive(ctx context.Context, eventID, userID int64) (*domain.WaitlistEntry, error)
+	GetActiveForUpdate(ctx context.Context, eventID, userID int64) (*domain.WaitlistEntry, error)
+	Position(ctx context.Context, w *domain.WaitlistEntry) (int, error)
+	NextServableForUpdate(ctx context.Context, eventID int64) (*domain.WaitlistEntry, error)
+	MarkOffered(ctx context.Context, w *domain.WaitlistEntry) error
+	UpdateStatus(ctx context.Context, id int64, status string) error
+	ListWaitingEvents(ctx context.Context) ([]int64, error)
+	ExpireOffers(ctx context.Context) ([]*domain.WaitlistEntry, error)
+}
+
+const uniqueActiveWaitlistEntry = "unique_active_waitlist_entry"
+
+type waitlistRepository struct {
+	db *sql.DB
+}
+
+func NewWaitlistRepository(db *sql.DB) WaitlistRepository {
+	return &waitlistRepository{db: db}
+}
+
+func (r *waitlistRepository) Create(ctx context.Context, w *domain.WaitlistEntry) error {
+	query := `
+		INSERT INTO waitlist_entries (event_id, section_id, user_id)
+		VALUES ($1, $2, $3) RETURNING id, status, created_at
+	`
+	err := database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		w.EventID,
+		w.SectionID,
+		w.UserID,
+	).Scan(&w.ID, &w.Status, &w.CreatedAt)
+	if err != nil {
+		if database.IsUniqueViolation(err, uniqueActiveWaitlistEntry) {
+			return errs.ErrAlreadyOnWaitlist
+		}
+		return err
+	}
+
+	return nil
+}
+
+const selectWaitlistQuery = `
+	SELECT id, event_id, section_id, user_id, status, seat_id, booking_id, offer_expires_at, created_at, updated_at
+	FROM waitlist_entries
+`
+
+func (r *waitlistRepository) GetActive(ctx context.Context, eventID, userID int64) (*domain.WaitlistEntry, error) {
+	query := selectWaitlistQuery + `
+		WHERE event_id = $1 AND user_id = $2 AND status IN ('waiting', 'offered')
+	`
+	return scanWaitlistEntry(database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID, userID).Scan)
+}
+
+func (r *waitlistRepository) GetActiveForUpdate(ctx context.Context, eventID, userID int64) (*domain.WaitlistEntry, error) {
+	query := selectWaitlistQuery + `
+		WHERE event_id = $1 AND user_id = $2 AND status IN ('waiting', 'offered')
+		FOR UPDATE
+	`
+	return scanWaitlistEntry(database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID, userID).Scan)
+}
+
+// Position counts the entries still waiting ahead of w.
+func (r *waitlistRepository) Position(ctx context.Context, w *domain.WaitlistEntry) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM waitlist_entries
+		WHERE event_id = $1 AND status = 'waiting' AND id < $2
+	`
+	var n int
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, w.EventID, w.ID).Scan(&n)
+	return n, err
+}
+
+// NextServableForUpdate locks the first waiting entry of the event that has
+// a free seat in its section, so a narrow request does not hold up the line.
+// Entries another offerer is already working on are skipped.
+func (r *waitlistRepository) NextServableForUpdate(ctx context.Context, eventID int64) (*domain.WaitlistEntry, error) {
+	query := selectWaitlistQuery + `
+		WHERE event_id = $1 AND status = 'waiting'
+		AND EXISTS (
+			SELECT 1 FROM seats s
+			INNER JOIN sections sec ON s.section_id = sec.id
+			WHERE sec.event_id = waitlist_entries.event_id
+			AND (waitlist_entries.section_id IS NULL OR sec.id = waitlist_entries.section_id)
+			AND s.is_available = true
+			AND NOT EXISTS (
+				SELECT 1 FROM bookings b
+				WHERE b.seat_id = s.id
+				AND (b.status = 'confirmed' OR (b.status = 'pending' AND b.expires_at > NOW()))
+			)
+		)
+		ORDER BY id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+	return scanWaitlistEntry(database.Conn(ctx, r.db).QueryRowContext(ctx, query, eventID).Scan)
+}
+
+func (r *waitlistRepository) MarkOffered(ctx context.Context, w *domain.WaitlistEntry) error {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'offered', seat_id = $1, booking_id = $2, offer_expires_at = $3, updated_at = NOW()
+		WHERE id = $4 AND status = 'waiting'
+		RETURNING status
+	`
+	return database.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		w.SeatID,
+		w.BookingID,
+		w.OfferExpiresAt,
+		w.ID,
+	).Scan(&w.Status)
+}
+
+func (r *waitlistRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
+	query := `UPDATE waitlist_entries SET status = $1, updated_at = NOW() WHERE id = $2`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, status, id)
+	return err
+}
+
+// ListWaitingEvents returns the events that still sell tickets and have
+// people waiting.
+func (r *waitlistRepository) ListWaitingEvents(ctx context.Context) ([]int64, error) {
+	query := `
+		SELECT DISTINCT w.event_id
+		FROM waitlist_entries w
+		JOIN events e ON e.id = w.event_id
+		WHERE w.status = 'waiting' AND e.status IN ('on_sale', 'sold_out')
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ExpireOffers closes offers whose time ran out. An offer paid for without
+// being accepted first counts as accepted. The lapsed entries are returned so
+// their holds can be released.
+func (r *waitlistRepository) ExpireOffers(ctx context.Context) ([]*domain.WaitlistEntry, error) {
+	query := `
+		UPDATE waitlist_entries w SET
+			status = CASE WHEN b.status = 'confirmed' THEN 'accepted' ELSE 'expired' END,
+			updated_at = NOW()
+		FROM bookings b
+		WHERE b.id = w.booking_id AND w.status = 'offered' AND w.offer_expires_at <= NOW()
+		RETURNING w.id, w.event_id, w.section_id, w.user_id, w.status, w.seat_id, w.booking_id,
+			w.offer_expires_at, w.created_at, w.updated_at
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lapsed []*domain.WaitlistEntry
+	for rows.Next() {
+		w, err := scanWaitlistEntry(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		if w.Status == "expired" {
+			lapsed = append(lapsed, w)
+		}
+	}
+
+	return lapsed, rows.Err()
+}
+
+func scanWaitlistEntry(scan func(dest ...any) error) (*domain.WaitlistEntry, error) {
+	var w domain.WaitlistEntry
+
+	err := scan(
+		&w.ID,
+		&w.EventID,
+		&w.SectionID,
+		&w.UserID,
+		&w.Status,
+		&w.SeatID,
+		&w.BookingID,
+		&w.OfferExpiresAt,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrWaitlistEntryNotFound
+		}
+		return nil, err
+	}
+
+	return &w, nil
+}
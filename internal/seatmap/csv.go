@@ -0,0 +1,170 @@
+package seatmap
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// header lists the CSV columns in export order. On import the columns may
+// come in any order and only section, row and seat are required.
+var header = []string{"section", "row", "seat", "category", "x", "y", "accessible", "aisle_after", "blocked"}
+
+func ReadCSV(r io.Reader) (*Map, error) {
+	cr := csv.NewReader(r)
+
+	head, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv is empty")
+		}
+		return nil, err
+	}
+
+	cols := make(map[string]int, len(head))
+	for i, name := range head {
+		// spreadsheets often save a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		if !knownColumn(name) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		cols[name] = i
+	}
+	for _, required := range header[:3] {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	m := &Map{Version: Version}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		seat, err := parseRecord(record, cols)
+		if err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+		m.Seats = append(m.Seats, seat)
+	}
+
+	return m, nil
+}
+
+func WriteCSV(w io.Writer, m *Map) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, s := range m.Seats {
+		record := []string{
+			s.Section,
+			s.Row,
+			strconv.Itoa(s.Number),
+			s.Category,
+			formatFloat(s.X),
+			formatFloat(s.Y),
+			strconv.FormatBool(s.Accessible),
+			strconv.FormatBool(s.AisleAfter),
+			strconv.FormatBool(s.Blocked),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// parseRecord keeps text fields as written so they survive an export and
+// import; only numbers and flags are trimmed before parsing.
+func parseRecord(record []string, cols map[string]int) (Seat, error) {
+	raw := func(name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	get := func(name string) string {
+		return strings.TrimSpace(raw(name))
+	}
+
+	var s Seat
+	var err error
+
+	s.Section = raw("section")
+	s.Row = raw("row")
+	s.Category = raw("category")
+
+	if s.Number, err = strconv.Atoi(get("seat")); err != nil {
+		return s, fmt.Errorf("seat: %q is not a number", get("seat"))
+	}
+	if s.X, err = parseFloat(get("x")); err != nil {
+		return s, fmt.Errorf("x: %w", err)
+	}
+	if s.Y, err = parseFloat(get("y")); err != nil {
+		return s, fmt.Errorf("y: %w", err)
+	}
+	if s.Accessible, err = parseBool(get("accessible")); err != nil {
+		return s, fmt.Errorf("accessible: %w", err)
+	}
+	if s.AisleAfter, err = parseBool(get("aisle_after")); err != nil {
+		return s, fmt.Errorf("aisle_after: %w", err)
+	}
+	if s.Blocked, err = parseBool(get("blocked")); err != nil {
+		return s, fmt.Errorf("blocked: %w", err)
+	}
+
+	return s, nil
+}
+
+func knownColumn(name string) bool {
+	for _, h := range header {
+		if h == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFloat(v string) (*float64, error) {
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", v)
+	}
+	return &f, nil
+}
+
+// formatFloat uses the shortest form that parses back to the same value.
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func parseBool(v string) (bool, error) {
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(strings.ToLower(v))
+	if err != nil {
+		return false, fmt.Errorf("%q is not true or false", v)
+	}
+	return b, nil
+}
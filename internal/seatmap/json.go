@@ -0,0 +1,32 @@
+package seatmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func ReadJSON(r io.Reader) (*Map, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var m Map
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+
+	if m.Version != Version {
+		return nil, fmt.Errorf("unsupported seat map version %d", m.Version)
+	}
+
+	return &m, nil
+}
+
+func WriteJSON(w io.Writer, m *Map) error {
+	out := *m
+	out.Version = Version
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&out)
+}
@@ -0,0 +1,155 @@
+// Package seatmap reads and writes section/seat layouts as CSV or JSON so
+// venue teams can edit them in spreadsheets. Both formats carry the same
+// fields, and writing then reading a map gives back the same map.
+package seatmap
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// column sizes of the sections and seats tables
+	maxSectionLen = 50
+	maxRowLen     = 10
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	// Version is the JSON schema version written on export.
+	Version = 1
+)
+
+var ErrUnknownFormat = errors.New("unknown seat map format, use csv or json")
+
+// Schema is the JSON schema of the JSON format.
+//
+//go:embed schema.json
+var Schema []byte
+
+// Map is a seat layout. Sections are identified by name.
+type Map struct {
+	Version int    `json:"version"`
+	Seats   []Seat `json:"seats"`
+}
+
+// Seat is one seat of the layout. Category is the name of a ticket type of
+// the event; Blocked seats exist but are not sold.
+type Seat struct {
+	Section    string   `json:"section"`
+	Row        string   `json:"row"`
+	Number     int      `json:"number"`
+	Category   string   `json:"category,omitempty"`
+	X          *float64 `json:"x,omitempty"`
+	Y          *float64 `json:"y,omitempty"`
+	Accessible bool     `json:"accessible,omitempty"`
+	AisleAfter bool     `json:"aisle_after,omitempty"`
+	Blocked    bool     `json:"blocked,omitempty"`
+}
+
+// Key identifies a seat position.
+type Key struct {
+	Section string
+	Row     string
+	Number  int
+}
+
+func (s Seat) Key() Key {
+	return Key{Section: s.Section, Row: s.Row, Number: s.Number}
+}
+
+// LineError reports a CSV line that could not be parsed.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// SeatError reports an invalid seat by its 1-based position in Seats. In a
+// CSV file seat N is on line N+1.
+type SeatError struct {
+	Seat int
+	Err  error
+}
+
+func (e *SeatError) Error() string {
+	return fmt.Sprintf("seat %d: %v", e.Seat, e.Err)
+}
+
+func (e *SeatError) Unwrap() error {
+	return e.Err
+}
+
+// Read decodes a map in the given format and checks every seat.
+func Read(r io.Reader, format string) (*Map, error) {
+	var m *Map
+	var err error
+
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		m, err = ReadCSV(r)
+	case FormatJSON:
+		m, err = ReadJSON(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return m, m.Validate()
+}
+
+// Write encodes the map in the given format.
+func Write(w io.Writer, m *Map, format string) error {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return WriteCSV(w, m)
+	case FormatJSON:
+		return WriteJSON(w, m)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// Validate checks required fields and duplicate positions.
+func (m *Map) Validate() error {
+	if len(m.Seats) == 0 {
+		return errors.New("seat map has no seats")
+	}
+
+	seen := make(map[Key]int, len(m.Seats))
+	for i, s := range m.Seats {
+		pos := i + 1
+		switch {
+		case s.Section == "" || utf8.RuneCountInString(s.Section) > maxSectionLen:
+			return &SeatError{Seat: pos, Err: fmt.Errorf("section must be 1-%d characters", maxSectionLen)}
+		case s.Row == "" || utf8.RuneCountInString(s.Row) > maxRowLen:
+			return &SeatError{Seat: pos, Err: fmt.Errorf("row must be 1-%d characters", maxRowLen)}
+		case s.Number < 1:
+			return &SeatError{Seat: pos, Err: errors.New("seat number must be positive")}
+		case (s.X == nil) != (s.Y == nil):
+			return &SeatError{Seat: pos, Err: errors.New("x and y must be set together")}
+		}
+
+		if first, ok := seen[s.Key()]; ok {
+			return &SeatError{Seat: pos, Err: fmt.Errorf("same position as seat %d", first)}
+		}
+		seen[s.Key()] = pos
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package seatmap
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func sampleMap() *Map {
+	return &Map{
+		Version: Version,
+		Seats: []Seat{
+			{Section: "Floor", Row: "A", Number: 1, Category: "VIP", X: float(10), Y: float(20)},
+			{Section: "Floor", Row: "A", Number: 2, Category: "VIP", X: float(30.25), Y: float(20), AisleAfter: true},
+			{Section: "Floor", Row: "A", Number: 3, X: float(-0.1), Y: float(1e-7), Accessible: true},
+			{Section: "Balcony", Row: "AA", Number: 12, Blocked: true},
+			{Section: " Box 1", Row: "B ", Number: 4, Category: "  padded  "},
+			{Section: "Stage, left", Row: `"Q"`, Number: 5, Category: "Tier\t2"},
+			{Section: "Zoné", Row: "1", Number: 100, Accessible: true, AisleAfter: true, Blocked: true},
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			want := sampleMap()
+
+			var buf bytes.Buffer
+			if err := Write(&buf, want, format); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+
+			got, err := Read(&buf, format)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("round trip changed the map\ngot:  %+v\nwant: %+v", got.Seats, want.Seats)
+			}
+		})
+	}
+}
+
+func TestRoundTripTwice(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			var first, second bytes.Buffer
+			if err := Write(&first, sampleMap(), format); err != nil {
+				t.Fatal(err)
+			}
+
+			m, err := Read(bytes.NewReader(first.Bytes()), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Write(&second, m, format); err != nil {
+				t.Fatal(err)
+			}
+
+			if first.String() != second.String() {
+				t.Fatalf("export differs after import\nfirst:\n%s\nsecond:\n%s", first.String(), second.String())
+			}
+		})
+	}
+}
+
+func TestReadCSVHeader(t *testing.T) {
+	in := "\uFEFF Seat ,Row, SECTION,x,y\n 3 ,A,Floor, 1.5 ,2\n"
+
+	m, err := Read(strings.NewReader(in), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Seat{{Section: "Floor", Row: "A", Number: 3, X: float(1.5), Y: float(2)}}
+	if !reflect.DeepEqual(m.Seats, want) {
+		t.Fatalf("got %+v, want %+v", m.Seats, want)
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", "csv is empty"},
+		{"unknown column", "section,row,seat,price\n", `unknown column "price"`},
+		{"missing column", "section,seat\n", `missing column "row"`},
+		{"bad number", "section,row,seat\nFloor,A,one\n", `line 2: seat: "one" is not a number`},
+		{"bad flag", "section,row,seat,blocked\nFloor,A,1,maybe\n", `line 2: blocked: "maybe" is not true or false`},
+		{"duplicate", "section,row,seat\nFloor,A,1\nFloor,A,1\n", "seat 2: same position as seat 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.in), FormatCSV)
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCountsCharacters(t *testing.T) {
+	// 30 Thai characters take 90 bytes but fit the 50 character column
+	section := strings.Repeat("ที่", 10)
+
+	m := &Map{Seats: []Seat{{Section: section, Row: "ก", Number: 1}}}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	m.Seats[0].Section = strings.Repeat("ก", maxSectionLen+1)
+	if err := m.Validate(); err == nil {
+		t.Fatal("validate accepted a section longer than the column")
+	}
+}
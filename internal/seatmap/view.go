@@ -0,0 +1,174 @@
+package seatmap
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Seat statuses shown on a rendered map. Accessible is shown instead of
+// available for accessible seats that are free.
+const (
+	StatusAvailable  = "available"
+	StatusHeld       = "held"
+	StatusBooked     = "booked"
+	StatusBlocked    = "blocked"
+	StatusAccessible = "accessible"
+)
+
+const (
+	// seatSpacing is the distance between auto-placed seats, in layout
+	// units. One unit is one SVG pixel.
+	seatSpacing = 20
+	seatRadius  = 8
+	margin      = 30
+	labelHeight = 24
+)
+
+// View is the seat map of an event with the current state of every seat.
+type View struct {
+	EventID  int64         `json:"event_id"`
+	Width    float64       `json:"width"`
+	Height   float64       `json:"height"`
+	Sections []ViewSection `json:"sections"`
+}
+
+type ViewSection struct {
+	ID    int64      `json:"id"`
+	Name  string     `json:"name"`
+	Seats []ViewSeat `json:"seats"`
+}
+
+// ViewSeat is a placed seat. Positioned is false when the seat had no
+// coordinates and was laid out on a grid by row and number.
+type ViewSeat struct {
+	ID         int64   `json:"id"`
+	Row        string  `json:"row"`
+	Number     int     `json:"number"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Positioned bool    `json:"positioned"`
+	Status     string  `json:"status"`
+	Accessible bool    `json:"accessible"`
+}
+
+// DisplayStatus is the status the seat is coloured by.
+func (s ViewSeat) DisplayStatus() string {
+	if s.Accessible && s.Status == StatusAvailable {
+		return StatusAccessible
+	}
+	return s.Status
+}
+
+// Layout places seats without coordinates. Seats with coordinates keep
+// them; sections with unplaced seats are stacked as grids below them. Rows
+// keep the order they first appear in, seats are spread by number.
+func (v *View) Layout() {
+	maxX, maxY := 0.0, 0.0
+	for _, sec := range v.Sections {
+		for _, s := range sec.Seats {
+			if s.Positioned {
+				maxX = math.Max(maxX, s.X)
+				maxY = math.Max(maxY, s.Y)
+			}
+		}
+	}
+
+	top := float64(margin + labelHeight)
+	if maxY > 0 {
+		top = maxY + margin + labelHeight
+	}
+
+	for i := range v.Sections {
+		sec := &v.Sections[i]
+
+		rows := map[string]int{}
+		minNumber := math.MaxInt
+		for _, s := range sec.Seats {
+			if s.Positioned {
+				continue
+			}
+			if _, ok := rows[s.Row]; !ok {
+				rows[s.Row] = len(rows)
+			}
+			minNumber = min(minNumber, s.Number)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		for j := range sec.Seats {
+			s := &sec.Seats[j]
+			if s.Positioned {
+				continue
+			}
+			s.X = float64(margin + (s.Number-minNumber)*seatSpacing)
+			s.Y = top + float64(rows[s.Row]*seatSpacing)
+			maxX = math.Max(maxX, s.X)
+			maxY = math.Max(maxY, s.Y)
+		}
+
+		top += float64(len(rows)*seatSpacing) + margin + labelHeight
+	}
+
+	v.Width = maxX + margin
+	v.Height = maxY + margin
+}
+
+var statusColors = []struct{ status, color string }{
+	{StatusAvailable, "#2e7d32"},
+	{StatusAccessible, "#1565c0"},
+	{StatusHeld, "#f9a825"},
+	{StatusBooked, "#9e9e9e"},
+	{StatusBlocked, "#424242"},
+}
+
+// WriteSVG renders the view. Each seat is a circle with its status as class
+// and its ID in data-seat-id so pages can attach handlers.
+func WriteSVG(w io.Writer, v *View) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %s %s" width="%s" height="%s">`,
+		num(v.Width), num(v.Height), num(v.Width), num(v.Height))
+	b.WriteString("\n<style>.section-label{font:bold 14px sans-serif;fill:#212121}")
+	for _, c := range statusColors {
+		fmt.Fprintf(&b, ".seat.%s{fill:%s}", c.status, c.color)
+	}
+	b.WriteString("</style>\n")
+
+	for _, sec := range v.Sections {
+		if len(sec.Seats) == 0 {
+			continue
+		}
+
+		labelX, labelY := math.MaxFloat64, math.MaxFloat64
+		for _, s := range sec.Seats {
+			labelX = math.Min(labelX, s.X)
+			labelY = math.Min(labelY, s.Y)
+		}
+
+		fmt.Fprintf(&b, `<g class="section" id="section-%d">`, sec.ID)
+		fmt.Fprintf(&b, `<text class="section-label" x="%s" y="%s">%s</text>`,
+			num(labelX-seatRadius), num(labelY-seatRadius-6), html.EscapeString(sec.Name))
+		b.WriteString("\n")
+
+		for _, s := range sec.Seats {
+			fmt.Fprintf(&b, `<circle class="seat %s" cx="%s" cy="%s" r="%d" data-seat-id="%d"><title>Row %s, seat %d (%s)</title></circle>`,
+				s.DisplayStatus(), num(s.X), num(s.Y), seatRadius, s.ID,
+				html.EscapeString(s.Row), s.Number, s.DisplayStatus())
+			b.WriteString("\n")
+		}
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,110 @@
+package seatstream
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+// DefaultPollInterval is how often the hub checks the log when nothing
+// wakes it up earlier.
+const DefaultPollInterval = time.Millisecond * 500
+
+const followBatch = 500
+
+// Log is the seat change log a hub follows and replays from.
+type Log interface {
+	ListAfter(ctx context.Context, afterID int64, limit int) ([]*domain.SeatChange, error)
+	ListByEventAfter(ctx context.Context, eventID, afterID, upToID int64, limit int) ([]*domain.SeatChange, error)
+	LatestID(ctx context.Context) (int64, error)
+	OldestID(ctx context.Context) (int64, error)
+	Horizon(ctx context.Context) (xmin, xmax int64, err error)
+}
+
+// Follow publishes changes from the log in id order as they commit, until
+// ctx is cancelled. It reads the log on every interval and whenever wake
+// fires; wake may be nil.
+func (h *Hub) Follow(ctx context.Context, changes Log, wake <-chan struct{}, interval time.Duration) {
+	f := &follower{hub: h, changes: changes}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.poll(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("seat stream: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+type follower struct {
+	hub     *Hub
+	changes Log
+	started bool
+	cursor  int64
+
+	// Ids come from a sequence, so a change can commit after one with a
+	// higher id. While the id after cursor is missing, gapXmax holds the
+	// snapshot xmax from when that was noticed: once every transaction
+	// below it has finished, the change is either visible or rolled back.
+	gapXmax int64
+}
+
+func (f *follower) poll(ctx context.Context) error {
+	if !f.started {
+		latest, err := f.changes.LatestID(ctx)
+		if err != nil {
+			return err
+		}
+		f.cursor = latest
+		f.started = true
+		f.hub.advance(latest)
+	}
+
+	for {
+		// taken before reading, so anything it counts as finished is visible
+		xmin, _, err := f.changes.Horizon(ctx)
+		if err != nil {
+			return err
+		}
+
+		list, err := f.changes.ListAfter(ctx, f.cursor, followBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range list {
+			if c.ID != f.cursor+1 {
+				if f.gapXmax == 0 {
+					_, xmax, err := f.changes.Horizon(ctx)
+					if err != nil {
+						return err
+					}
+					f.gapXmax = xmax
+					return nil
+				}
+				if xmin < f.gapXmax {
+					// the missing change may still commit
+					return nil
+				}
+			}
+
+			f.hub.Publish(c)
+			f.cursor = c.ID
+			f.gapXmax = 0
+		}
+
+		if len(list) < followBatch {
+			return nil
+		}
+	}
+}
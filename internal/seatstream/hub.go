@@ -0,0 +1,124 @@
+// Package seatstream fans seat changes out to live subscribers per event.
+//
+// Changes are recorded in Postgres together with the booking update that
+// caused them. A Hub follows that log and hands each change to the event's
+// subscribers, so every server instance streams every change no matter
+// which instance made it, and clients can resume from the last change they
+// saw.
+package seatstream
+
+import (
+	"sync"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+// DefaultBuffer is how many changes a subscriber may fall behind by before
+// it is dropped.
+const DefaultBuffer = 256
+
+// Subscriber receives one event's changes. Its channel is closed when the
+// subscriber falls too far behind; it should then resume from the last
+// change it handled.
+type Subscriber struct {
+	eventID int64
+	ch      chan *domain.SeatChange
+}
+
+// Changes returns the channel the subscriber's changes arrive on.
+func (s *Subscriber) Changes() <-chan *domain.SeatChange {
+	return s.ch
+}
+
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[int64]map[*Subscriber]struct{}
+	cursor int64
+	buffer int
+}
+
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{
+		subs:   make(map[int64]map[*Subscriber]struct{}),
+		buffer: buffer,
+	}
+}
+
+// Subscribe registers a subscriber for the event. It also returns the last
+// change published, so everything after it reaches the subscriber and
+// anything up to it can be read from the log.
+func (h *Hub) Subscribe(eventID int64) (*Subscriber, int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := &Subscriber{eventID: eventID, ch: make(chan *domain.SeatChange, h.buffer)}
+	if h.subs[eventID] == nil {
+		h.subs[eventID] = make(map[*Subscriber]struct{})
+	}
+	h.subs[eventID][s] = struct{}{}
+
+	return s, h.cursor
+}
+
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(s)
+}
+
+// Cursor returns the last change published.
+func (h *Hub) Cursor() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.cursor
+}
+
+// Publish hands the change to the event's subscribers without blocking.
+// Subscribers whose buffer is full are dropped.
+func (h *Hub) Publish(c *domain.SeatChange) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subs[c.EventID] {
+		select {
+		case s.ch <- c:
+		default:
+			h.remove(s)
+		}
+	}
+
+	if c.ID > h.cursor {
+		h.cursor = c.ID
+	}
+}
+
+// advance moves the cursor without publishing anything.
+func (h *Hub) advance(cursor int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if cursor > h.cursor {
+		h.cursor = cursor
+	}
+}
+
+func (h *Hub) remove(s *Subscriber) {
+	subs, ok := h.subs[s.eventID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[s]; !ok {
+		return
+	}
+
+	delete(subs, s)
+	if len(subs) == 0 {
+		delete(h.subs, s.eventID)
+	}
+	close(s.ch)
+}
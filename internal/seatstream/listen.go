@@ -0,0 +1,53 @@
+package seatstream
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// notifyChannel is notified by a trigger on seat_changes.
+const notifyChannel = "seat_changes"
+
+// Listen subscribes to Postgres notifications about new seat changes and
+// returns a channel that fires after each, for use as Follow's wake. It
+// also fires after a reconnect, in case notifications were missed.
+func Listen(ctx context.Context, dsn string) (<-chan struct{}, error) {
+	l := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("seat stream listener: %v", err)
+		}
+	})
+
+	if err := l.Listen(notifyChannel); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	wake := make(chan struct{}, 1)
+
+	go func() {
+		defer l.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-l.Notify:
+			case <-time.After(time.Minute):
+				// notices a dead connection that would otherwise stay silent
+				go l.Ping()
+				continue
+			}
+
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return wake, nil
+}
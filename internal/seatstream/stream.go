@@ -0,0 +1,116 @@
+package seatstream
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+// Message types sent to stream clients.
+const (
+	// MessageSeat carries one seat change.
+	MessageSeat = "seat"
+	// MessageReady follows the replay; the client is live from here on.
+	MessageReady = "ready"
+	// MessageReset means the changes since the client's last id are no
+	// longer available. The client should reload the seat map.
+	MessageReset = "reset"
+	// MessageLagged is sent before dropping a client that fell behind. It
+	// can reconnect and resume from the message's id.
+	MessageLagged = "lagged"
+	// MessagePing keeps idle connections open.
+	MessagePing = "ping"
+)
+
+const (
+	// maxReplay caps how many changes a resuming client is sent before it
+	// is told to reload instead.
+	maxReplay         = 1000
+	heartbeatInterval = time.Second * 15
+)
+
+var ErrLagged = errors.New("seat stream client fell behind")
+
+// Message is what a stream client receives. ID is the position to resume
+// from; pings have none.
+type Message struct {
+	Type   string             `json:"type"`
+	ID     int64              `json:"id,omitempty"`
+	Change *domain.SeatChange `json:"change,omitempty"`
+}
+
+// Serve streams the event's seat changes to send until ctx is cancelled or
+// send fails. With lastID set it first replays what the client missed.
+// send should give up on clients that stop reading: the hub drops the
+// subscriber once its buffer fills, and Serve returns ErrLagged.
+func (h *Hub) Serve(ctx context.Context, changes Log, eventID, lastID int64, send func(m *Message) error) error {
+	sub, cursor := h.Subscribe(eventID)
+	defer h.Unsubscribe(sub)
+
+	sent, err := replay(ctx, changes, eventID, lastID, cursor, send)
+	if err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case c, ok := <-sub.Changes():
+			if !ok {
+				send(&Message{Type: MessageLagged, ID: sent})
+				return ErrLagged
+			}
+			if c.ID <= sent {
+				continue
+			}
+			if err := send(&Message{Type: MessageSeat, ID: c.ID, Change: c}); err != nil {
+				return err
+			}
+			sent = c.ID
+
+		case <-heartbeat.C:
+			if err := send(&Message{Type: MessagePing}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// replay sends the event's changes after lastID up to cursor, which the
+// subscriber will not receive, and returns the position reached.
+func replay(ctx context.Context, changes Log, eventID, lastID, cursor int64, send func(m *Message) error) (int64, error) {
+	if lastID <= 0 || lastID >= cursor {
+		pos := max(lastID, cursor)
+		return pos, send(&Message{Type: MessageReady, ID: pos})
+	}
+
+	oldest, err := changes.OldestID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	list, err := changes.ListByEventAfter(ctx, eventID, lastID, cursor, maxReplay+1)
+	if err != nil {
+		return 0, err
+	}
+
+	// some of what the client missed has been pruned already
+	if oldest == 0 || oldest > lastID+1 || len(list) > maxReplay {
+		return cursor, send(&Message{Type: MessageReset, ID: cursor})
+	}
+
+	for _, c := range list {
+		if err := send(&Message{Type: MessageSeat, ID: c.ID, Change: c}); err != nil {
+			return 0, err
+		}
+	}
+
+	return cursor, send(&Message{Type: MessageReady, ID: cursor})
+}
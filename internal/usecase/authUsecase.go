@@ -2,56 +2,191 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
 	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/helper/security"
 	"github.com/codepnw/go-ticket-booking/internal/repository"
 )
 
 type AuthUsecase interface {
-	RefreshToken(ctx context.Context, refreshToken string) (string, error)
-	Logout(ctx context.Context, userID int64) error
+	RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (string, string, error)
+	Logout(ctx context.Context, userID, sessionID int64) error
+
+	ListSessions(ctx context.Context, userID, currentID int64) ([]*dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID int64) error
+	RevokeOtherSessions(ctx context.Context, userID, currentID int64) error
 }
 
 type authUsecase struct {
-	repo repository.AuthRepository
-	auth auth.Auth
+	tx       database.TxManager
+	repo     repository.AuthRepository
+	userRepo repository.UserRepository
+	auth     auth.Auth
 }
 
-func NewAuthUsecase(repo repository.AuthRepository, auth auth.Auth) AuthUsecase {
+func NewAuthUsecase(tx database.TxManager, repo repository.AuthRepository, userRepo repository.UserRepository, auth auth.Auth) AuthUsecase {
 	return &authUsecase{
-		repo: repo,
-		auth: auth,
+		tx:       tx,
+		repo:     repo,
+		userRepo: userRepo,
+		auth:     auth,
 	}
 }
 
-func (u *authUsecase) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
+// RefreshToken rotates a refresh token: the presented one is used up and a
+// new access and refresh token pair is returned for the same session. A
+// token that was already rotated means it leaked, so the whole session is
+// revoked and errs.ErrRefreshTokenReused returned.
+func (u *authUsecase) RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (string, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
-	// verify regresh token
-	user, err := u.auth.VerifyRefreshToken("Bearer " + refreshToken)
+	var accessToken, refreshToken string
+	reused := false
+
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		// refresh tokens are opaque, only their hash is known here
+		stored, err := u.repo.GetRefreshTokenForUpdate(ctx, security.HashToken(req.RefreshToken))
+		if err != nil {
+			return err
+		}
+
+		session, err := u.repo.GetSessionForUpdate(ctx, stored.SessionID)
+		if err != nil {
+			if errors.Is(err, errs.ErrSessionNotFound) {
+				return errs.ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		// the revocation has to commit, so the error is returned after
+		if stored.UsedAt != nil {
+			reused = true
+			err := u.repo.RevokeSession(ctx, session.UserID, session.ID)
+			if errors.Is(err, errs.ErrSessionNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		if session.RevokedAt != nil || !session.ExpiresAt.After(time.Now()) {
+			return errs.ErrInvalidRefreshToken
+		}
+
+		user, err := u.userRepo.FindByID(ctx, session.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.repo.MarkRefreshTokenUsed(ctx, stored.ID); err != nil {
+			return err
+		}
+
+		session.UserAgent = req.UserAgent
+		session.IPAddress = req.IPAddress
+		accessToken, refreshToken, err = issueTokens(ctx, u.repo, &u.auth, user, session)
+		return err
+	})
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	// check token in db
-	ok, err := u.repo.IsRefreshTokenValid(ctx, refreshToken)
-	if err != nil || !ok {
-		return "", err
+	if reused {
+		return "", "", errs.ErrRefreshTokenReused
 	}
 
-	// gen new token
-	accessToken, err := u.auth.GenerateAccessToken(user.ID, user.Email, user.Role)
+	return accessToken, refreshToken, nil
+}
+
+// Logout ends the session the request was made with.
+func (u *authUsecase) Logout(ctx context.Context, userID, sessionID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.RevokeSession(ctx, userID, sessionID)
+}
+
+func (u *authUsecase) ListSessions(ctx context.Context, userID, currentID int64) ([]*dto.SessionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	sessions, err := u.repo.ListActiveSessions(ctx, userID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	res := make([]*dto.SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, dto.NewSessionResponse(s, currentID))
 	}
 
-	return accessToken, nil
+	return res, nil
+}
+
+func (u *authUsecase) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.RevokeSession(ctx, userID, sessionID)
 }
 
-func (u *authUsecase) Logout(ctx context.Context, userID int64) error {
+// RevokeOtherSessions signs the user out everywhere except the current
+// session.
+func (u *authUsecase) RevokeOtherSessions(ctx context.Context, userID, currentID int64) error {
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
-	return u.repo.DeleteRefreshToken(ctx, userID)
-}
\ No newline at end of file
+	return u.repo.RevokeOtherSessions(ctx, userID, currentID)
+}
+
+// startSession opens a session for the user on the given device and returns
+// its first access and refresh tokens. It must run inside a transaction.
+func startSession(ctx context.Context, repo repository.AuthRepository, a *auth.Auth, user *domain.User, device *dto.Device) (string, string, error) {
+	session := &domain.Session{
+		UserID:     user.ID,
+		DeviceName: device.Name,
+		UserAgent:  device.UserAgent,
+		IPAddress:  device.IPAddress,
+		ExpiresAt:  time.Now().Add(auth.RefreshTokenTTL),
+	}
+
+	if err := repo.CreateSession(ctx, session); err != nil {
+		return "", "", err
+	}
+
+	return issueTokens(ctx, repo, a, user, session)
+}
+
+// issueTokens creates an access token and a random refresh token for the
+// session, stores the refresh token's hash and extends the session to its
+// expiry.
+func issueTokens(ctx context.Context, repo repository.AuthRepository, a *auth.Auth, user *domain.User, session *domain.Session) (string, string, error) {
+	accessToken, err := a.GenerateAccessToken(user.ID, user.Email, user.Role, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, refreshHash, err := security.NewToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	exp := time.Now().Add(auth.RefreshTokenTTL)
+	if err := repo.SaveRefreshToken(ctx, session.ID, refreshHash, exp); err != nil {
+		return "", "", err
+	}
+
+	session.LastUsedAt = time.Now()
+	session.ExpiresAt = exp
+	if err := repo.TouchSession(ctx, session); err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
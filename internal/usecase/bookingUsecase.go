@@ -4,7 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"strings"
+	"time"
 
 	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/domain"
@@ -13,6 +13,8 @@ import (
 	"github.com/codepnw/go-ticket-booking/internal/repository"
 )
 
+const bookingHoldTTL = time.Minute * 15
+
 type BookingUsecase interface {
 	Create(ctx context.Context, req *dto.CreateBookingRequest) error
 	GetByID(ctx context.Context, id int64) (*dto.BookingResponse, error)
@@ -23,13 +25,15 @@ type BookingUsecase interface {
 	CancelBooking(ctx context.Context, bookingID int64) (err error)
 	IsAvailable(ctx context.Context, seatID int64) (bool, error)
 	UpdateSeat(ctx context.Context, bookingID, newSeatID int64) error
+	ExpireHolds(ctx context.Context) (int64, error)
 }
 
 type bookingUsecase struct {
-	tx       database.TxManager
-	bookRepo repository.BookingRepository
-	seatRepo repository.SeatRepository
-	sectRepo repository.SectionRepository
+	tx        database.TxManager
+	bookRepo  repository.BookingRepository
+	promoRepo repository.PromotionRepository
+	holder    *seatHolder
+	gate      AdmissionGate
 }
 
 func NewBookingUsecase(
@@ -37,12 +41,18 @@ func NewBookingUsecase(
 	bookRepo repository.BookingRepository,
 	seatRepo repository.SeatRepository,
 	sectRepo repository.SectionRepository,
+	ttRepo repository.TicketTypeRepository,
+	eventRepo repository.EventRepository,
+	promoRepo repository.PromotionRepository,
+	userRepo repository.UserRepository,
+	gate AdmissionGate,
 ) BookingUsecase {
 	return &bookingUsecase{
-		tx:       tx,
-		bookRepo: bookRepo,
-		seatRepo: seatRepo,
-		sectRepo: sectRepo,
+		tx:        tx,
+		bookRepo:  bookRepo,
+		promoRepo: promoRepo,
+		holder:    newSeatHolder(bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, promoRepo, userRepo),
+		gate:      gate,
 	}
 }
 
@@ -50,47 +60,30 @@ func (u *bookingUsecase) Create(ctx context.Context, req *dto.CreateBookingReque
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
-	return u.tx.WithTx(ctx, func(tx *sql.Tx) error {
-		// get seat
-		seat, err := u.seatRepo.GetSeatByID(ctx, req.SeatID)
-		if err != nil {
-			return errs.ErrSeatNotFound
-		}
-
-		// get section & check event ownership
-		section, err := u.sectRepo.GetByID(ctx, seat.SectionID)
-		if err != nil {
-			return errs.ErrSectionNotFound
-		}
+	if err := u.gate.CheckAdmission(ctx, req.EventID, req.UserID, req.AdmissionToken); err != nil {
+		return err
+	}
 
-		if section.EventID != req.EventID {
-			return errs.ErrInvalidSeatEvent
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := u.holder.checkOnSale(ctx, req.EventID, req.UserID); err != nil {
+			return err
 		}
 
-		// check seat available
-		isAvailable, err := u.bookRepo.IsAvailable(ctx, seat.ID)
+		promos, err := loadPromotions(ctx, u.promoRepo, req.EventID, req.PromoCodes)
 		if err != nil {
 			return err
 		}
 
-		if !isAvailable {
-			return errs.ErrSeatAlreadyBooked
-		}
-
-		err = u.bookRepo.Create(ctx, tx, &domain.Booking{
+		_, err = u.holder.hold(ctx, &domain.Booking{
 			UserID:  req.UserID,
 			EventID: req.EventID,
 			SeatID:  req.SeatID,
-			Status:  string(dto.StatusPending),
-		})
-
+		}, time.Now().Add(bookingHoldTTL), promos)
 		if err != nil {
-			if strings.Contains(err.Error(), "unique_booking") {
-				return errors.New("user already booked")
-			}
 			return err
 		}
-		return nil
+
+		return promos.checkApplied()
 	})
 }
 
@@ -134,14 +127,23 @@ func (u *bookingUsecase) ConfirmBooking(ctx context.Context, bookingID int64) er
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
-	return u.tx.WithTx(ctx, func(tx *sql.Tx) error {
-		booking, err := u.bookRepo.GetForUpdate(ctx, tx, bookingID)
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		booking, err := u.bookRepo.GetForUpdate(ctx, bookingID)
 		if err != nil {
 			return errs.ErrBookingNotFound
 		}
 
+		if booking.OrderID != nil {
+			return errs.ErrBookingPartOfOrder
+		}
+
+		// check hold expired
+		if isHoldExpired(booking) {
+			return errs.ErrBookingHoldExpired
+		}
+
 		// check seat confirmed
-		confirmed, err := u.bookRepo.IsSeatConfirmed(ctx, tx, booking.SeatID)
+		confirmed, err := u.bookRepo.IsSeatConfirmed(ctx, booking.SeatID)
 		if err != nil {
 			return err
 		}
@@ -150,13 +152,13 @@ func (u *bookingUsecase) ConfirmBooking(ctx context.Context, bookingID int64) er
 		}
 
 		// confirmed booking
-		err = u.bookRepo.Confirm(ctx, tx, booking.ID)
+		err = u.bookRepo.Confirm(ctx, booking.ID)
 		if err != nil {
 			return err
 		}
 
 		// cancel other booking
-		err = u.bookRepo.CancelOtherBooking(ctx, tx, booking.SeatID, booking.ID)
+		err = u.bookRepo.CancelOtherBooking(ctx, booking.SeatID, booking.ID)
 		return err
 	})
 }
@@ -165,20 +167,26 @@ func (u *bookingUsecase) CancelBooking(ctx context.Context, bookingID int64) err
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
-	return u.tx.WithTx(ctx, func(tx *sql.Tx) error {
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
 		booking, err := u.bookRepo.GetByID(ctx, bookingID)
 		if err != nil {
 			return err
 		}
 
-		if booking.Status == string(dto.StatusCancelled) {
+		if booking.Status == string(dto.StatusCancelled) || booking.Status == string(dto.StatusRefunded) {
 			return errs.ErrBookingAlreadyCancelled
 		}
 		if booking.Status == string(dto.StatusConfirmed) {
 			return errs.ErrBookingAlreadyConfirmed
 		}
+		if booking.Status == string(dto.StatusExpired) {
+			return errs.ErrBookingHoldExpired
+		}
+		if booking.OrderID != nil {
+			return errs.ErrBookingPartOfOrder
+		}
 
-		err = u.bookRepo.Cancel(ctx, tx, bookingID)
+		err = u.bookRepo.Cancel(ctx, bookingID)
 		return err
 	})
 }
@@ -203,8 +211,8 @@ func (u *bookingUsecase) UpdateSeat(ctx context.Context, bookingID, newSeatID in
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
-	return u.tx.WithTx(ctx, func(tx *sql.Tx) error {
-		booking, err := u.bookRepo.GetForUpdate(ctx, tx, bookingID)
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		booking, err := u.bookRepo.GetForUpdate(ctx, bookingID)
 		if err != nil {
 			return errs.ErrBookingNotFound
 		}
@@ -213,33 +221,26 @@ func (u *bookingUsecase) UpdateSeat(ctx context.Context, bookingID, newSeatID in
 		if booking.Status != string(dto.StatusPending) {
 			return errs.ErrBookingNotPending
 		}
-
-		// get seat
-		seat, err := u.seatRepo.GetSeatByID(ctx, newSeatID)
-		if err != nil {
-			return errs.ErrSeatNotFound
+		if isHoldExpired(booking) {
+			return errs.ErrBookingHoldExpired
 		}
 
-		// check new seat in event
-		section, err := u.sectRepo.GetByID(ctx, seat.SectionID)
+		seat, section, err := u.holder.checkSeat(ctx, newSeatID, booking.EventID)
 		if err != nil {
-			return errs.ErrSectionNotFound
+			return err
 		}
-		if section.EventID != booking.EventID {
-			return errs.ErrInvalidSeatEvent
+
+		// keep the price paid, the new seat must be the same ticket type
+		if !sameTicketType(resolveTicketType(seat, section), booking.TicketTypeID) {
+			return errs.ErrTicketTypeMismatch
 		}
 
-		// check seat available
-		isAvailable, err := u.bookRepo.IsAvailable(ctx, seat.ID)
-		if err != nil {
+		if err = u.bookRepo.ExpireSeatHold(ctx, seat.ID); err != nil {
 			return err
 		}
-		if !isAvailable {
-			return errs.ErrSeatAlreadyBooked
-		}
 
 		// update seat
-		err = u.bookRepo.UpdateSeat(ctx, tx, booking.ID, seat.ID)
+		err = u.bookRepo.UpdateSeat(ctx, booking.ID, seat.ID)
 		if err != nil {
 			return err
 		}
@@ -247,3 +248,17 @@ func (u *bookingUsecase) UpdateSeat(ctx context.Context, bookingID, newSeatID in
 		return nil
 	})
 }
+
+func (u *bookingUsecase) ExpireHolds(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.bookRepo.ExpireHolds(ctx)
+}
+
+func isHoldExpired(b *domain.Booking) bool {
+	if b.Status == string(dto.StatusExpired) {
+		return true
+	}
+	return b.Status == string(dto.StatusPending) && b.ExpiresAt != nil && !b.ExpiresAt.After(time.Now())
+}
@@ -0,0 +1,284 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/notification"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+const cancellationBatchSize = 100
+
+type EventCancellationUsecase interface {
+	CancelEvent(ctx context.Context, eventID, userID int64, reason string) (*domain.EventCancellation, error)
+	GetCancellation(ctx context.Context, eventID int64) (*domain.EventCancellation, error)
+	RetryCancellation(ctx context.Context, eventID int64) (*domain.EventCancellation, error)
+	ResumeCancellations(ctx context.Context) (int64, error)
+}
+
+type eventCancellationUsecase struct {
+	tx         database.TxManager
+	eventRepo  repository.EventRepository
+	bookRepo   repository.BookingRepository
+	cancelRepo repository.EventCancellationRepository
+	notifyRepo repository.NotificationRepository
+	refundUc   RefundUsecase
+}
+
+func NewEventCancellationUsecase(
+	tx database.TxManager,
+	eventRepo repository.EventRepository,
+	bookRepo repository.BookingRepository,
+	cancelRepo repository.EventCancellationRepository,
+	notifyRepo repository.NotificationRepository,
+	refundUc RefundUsecase,
+) EventCancellationUsecase {
+	return &eventCancellationUsecase{
+		tx:         tx,
+		eventRepo:  eventRepo,
+		bookRepo:   bookRepo,
+		cancelRepo: cancelRepo,
+		notifyRepo: notifyRepo,
+		refundUc:   refundUc,
+	}
+}
+
+// CancelEvent marks the event cancelled and queues the release of all its
+// bookings. The bookings themselves are processed in batches by
+// ResumeCancellations so a crash part way through loses no work.
+func (u *eventCancellationUsecase) CancelEvent(ctx context.Context, eventID, userID int64, reason string) (*domain.EventCancellation, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	c := &domain.EventCancellation{
+		EventID:     eventID,
+		Reason:      reason,
+		RequestedBy: userID,
+	}
+
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		event, err := u.eventRepo.GetEventForUpdate(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		if event.Status == string(dto.EventCancelled) {
+			return errs.ErrEventAlreadyCancelled
+		}
+
+		if err := u.eventRepo.UpdateEventStatus(ctx, eventID, event.Status, string(dto.EventCancelled)); err != nil {
+			return err
+		}
+
+		err = u.eventRepo.CreateTransition(ctx, &domain.EventTransition{
+			EventID:    eventID,
+			FromStatus: event.Status,
+			ToStatus:   string(dto.EventCancelled),
+			ChangedBy:  &userID,
+			Reason:     reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		return u.cancelRepo.Create(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (u *eventCancellationUsecase) GetCancellation(ctx context.Context, eventID int64) (*domain.EventCancellation, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.cancelRepo.GetByEvent(ctx, eventID)
+}
+
+// RetryCancellation re-runs a cancellation that left bookings unreleased.
+// The runner picks it up on its next pass.
+func (u *eventCancellationUsecase) RetryCancellation(ctx context.Context, eventID int64) (*domain.EventCancellation, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	c, err := u.cancelRepo.Retry(ctx, eventID)
+	if err == errs.ErrCancellationNotRetryable {
+		// tell a missing cancellation apart from one that has nothing to retry
+		if _, err := u.cancelRepo.GetByEvent(ctx, eventID); err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ResumeCancellations works through every unfinished event cancellation and
+// returns the number of bookings handled.
+func (u *eventCancellationUsecase) ResumeCancellations(ctx context.Context) (int64, error) {
+	list, err := u.cancelRepo.ListRunning(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, c := range list {
+		n, err := u.run(ctx, c)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("event %d cancellation: %w", c.EventID, err)
+		}
+	}
+
+	return total, nil
+}
+
+func (u *eventCancellationUsecase) run(ctx context.Context, c *domain.EventCancellation) (int64, error) {
+	event, err := u.eventRepo.GetEventByID(ctx, c.EventID)
+	if err != nil {
+		return 0, err
+	}
+
+	var processed int64
+	for {
+		bookings, err := u.bookRepo.ListActiveByEvent(ctx, c.EventID, c.LastBookingID, cancellationBatchSize)
+		if err != nil {
+			return processed, err
+		}
+
+		if len(bookings) == 0 {
+			return processed, u.finish(ctx, c)
+		}
+
+		for _, b := range bookings {
+			status, err := u.release(ctx, c, event, b.ID)
+			switch {
+			case err != nil:
+				// the booking stays active for RetryCancellation, it must not
+				// stall the rest
+				c.FailedCount++
+				msg := fmt.Sprintf("booking %d: %v", b.ID, err)
+				c.LastError = &msg
+			case status == dto.StatusCancelled:
+				c.CancelledCount++
+			case status == dto.StatusRefunded:
+				c.RefundedCount++
+			}
+
+			c.LastBookingID = b.ID
+			processed++
+		}
+
+		if err := u.cancelRepo.SaveProgress(ctx, c); err != nil {
+			return processed, err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+	}
+}
+
+// finish closes the pass. Bookings that failed to release keep the
+// cancellation out of completed until an admin retries it.
+func (u *eventCancellationUsecase) finish(ctx context.Context, c *domain.EventCancellation) error {
+	status := dto.CancellationCompleted
+	if c.FailedCount > 0 {
+		status = dto.CancellationCompletedWithErrors
+	}
+
+	return u.cancelRepo.Finish(ctx, c.ID, string(status))
+}
+
+// release cancels a pending booking or fully refunds a confirmed one, and
+// queues the user's notification. A refund runs in its own transaction so
+// the provider is only asked for the money after the refund is recorded.
+func (u *eventCancellationUsecase) release(ctx context.Context, c *domain.EventCancellation, event *domain.Event, bookingID int64) (dto.BookingStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	var (
+		status dto.BookingStatus
+		userID int64
+	)
+
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		b, err := u.bookRepo.GetForUpdate(ctx, bookingID)
+		if err != nil {
+			return err
+		}
+		userID = b.UserID
+
+		switch dto.BookingStatus(b.Status) {
+		case dto.StatusPending:
+			if err := u.bookRepo.Cancel(ctx, b.ID); err != nil {
+				return err
+			}
+			status = dto.StatusCancelled
+			return u.notify(ctx, c, event, b.UserID)
+
+		case dto.StatusConfirmed:
+			// refunded below, outside this transaction
+			status = dto.StatusRefunded
+		}
+
+		// settled by someone else since it was listed
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if status != dto.StatusRefunded {
+		return status, nil
+	}
+
+	req := &dto.ForceRefundRequest{Reason: "event cancelled: " + c.Reason}
+
+	_, err = u.refundUc.ForceRefund(ctx, bookingID, c.RequestedBy, req)
+	if errors.Is(err, errs.ErrPaymentNotFound) {
+		// confirmed without a recorded payment, nothing to pay back
+		var zero float64
+		req.Amount = &zero
+		_, err = u.refundUc.ForceRefund(ctx, bookingID, c.RequestedBy, req)
+	}
+	if errors.Is(err, errs.ErrBookingNotConfirmed) {
+		// settled by someone else since it was locked
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// the notification is unique per user and event, so a retried pass
+	// never queues it twice
+	if err := u.notify(ctx, c, event, userID); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+func (u *eventCancellationUsecase) notify(ctx context.Context, c *domain.EventCancellation, event *domain.Event, userID int64) error {
+	eventID := c.EventID
+	return u.notifyRepo.Create(ctx, &domain.Notification{
+		UserID:  userID,
+		EventID: &eventID,
+		Kind:    notification.KindEventCancelled,
+		Subject: fmt.Sprintf("%s has been cancelled", event.Name),
+		Body: fmt.Sprintf(
+			"%s on %s has been cancelled. Reason: %s. Your bookings are cancelled and any payment is refunded in full.",
+			event.Name, event.StartTime.Format("2 Jan 2006 15:04"), c.Reason,
+		),
+	})
+}
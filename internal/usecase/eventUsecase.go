@@ -4,20 +4,42 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/domain"
 	"github.com/codepnw/go-ticket-booking/internal/dto"
 	"github.com/codepnw/go-ticket-booking/internal/errs"
 	"github.com/codepnw/go-ticket-booking/internal/repository"
 )
 
+// defaultRefundPolicy applies to events created without an explicit policy.
+var defaultRefundPolicy = domain.RefundPolicy{
+	FullHours:      72,
+	PartialHours:   24,
+	PartialPercent: 50,
+}
+
+// eventTransitions lists the statuses an event may move to from each status.
+// Cancelling goes through EventCancellationUsecase, which also releases the
+// bookings, and is final.
+var eventTransitions = map[dto.EventStatus][]dto.EventStatus{
+	dto.EventDraft:     {dto.EventPublished},
+	dto.EventPublished: {dto.EventDraft, dto.EventOnSale, dto.EventPostponed},
+	dto.EventOnSale:    {dto.EventPublished, dto.EventSoldOut, dto.EventPostponed},
+	dto.EventSoldOut:   {dto.EventOnSale, dto.EventPostponed},
+	dto.EventPostponed: {dto.EventPublished, dto.EventOnSale},
+}
+
 type EventUsecase interface {
 	CreateEvent(ctx context.Context, e *dto.EventRequest) (*domain.Event, error)
 	ListEvents(ctx context.Context) ([]*domain.Event, error)
 	GetEventByID(ctx context.Context, id int64) (*domain.Event, error)
 	UpdateEvent(ctx context.Context, id int64, e *dto.EventUpdateRequest) error
 	DeleteEvent(ctx context.Context, id int64) error
+	ChangeStatus(ctx context.Context, id, userID int64, req *dto.EventStatusRequest) (*domain.EventTransition, error)
+	ListTransitions(ctx context.Context, id int64) ([]*domain.EventTransition, error)
 
 	// Location
 	CreateLocation(ctx context.Context, req *dto.LocationRequest) error
@@ -28,11 +50,13 @@ type EventUsecase interface {
 }
 
 type eventUsecase struct {
+	tx   database.TxManager
 	repo repository.EventRepository
 }
 
-func NewEventUsecase(repo repository.EventRepository) EventUsecase {
+func NewEventUsecase(tx database.TxManager, repo repository.EventRepository) EventUsecase {
 	return &eventUsecase{
+		tx:   tx,
 		repo: repo,
 	}
 }
@@ -48,6 +72,22 @@ func (u *eventUsecase) CreateEvent(ctx context.Context, req *dto.EventRequest) (
 		StartTime:   req.StartTime,
 		EndTime:     req.EndTime,
 		LocationID:  req.LocationID,
+
+		Status:       string(dto.EventDraft),
+		SalesStartAt: req.SalesStartAt,
+		SalesEndAt:   req.SalesEndAt,
+		RefundPolicy: defaultRefundPolicy,
+
+		MaxTicketsPerUser:    req.MaxTicketsPerUser,
+		RequireVerifiedEmail: req.RequireVerifiedEmail,
+	}
+
+	if !validSalesWindow(event) {
+		return nil, errs.ErrInvalidSalesWindow
+	}
+
+	if req.RefundPolicy != nil {
+		event.RefundPolicy = refundPolicy(req.RefundPolicy)
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
@@ -70,7 +110,9 @@ func (u *eventUsecase) UpdateEvent(ctx context.Context, id int64, req *dto.Event
 	}
 
 	if req.Name == nil && req.Description == nil && req.StartTime == nil &&
-		req.EndTime == nil && req.LocationID == nil {
+		req.EndTime == nil && req.LocationID == nil && req.RefundPolicy == nil &&
+		req.SalesStartAt == nil && req.SalesEndAt == nil && req.MaxTicketsPerUser == nil &&
+		req.RequireVerifiedEmail == nil {
 		return errs.ErrNoFieldsToUpdate
 	}
 
@@ -94,10 +136,34 @@ func (u *eventUsecase) UpdateEvent(ctx context.Context, id int64, req *dto.Event
 		event.LocationID = *req.LocationID
 	}
 
+	if req.SalesStartAt != nil {
+		event.SalesStartAt = req.SalesStartAt
+	}
+
+	if req.SalesEndAt != nil {
+		event.SalesEndAt = req.SalesEndAt
+	}
+
+	if req.RefundPolicy != nil {
+		event.RefundPolicy = refundPolicy(req.RefundPolicy)
+	}
+
+	if req.MaxTicketsPerUser != nil {
+		event.MaxTicketsPerUser = purchaseLimit(*req.MaxTicketsPerUser)
+	}
+
+	if req.RequireVerifiedEmail != nil {
+		event.RequireVerifiedEmail = *req.RequireVerifiedEmail
+	}
+
 	if event.EndTime.Before(event.StartTime) {
 		return errors.New("end time cannot be before start time")
 	}
 
+	if !validSalesWindow(event) {
+		return errs.ErrInvalidSalesWindow
+	}
+
 	event.UpdatedAt = time.Now()
 
 	return u.repo.UpdateEvent(ctx, event)
@@ -121,7 +187,65 @@ func (u *eventUsecase) DeleteEvent(ctx context.Context, id int64) error {
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
-	return u.repo.DeleteEvent(ctx, id)
+	// deleting cascades to bookings, so only events never sold can go
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		event, err := u.repo.GetEventForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if event.Status != string(dto.EventDraft) {
+			return errs.ErrEventNotDraft
+		}
+
+		return u.repo.DeleteEvent(ctx, id)
+	})
+}
+
+// ChangeStatus moves the event along its lifecycle and records who did it.
+func (u *eventUsecase) ChangeStatus(ctx context.Context, id, userID int64, req *dto.EventStatusRequest) (*domain.EventTransition, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	var transition *domain.EventTransition
+
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		event, err := u.repo.GetEventForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		from := dto.EventStatus(event.Status)
+		if !slices.Contains(eventTransitions[from], req.Status) {
+			return errs.ErrInvalidEventTransition
+		}
+
+		if err := u.repo.UpdateEventStatus(ctx, id, string(from), string(req.Status)); err != nil {
+			return err
+		}
+
+		transition = &domain.EventTransition{
+			EventID:    id,
+			FromStatus: string(from),
+			ToStatus:   string(req.Status),
+			ChangedBy:  &userID,
+			Reason:     req.Reason,
+		}
+
+		return u.repo.CreateTransition(ctx, transition)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transition, nil
+}
+
+func (u *eventUsecase) ListTransitions(ctx context.Context, id int64) ([]*domain.EventTransition, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.ListTransitions(ctx, id)
 }
 
 func (u *eventUsecase) CreateLocation(ctx context.Context, req *dto.LocationRequest) error {
@@ -196,3 +320,24 @@ func (u *eventUsecase) DeleteLocation(ctx context.Context, id int64) error {
 
 	return u.repo.DeleteLocation(ctx, id)
 }
+
+func validSalesWindow(e *domain.Event) bool {
+	return e.SalesStartAt == nil || e.SalesEndAt == nil || e.SalesEndAt.After(*e.SalesStartAt)
+}
+
+func refundPolicy(req *dto.RefundPolicyRequest) domain.RefundPolicy {
+	return domain.RefundPolicy{
+		FullHours:      req.FullHours,
+		PartialHours:   req.PartialHours,
+		PartialPercent: req.PartialPercent,
+	}
+}
+
+// purchaseLimit turns a requested limit into the stored one, where 0
+// means no limit.
+func purchaseLimit(n int) *int {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
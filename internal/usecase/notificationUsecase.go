@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/codepnw/go-ticket-booking/internal/notification"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+const (
+	notificationBatchSize   = 100
+	notificationMaxAttempts = 5
+)
+
+type NotificationUsecase interface {
+	DispatchPending(ctx context.Context) (int64, error)
+}
+
+type notificationUsecase struct {
+	repo     repository.NotificationRepository
+	notifier notification.Notifier
+}
+
+func NewNotificationUsecase(repo repository.NotificationRepository, notifier notification.Notifier) NotificationUsecase {
+	return &notificationUsecase{
+		repo:     repo,
+		notifier: notifier,
+	}
+}
+
+// DispatchPending sends queued notifications and returns how many went out.
+// Failed sends are retried on later runs up to notificationMaxAttempts.
+func (u *notificationUsecase) DispatchPending(ctx context.Context) (int64, error) {
+	listCtx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	list, err := u.repo.ListUnsent(listCtx, notificationMaxAttempts, notificationBatchSize)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	var sent int64
+	for _, n := range list {
+		if err := u.notifier.Send(ctx, n); err != nil {
+			log.Printf("send notification %d failed: %v", n.ID, err)
+			if err := u.repo.MarkFailed(ctx, n.ID); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		if err := u.repo.MarkSent(ctx, n.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
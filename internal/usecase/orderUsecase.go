@@ -0,0 +1,322 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/allocator"
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+type OrderUsecase interface {
+	Create(ctx context.Context, req *dto.CreateOrderRequest) (*dto.OrderResponse, error)
+	CreateBestAvailable(ctx context.Context, req *dto.BestAvailableOrderRequest) (*dto.OrderResponse, error)
+	GetByID(ctx context.Context, id int64) (*dto.OrderResponse, error)
+	ConfirmOrder(ctx context.Context, orderID int64) error
+	CancelOrder(ctx context.Context, orderID int64) error
+	ExpireHolds(ctx context.Context) (int64, error)
+}
+
+type orderUsecase struct {
+	tx        database.TxManager
+	orderRepo repository.OrderRepository
+	bookRepo  repository.BookingRepository
+	seatRepo  repository.SeatRepository
+	promoRepo repository.PromotionRepository
+	holder    *seatHolder
+	gate      AdmissionGate
+}
+
+// bestAvailableAttempts is how often a best-available order is retried when
+// another buyer takes one of the chosen seats first.
+const bestAvailableAttempts = 3
+
+func NewOrderUsecase(
+	tx database.TxManager,
+	orderRepo repository.OrderRepository,
+	bookRepo repository.BookingRepository,
+	seatRepo repository.SeatRepository,
+	sectRepo repository.SectionRepository,
+	ttRepo repository.TicketTypeRepository,
+	eventRepo repository.EventRepository,
+	promoRepo repository.PromotionRepository,
+	userRepo repository.UserRepository,
+	gate AdmissionGate,
+) OrderUsecase {
+	return &orderUsecase{
+		tx:        tx,
+		orderRepo: orderRepo,
+		bookRepo:  bookRepo,
+		seatRepo:  seatRepo,
+		promoRepo: promoRepo,
+		holder:    newSeatHolder(bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, promoRepo, userRepo),
+		gate:      gate,
+	}
+}
+
+func (u *orderUsecase) Create(ctx context.Context, req *dto.CreateOrderRequest) (*dto.OrderResponse, error) {
+	if err := u.checkAdmission(ctx, req.EventID, req.UserID, req.AdmissionToken); err != nil {
+		return nil, err
+	}
+
+	return u.create(ctx, req)
+}
+
+func (u *orderUsecase) create(ctx context.Context, req *dto.CreateOrderRequest) (*dto.OrderResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	expiresAt := time.Now().Add(bookingHoldTTL)
+	order := &domain.Order{
+		UserID:    req.UserID,
+		EventID:   req.EventID,
+		Status:    string(dto.StatusPending),
+		ExpiresAt: &expiresAt,
+	}
+
+	// all seats or none
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := u.holder.checkOnSale(ctx, req.EventID, req.UserID); err != nil {
+			return err
+		}
+
+		promos, err := loadPromotions(ctx, u.promoRepo, req.EventID, req.PromoCodes)
+		if err != nil {
+			return err
+		}
+
+		if err := u.orderRepo.Create(ctx, order); err != nil {
+			return err
+		}
+
+		for _, seatID := range req.SeatIDs {
+			_, err := u.holder.hold(ctx, &domain.Booking{
+				UserID:  req.UserID,
+				EventID: req.EventID,
+				SeatID:  seatID,
+				OrderID: &order.ID,
+			}, expiresAt, promos)
+			if err != nil {
+				return err
+			}
+		}
+		return promos.checkApplied()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.GetByID(ctx, order.ID)
+}
+
+// CreateBestAvailable picks req.Quantity seats with the allocator and holds
+// them as one order.
+func (u *orderUsecase) CreateBestAvailable(ctx context.Context, req *dto.BestAvailableOrderRequest) (*dto.OrderResponse, error) {
+	if err := u.checkAdmission(ctx, req.EventID, req.UserID, req.AdmissionToken); err != nil {
+		return nil, err
+	}
+
+	pref := allocator.PreferCenter
+	if req.Preference != "" {
+		pref = allocator.Preference(req.Preference)
+	}
+
+	var err error
+	for range bestAvailableAttempts {
+		var seatIDs []int64
+		seatIDs, err = u.pickSeats(ctx, req, pref)
+		if err != nil {
+			return nil, err
+		}
+
+		var order *dto.OrderResponse
+		order, err = u.create(ctx, &dto.CreateOrderRequest{
+			UserID:     req.UserID,
+			EventID:    req.EventID,
+			SeatIDs:    seatIDs,
+			PromoCodes: req.PromoCodes,
+		})
+		// lost a seat to a concurrent buyer, pick again
+		if err == errs.ErrSeatAlreadyBooked {
+			continue
+		}
+		return order, err
+	}
+
+	return nil, err
+}
+
+func (u *orderUsecase) checkAdmission(ctx context.Context, eventID, userID int64, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.gate.CheckAdmission(ctx, eventID, userID, token)
+}
+
+func (u *orderUsecase) pickSeats(ctx context.Context, req *dto.BestAvailableOrderRequest, pref allocator.Preference) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	states, err := u.seatRepo.ListSeatStatesByEvent(ctx, req.EventID, req.SectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	seats := make([]allocator.Seat, 0, len(states))
+	for _, s := range states {
+		seats = append(seats, allocator.Seat{
+			ID:         s.ID,
+			SectionID:  s.SectionID,
+			Row:        s.RowLabel,
+			Number:     s.SeatNumber,
+			Available:  s.Status == string(dto.SeatAvailable),
+			AisleAfter: s.AisleAfter,
+		})
+	}
+
+	picked, err := allocator.Allocate(seats, allocator.Request{
+		Quantity:   req.Quantity,
+		Preference: pref,
+		AllowSplit: req.AllowSplit,
+	})
+	if err != nil {
+		if err == allocator.ErrNotEnoughSeats {
+			return nil, errs.ErrNotEnoughSeats
+		}
+		return nil, err
+	}
+
+	seatIDs := make([]int64, len(picked))
+	for i, s := range picked {
+		seatIDs[i] = s.ID
+	}
+
+	return seatIDs, nil
+}
+
+func (u *orderUsecase) GetByID(ctx context.Context, id int64) (*dto.OrderResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	order, err := u.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := u.bookRepo.ListByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.OrderResponse{
+		ID:          order.ID,
+		UserID:      order.UserID,
+		EventID:     order.EventID,
+		Status:      order.Status,
+		ExpiresAt:   order.ExpiresAt,
+		CreatedAt:   order.CreatedAt,
+		ConfirmedAt: order.ConfirmedAt,
+		CancelledAt: order.CancelledAt,
+		Bookings:    bookings,
+	}, nil
+}
+
+func (u *orderUsecase) ConfirmOrder(ctx context.Context, orderID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		order, err := u.orderRepo.GetForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		switch order.Status {
+		case string(dto.StatusConfirmed):
+			return errs.ErrOrderAlreadyConfirmed
+		case string(dto.StatusCancelled):
+			return errs.ErrOrderAlreadyCancelled
+		case string(dto.StatusExpired):
+			return errs.ErrOrderHoldExpired
+		}
+
+		if order.ExpiresAt != nil && !order.ExpiresAt.After(time.Now()) {
+			return errs.ErrOrderHoldExpired
+		}
+
+		bookings, err := u.bookRepo.ListForUpdateByOrder(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		// check seats confirmed
+		for _, b := range bookings {
+			if b.Status != string(dto.StatusPending) {
+				return errs.ErrBookingNotPending
+			}
+
+			confirmed, err := u.bookRepo.IsSeatConfirmed(ctx, b.SeatID)
+			if err != nil {
+				return err
+			}
+			if confirmed {
+				return errs.ErrSeatAlreadyBooked
+			}
+		}
+
+		// confirmed order & bookings
+		if err = u.bookRepo.ConfirmByOrder(ctx, order.ID); err != nil {
+			return err
+		}
+
+		if err = u.orderRepo.Confirm(ctx, order.ID); err != nil {
+			return err
+		}
+
+		// cancel other booking
+		for _, b := range bookings {
+			if err = u.bookRepo.CancelOtherBooking(ctx, b.SeatID, b.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (u *orderUsecase) CancelOrder(ctx context.Context, orderID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		order, err := u.orderRepo.GetForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		switch order.Status {
+		case string(dto.StatusConfirmed):
+			return errs.ErrOrderAlreadyConfirmed
+		case string(dto.StatusCancelled):
+			return errs.ErrOrderAlreadyCancelled
+		case string(dto.StatusExpired):
+			return errs.ErrOrderHoldExpired
+		}
+
+		if err = u.bookRepo.CancelByOrder(ctx, order.ID); err != nil {
+			return err
+		}
+
+		return u.orderRepo.Cancel(ctx, order.ID)
+	})
+}
+
+func (u *orderUsecase) ExpireHolds(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.orderRepo.ExpireHolds(ctx)
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
+	"github.com/codepnw/go-ticket-booking/internal/helper/security"
+	"github.com/codepnw/go-ticket-booking/internal/mailer"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+const (
+	passwordResetTTL = time.Hour
+	mailTimeout      = 30 * time.Second
+)
+
+type PasswordUsecase interface {
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+	ChangePassword(ctx context.Context, userID int64, req *dto.ChangePasswordRequest) (string, string, error)
+}
+
+type passwordUsecase struct {
+	tx        database.TxManager
+	userRepo  repository.UserRepository
+	authRepo  repository.AuthRepository
+	resetRepo repository.PasswordResetRepository
+	mailer    mailer.Mailer
+	auth      auth.Auth
+	publicURL string
+}
+
+func NewPasswordUsecase(
+	tx database.TxManager,
+	userRepo repository.UserRepository,
+	authRepo repository.AuthRepository,
+	resetRepo repository.PasswordResetRepository,
+	mailer mailer.Mailer,
+	auth auth.Auth,
+	publicURL string,
+) PasswordUsecase {
+	return &passwordUsecase{
+		tx:        tx,
+		userRepo:  userRepo,
+		authRepo:  authRepo,
+		resetRepo: resetRepo,
+		mailer:    mailer,
+		auth:      auth,
+		publicURL: publicURL,
+	}
+}
+
+// ForgotPassword emails a reset link when the address belongs to a user.
+// It succeeds either way so callers cannot probe for accounts, and the mail
+// goes out in the background so the response time does not tell either.
+func (u *passwordUsecase) ForgotPassword(ctx context.Context, email string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	user, err := u.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	token, hash, err := security.NewToken()
+	if err != nil {
+		return err
+	}
+
+	// a new link replaces any earlier one
+	err = u.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := u.resetRepo.InvalidateForUser(ctx, user.ID); err != nil {
+			return err
+		}
+
+		return u.resetRepo.Create(ctx, &domain.PasswordReset{
+			UserID:    user.ID,
+			TokenHash: hash,
+			ExpiresAt: time.Now().Add(passwordResetTTL),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", u.publicURL, url.QueryEscape(token))
+	go sendMail(u.mailer, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to choose a new password. It works once and expires in %d minutes.\n\n%s\n\nIf you did not ask for this, you can ignore this email.",
+			user.FirstName, int(passwordResetTTL.Minutes()), link,
+		),
+	})
+
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token. The token and any
+// other outstanding ones are used up, and every token of the user is
+// revoked so all sessions have to log in again.
+func (u *passwordUsecase) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		reset, err := u.resetRepo.GetValidForUpdate(ctx, security.HashToken(req.Token))
+		if err != nil {
+			return err
+		}
+
+		user, err := u.userRepo.FindByID(ctx, reset.UserID)
+		if err != nil {
+			return err
+		}
+
+		return u.setPassword(ctx, user, req.NewPassword, time.Now())
+	})
+}
+
+// ChangePassword replaces the user's password after checking the current
+// one. Every session is revoked; the returned access and refresh tokens
+// belong to a new session for the caller's device.
+func (u *passwordUsecase) ChangePassword(ctx context.Context, userID int64, req *dto.ChangePasswordRequest) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	current, err := u.userRepo.GetPasswordHash(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := security.VerifyPassword(req.CurrentPassword, current); err != nil {
+		return "", "", errs.ErrWrongPassword
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return "", "", errs.ErrSamePassword
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	var accessToken, refreshToken string
+	err = u.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := u.setPassword(ctx, user, req.NewPassword, time.Now()); err != nil {
+			return err
+		}
+
+		accessToken, refreshToken, err = startSession(ctx, u.authRepo, &u.auth, user, &req.Device)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// setPassword checks the password policy, stores the new hash, and revokes
+// the user's reset links and sessions. Access tokens issued before
+// changedAt stop working through the auth revocation check.
+func (u *passwordUsecase) setPassword(ctx context.Context, user *domain.User, password string, changedAt time.Time) error {
+	if err := security.CheckPasswordPolicy(password, user.Email); err != nil {
+		return err
+	}
+
+	hashed, err := security.GenenrateHashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdatePassword(ctx, user.ID, hashed, changedAt); err != nil {
+		return err
+	}
+
+	if err := u.resetRepo.InvalidateForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	return u.authRepo.RevokeUserSessions(ctx, user.ID)
+}
+
+// sendMail delivers msg on its own deadline, so it can run after the
+// request that triggered it has finished. Failures are only logged.
+func sendMail(m mailer.Mailer, msg *mailer.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	if err := m.Send(ctx, msg); err != nil {
+		log.Printf("send mail to %s failed: %v", msg.To, err)
+	}
+}
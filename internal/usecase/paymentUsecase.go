@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/payment"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+// freeProvider marks payments for zero-priced bookings that never reach a provider.
+const freeProvider = "none"
+
+// paymentRefKey is the unique (provider, provider_ref) constraint of payments.
+const paymentRefKey = "payments_provider_ref_key"
+
+type PaymentUsecase interface {
+	PayBooking(ctx context.Context, bookingID int64) (*dto.PaymentResponse, error)
+	PayOrder(ctx context.Context, orderID int64) (*dto.PaymentResponse, error)
+	GetByID(ctx context.Context, id int64) (*dto.PaymentResponse, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+	SettlePayments(ctx context.Context) (int64, error)
+}
+
+type paymentUsecase struct {
+	tx        database.TxManager
+	payRepo   repository.PaymentRepository
+	bookRepo  repository.BookingRepository
+	orderRepo repository.OrderRepository
+	bookingUc BookingUsecase
+	orderUc   OrderUsecase
+	provider  payment.Provider
+	currency  string
+}
+
+func NewPaymentUsecase(
+	tx database.TxManager,
+	payRepo repository.PaymentRepository,
+	bookRepo repository.BookingRepository,
+	orderRepo repository.OrderRepository,
+	bookingUc BookingUsecase,
+	orderUc OrderUsecase,
+	provider payment.Provider,
+	currency string,
+) PaymentUsecase {
+	return &paymentUsecase{
+		tx:        tx,
+		payRepo:   payRepo,
+		bookRepo:  bookRepo,
+		orderRepo: orderRepo,
+		bookingUc: bookingUc,
+		orderUc:   orderUc,
+		provider:  provider,
+		currency:  currency,
+	}
+}
+
+func (u *paymentUsecase) PayBooking(ctx context.Context, bookingID int64) (*dto.PaymentResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	booking, err := u.bookRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrBookingNotFound
+		}
+		return nil, err
+	}
+
+	if booking.OrderID != nil {
+		return nil, errs.ErrBookingPartOfOrder
+	}
+
+	if err = checkPayable(booking.Status, booking.ExpiresAt, errs.ErrBookingHoldExpired); err != nil {
+		return nil, err
+	}
+
+	var amount float64
+	if booking.Price != nil {
+		amount = *booking.Price
+	}
+
+	p := &domain.Payment{
+		BookingID: &booking.ID,
+		Amount:    amount,
+	}
+
+	return u.startPayment(ctx, p, fmt.Sprintf("booking:%d", booking.ID))
+}
+
+func (u *paymentUsecase) PayOrder(ctx context.Context, orderID int64) (*dto.PaymentResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkPayable(order.Status, order.ExpiresAt, errs.ErrOrderHoldExpired); err != nil {
+		return nil, err
+	}
+
+	bookings, err := u.bookRepo.ListByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var amount float64
+	for _, b := range bookings {
+		if b.Price != nil {
+			amount += *b.Price
+		}
+	}
+
+	p := &domain.Payment{
+		OrderID: &order.ID,
+		Amount:  amount,
+	}
+
+	return u.startPayment(ctx, p, fmt.Sprintf("order:%d", order.ID))
+}
+
+func (u *paymentUsecase) GetByID(ctx context.Context, id int64) (*dto.PaymentResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	p, err := u.payRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPaymentResponse(p, ""), nil
+}
+
+// HandleWebhook applies a signed provider notification. A successful payment
+// confirms its booking or order; if that is no longer possible the money is
+// refunded. Repeated notifications for a settled payment are ignored.
+//
+// The booking change and the payment's next status are committed before the
+// provider is asked to capture or refund, so a failed transaction never
+// leaves money moved without a record. A retried webhook, or SettlePayments,
+// finishes a payment whose provider call did not go through.
+func (u *paymentUsecase) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := u.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	var p *domain.Payment
+
+	err = u.tx.WithTx(ctx, func(ctx context.Context) error {
+		p, err = u.payRepo.GetByProviderRefForUpdate(ctx, u.provider.Name(), event.IntentID)
+		if err != nil {
+			return err
+		}
+
+		if p.Status != string(dto.PaymentPending) {
+			return nil
+		}
+
+		switch event.Type {
+		case payment.EventFailed:
+			p.Status = string(dto.PaymentFailed)
+
+		case payment.EventAuthorized, payment.EventSucceeded:
+			if event.Amount != p.Amount {
+				return errs.ErrPaymentAmountMismatch
+			}
+
+			p.Status = string(dto.PaymentSucceeded)
+			if event.Type == payment.EventAuthorized {
+				p.Status = string(dto.PaymentCapturing)
+			}
+
+			if err := u.confirmTarget(ctx, p); err != nil {
+				if !isUnconfirmable(err) {
+					return err
+				}
+				// seat was lost while paying, give the money back
+				p.Status = string(dto.PaymentRefunding)
+			}
+
+		default:
+			return nil
+		}
+
+		return u.payRepo.UpdateStatus(ctx, p.ID, p.Status)
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.settle(ctx, p)
+}
+
+// SettlePayments retries the provider calls of payments left capturing or
+// refunding and returns the number settled.
+func (u *paymentUsecase) SettlePayments(ctx context.Context) (int64, error) {
+	listCtx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	list, err := u.payRepo.ListSettling(listCtx)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	var settled int64
+	for _, p := range list {
+		if err := u.settlePayment(ctx, p); err != nil {
+			return settled, fmt.Errorf("payment %d: %w", p.ID, err)
+		}
+		settled++
+	}
+
+	return settled, nil
+}
+
+func (u *paymentUsecase) settlePayment(ctx context.Context, p *domain.Payment) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.settle(ctx, p)
+}
+
+// settle makes the provider call a capturing or refunding payment is waiting
+// for, then records the outcome. The payment ID keys the calls, so settling
+// the same payment twice moves the money once.
+func (u *paymentUsecase) settle(ctx context.Context, p *domain.Payment) error {
+	var final dto.PaymentStatus
+
+	switch dto.PaymentStatus(p.Status) {
+	case dto.PaymentCapturing:
+		if _, err := u.provider.Capture(ctx, p.ProviderRef, fmt.Sprintf("payment:%d:capture", p.ID)); err != nil {
+			return err
+		}
+		final = dto.PaymentSucceeded
+
+	case dto.PaymentRefunding:
+		// an authorized payment has to be captured before it can be refunded
+		if _, err := u.provider.Capture(ctx, p.ProviderRef, fmt.Sprintf("payment:%d:capture", p.ID)); err != nil {
+			return err
+		}
+		if _, err := u.provider.Refund(ctx, p.ProviderRef, p.Amount, fmt.Sprintf("payment:%d:refund", p.ID)); err != nil {
+			return err
+		}
+		final = dto.PaymentRefunded
+
+	default:
+		return nil
+	}
+
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		locked, err := u.payRepo.GetByProviderRefForUpdate(ctx, p.Provider, p.ProviderRef)
+		if err != nil {
+			return err
+		}
+
+		// settled by a concurrent webhook or runner
+		if locked.Status != p.Status {
+			return nil
+		}
+
+		return u.payRepo.UpdateStatus(ctx, p.ID, string(final))
+	})
+}
+
+func (u *paymentUsecase) startPayment(ctx context.Context, p *domain.Payment, reference string) (*dto.PaymentResponse, error) {
+	p.Currency = u.currency
+
+	// nothing to collect, confirm right away
+	if p.Amount == 0 {
+		p.Provider = freeProvider
+		p.ProviderRef = reference
+		p.Status = string(dto.PaymentSucceeded)
+
+		err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+			if err := u.confirmTarget(ctx, p); err != nil {
+				return err
+			}
+			return u.payRepo.Create(ctx, p)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return newPaymentResponse(p, ""), nil
+	}
+
+	// one pending payment per booking or order
+	if pending, err := u.pendingPayment(ctx, p); pending != nil || err != nil {
+		return pending, err
+	}
+
+	intent, err := u.provider.CreateIntent(ctx, p.Amount, p.Currency, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Provider = u.provider.Name()
+	p.ProviderRef = intent.ID
+	p.Status = string(dto.PaymentPending)
+
+	if err = u.payRepo.Create(ctx, p); err != nil {
+		// a concurrent call got the same intent in first
+		if database.IsUniqueViolation(err, paymentRefKey) {
+			if pending, err := u.pendingPayment(ctx, p); pending != nil || err != nil {
+				return pending, err
+			}
+			return nil, errs.ErrPaymentPending
+		}
+		return nil, err
+	}
+
+	return newPaymentResponse(p, intent.ClientSecret), nil
+}
+
+// pendingPayment returns the pending payment already started for p's
+// booking or order together with errs.ErrPaymentPending, or nil if there is
+// none.
+func (u *paymentUsecase) pendingPayment(ctx context.Context, p *domain.Payment) (*dto.PaymentResponse, error) {
+	pending, err := u.payRepo.GetPending(ctx, p.BookingID, p.OrderID)
+	if err != nil {
+		if errors.Is(err, errs.ErrPaymentNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return newPaymentResponse(pending, ""), errs.ErrPaymentPending
+}
+
+func (u *paymentUsecase) confirmTarget(ctx context.Context, p *domain.Payment) error {
+	if p.OrderID != nil {
+		return u.orderUc.ConfirmOrder(ctx, *p.OrderID)
+	}
+	return u.bookingUc.ConfirmBooking(ctx, *p.BookingID)
+}
+
+func checkPayable(status string, expiresAt *time.Time, errExpired error) error {
+	switch status {
+	case string(dto.StatusPending):
+	case string(dto.StatusExpired):
+		return errExpired
+	default:
+		return errs.ErrBookingNotPending
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return errExpired
+	}
+
+	return nil
+}
+
+// isUnconfirmable reports whether the paid target can never be confirmed,
+// so the payment has to be refunded instead of retried.
+func isUnconfirmable(err error) bool {
+	switch err {
+	case errs.ErrBookingNotFound,
+		errs.ErrBookingPartOfOrder,
+		errs.ErrBookingHoldExpired,
+		errs.ErrBookingAlreadyConfirmed,
+		errs.ErrBookingAlreadyCancelled,
+		errs.ErrBookingNotPending,
+		errs.ErrSeatAlreadyBooked,
+		errs.ErrOrderNotFound,
+		errs.ErrOrderHoldExpired,
+		errs.ErrOrderAlreadyConfirmed,
+		errs.ErrOrderAlreadyCancelled:
+		return true
+	}
+	return false
+}
+
+func newPaymentResponse(p *domain.Payment, clientSecret string) *dto.PaymentResponse {
+	return &dto.PaymentResponse{
+		PaymentID:    p.ID,
+		BookingID:    p.BookingID,
+		OrderID:      p.OrderID,
+		Provider:     p.Provider,
+		ProviderRef:  p.ProviderRef,
+		ClientSecret: clientSecret,
+		Amount:       p.Amount,
+		Currency:     p.Currency,
+		Status:       p.Status,
+	}
+}
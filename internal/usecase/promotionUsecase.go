@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/promotion"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+type PromotionUsecase interface {
+	Create(ctx context.Context, req *dto.PromotionRequest) (*domain.Promotion, error)
+	GetByID(ctx context.Context, id int64) (*domain.Promotion, error)
+	List(ctx context.Context) ([]*domain.Promotion, error)
+	Update(ctx context.Context, id int64, req *dto.PromotionUpdateRequest) (*domain.Promotion, error)
+	Delete(ctx context.Context, id int64) error
+	Report(ctx context.Context, id int64) (*dto.PromotionReport, error)
+}
+
+type promotionUsecase struct {
+	repo      repository.PromotionRepository
+	eventRepo repository.EventRepository
+	ttRepo    repository.TicketTypeRepository
+}
+
+func NewPromotionUsecase(
+	repo repository.PromotionRepository,
+	eventRepo repository.EventRepository,
+	ttRepo repository.TicketTypeRepository,
+) PromotionUsecase {
+	return &promotionUsecase{
+		repo:      repo,
+		eventRepo: eventRepo,
+		ttRepo:    ttRepo,
+	}
+}
+
+func (u *promotionUsecase) Create(ctx context.Context, req *dto.PromotionRequest) (*domain.Promotion, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	p := &domain.Promotion{
+		Code:           promotion.NormalizeCode(req.Code),
+		Description:    req.Description,
+		DiscountType:   req.DiscountType,
+		Amount:         req.Amount,
+		EventID:        req.EventID,
+		TicketTypeID:   req.TicketTypeID,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+		Stackable:      req.Stackable,
+		Active:         true,
+	}
+	if req.Active != nil {
+		p.Active = *req.Active
+	}
+
+	if err := checkPromotion(p); err != nil {
+		return nil, err
+	}
+
+	// a ticket type scope implies its event
+	if p.TicketTypeID != nil {
+		tt, err := u.ttRepo.GetByID(ctx, *p.TicketTypeID)
+		if err != nil {
+			return nil, err
+		}
+		if p.EventID != nil && *p.EventID != tt.EventID {
+			return nil, errs.ErrInvalidTicketTypeEvent
+		}
+		p.EventID = &tt.EventID
+	} else if p.EventID != nil {
+		if _, err := u.eventRepo.GetEventByID(ctx, *p.EventID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (u *promotionUsecase) GetByID(ctx context.Context, id int64) (*domain.Promotion, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.GetByID(ctx, id)
+}
+
+func (u *promotionUsecase) List(ctx context.Context) ([]*domain.Promotion, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.List(ctx)
+}
+
+func (u *promotionUsecase) Update(ctx context.Context, id int64, req *dto.PromotionUpdateRequest) (*domain.Promotion, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	if req.Description == nil && req.Amount == nil && req.StartsAt == nil && req.EndsAt == nil &&
+		req.MaxUses == nil && req.MaxUsesPerUser == nil && req.Stackable == nil && req.Active == nil {
+		return nil, errs.ErrNoFieldsToUpdate
+	}
+
+	p, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		p.Description = *req.Description
+	}
+
+	if req.Amount != nil {
+		p.Amount = *req.Amount
+	}
+
+	if req.StartsAt != nil {
+		p.StartsAt = req.StartsAt
+	}
+
+	if req.EndsAt != nil {
+		p.EndsAt = req.EndsAt
+	}
+
+	if req.MaxUses != nil {
+		p.MaxUses = purchaseLimit(*req.MaxUses)
+	}
+
+	if req.MaxUsesPerUser != nil {
+		p.MaxUsesPerUser = purchaseLimit(*req.MaxUsesPerUser)
+	}
+
+	if req.Stackable != nil {
+		p.Stackable = *req.Stackable
+	}
+
+	if req.Active != nil {
+		p.Active = *req.Active
+	}
+
+	if err := checkPromotion(p); err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (u *promotionUsecase) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *promotionUsecase) Report(ctx context.Context, id int64) (*dto.PromotionReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.Report(ctx, id)
+}
+
+func checkPromotion(p *domain.Promotion) error {
+	if p.DiscountType == string(promotion.Percent) && p.Amount > 100 {
+		return errs.ErrInvalidPromotion
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return errs.ErrInvalidPromotion
+	}
+	return nil
+}
+
+// promoSet holds the promotions of one checkout, locked until its
+// transaction ends so usage caps hold under concurrent checkouts.
+type promoSet struct {
+	promotions []*domain.Promotion
+	applied    map[int64]bool
+}
+
+// loadPromotions looks up and locks the promo codes for a checkout of
+// eventID. Every code must exist, be active, be in its window and cover
+// the event; together they must be allowed to stack.
+func loadPromotions(ctx context.Context, repo repository.PromotionRepository, eventID int64, codes []string) (*promoSet, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	normalized := make([]string, len(codes))
+	for i, code := range codes {
+		normalized[i] = promotion.NormalizeCode(code)
+	}
+
+	promotions, err := repo.ListByCodesForUpdate(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if len(promotions) != len(normalized) {
+		return nil, errs.ErrInvalidPromoCode
+	}
+
+	now := time.Now()
+	discounts := make([]promotion.Discount, 0, len(promotions))
+	for _, p := range promotions {
+		if !p.Active || !p.InWindow(now) {
+			return nil, errs.ErrInvalidPromoCode
+		}
+		if p.EventID != nil && *p.EventID != eventID {
+			return nil, errs.ErrPromotionNotApplicable
+		}
+		discounts = append(discounts, promotionDiscount(p))
+	}
+
+	if err := promotion.CheckStacking(discounts); err != nil {
+		return nil, errs.ErrPromotionNotStackable
+	}
+
+	return &promoSet{
+		promotions: promotions,
+		applied:    make(map[int64]bool, len(promotions)),
+	}, nil
+}
+
+// forTicketType returns the promotions that cover the ticket type.
+func (s *promoSet) forTicketType(ticketTypeID *int64) []*domain.Promotion {
+	var out []*domain.Promotion
+	for _, p := range s.promotions {
+		if p.TicketTypeID == nil || sameTicketType(p.TicketTypeID, ticketTypeID) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// checkApplied fails when a code did not discount any ticket of the
+// checkout, so customers are not silently charged full price.
+func (s *promoSet) checkApplied() error {
+	if s == nil {
+		return nil
+	}
+	for _, p := range s.promotions {
+		if !s.applied[p.ID] {
+			return errs.ErrPromotionNotApplicable
+		}
+	}
+	return nil
+}
+
+func promotionDiscount(p *domain.Promotion) promotion.Discount {
+	return promotion.Discount{
+		ID:        p.ID,
+		Type:      promotion.DiscountType(p.DiscountType),
+		Amount:    p.Amount,
+		Stackable: p.Stackable,
+	}
+}
@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/payment"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+const (
+	// maxRefundAttempts is how often a refund is tried at the provider
+	// before it is left failed for an admin.
+	maxRefundAttempts = 10
+	refundBatchSize   = 100
+)
+
+// RefundUsecase returns money on confirmed bookings. A refund is recorded as
+// pending together with the booking change, and paid out by the provider
+// after that commits; pending refunds are retried by RetryRefunds.
+type RefundUsecase interface {
+	RefundBooking(ctx context.Context, bookingID, userID int64) (*domain.Refund, error)
+	ForceRefund(ctx context.Context, bookingID, adminID int64, req *dto.ForceRefundRequest) (*domain.Refund, error)
+	ListByBooking(ctx context.Context, bookingID int64) ([]*domain.Refund, error)
+	RetryRefunds(ctx context.Context) (int64, error)
+}
+
+type refundUsecase struct {
+	tx         database.TxManager
+	bookRepo   repository.BookingRepository
+	eventRepo  repository.EventRepository
+	payRepo    repository.PaymentRepository
+	refundRepo repository.RefundRepository
+	provider   payment.Provider
+}
+
+func NewRefundUsecase(
+	tx database.TxManager,
+	bookRepo repository.BookingRepository,
+	eventRepo repository.EventRepository,
+	payRepo repository.PaymentRepository,
+	refundRepo repository.RefundRepository,
+	provider payment.Provider,
+) RefundUsecase {
+	return &refundUsecase{
+		tx:         tx,
+		bookRepo:   bookRepo,
+		eventRepo:  eventRepo,
+		payRepo:    payRepo,
+		refundRepo: refundRepo,
+		provider:   provider,
+	}
+}
+
+// RefundBooking cancels a customer's confirmed booking and returns the share
+// of the price allowed by the event's refund policy.
+func (u *refundUsecase) RefundBooking(ctx context.Context, bookingID, userID int64) (*domain.Refund, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	var refund *domain.Refund
+
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		b, err := u.lockConfirmed(ctx, bookingID)
+		if err != nil {
+			return err
+		}
+
+		if b.UserID != userID {
+			return errs.ErrBookingNotFound
+		}
+
+		event, err := u.eventRepo.GetEventByID(ctx, b.EventID)
+		if err != nil {
+			return err
+		}
+
+		percent := event.RefundPercent(time.Now())
+		if percent == 0 {
+			return errs.ErrRefundWindowClosed
+		}
+
+		refund = &domain.Refund{
+			BookingID: b.ID,
+			Amount:    refundAmount(b.Price, percent),
+			Percent:   percent,
+			Reason:    "cancelled by customer",
+			CreatedBy: &userID,
+		}
+
+		return u.reserve(ctx, b, refund)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, u.payOut(ctx, refund)
+}
+
+// ForceRefund lets an admin refund a confirmed booking regardless of the
+// event's policy. Without an amount the full price is returned.
+func (u *refundUsecase) ForceRefund(ctx context.Context, bookingID, adminID int64, req *dto.ForceRefundRequest) (*domain.Refund, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	var refund *domain.Refund
+
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		b, err := u.lockConfirmed(ctx, bookingID)
+		if err != nil {
+			return err
+		}
+
+		refund = &domain.Refund{
+			BookingID: b.ID,
+			Amount:    refundAmount(b.Price, 100),
+			Percent:   100,
+			Reason:    req.Reason,
+			Forced:    true,
+			CreatedBy: &adminID,
+		}
+
+		if req.Amount != nil {
+			full := refund.Amount
+			if *req.Amount > full {
+				return errs.ErrRefundExceedsPaid
+			}
+			refund.Amount = *req.Amount
+			if full > 0 {
+				refund.Percent = int(math.Round(refund.Amount / full * 100))
+			}
+		}
+
+		return u.reserve(ctx, b, refund)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, u.payOut(ctx, refund)
+}
+
+func (u *refundUsecase) ListByBooking(ctx context.Context, bookingID int64) ([]*domain.Refund, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.refundRepo.ListByBooking(ctx, bookingID)
+}
+
+// RetryRefunds pays out refunds whose provider call failed before and
+// returns the number paid out.
+func (u *refundUsecase) RetryRefunds(ctx context.Context) (int64, error) {
+	listCtx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	list, err := u.refundRepo.ListPending(listCtx, refundBatchSize)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	var paid int64
+	for _, refund := range list {
+		refundCtx, cancel := context.WithTimeout(ctx, queryTimeOut)
+		err := u.payOut(refundCtx, refund)
+		cancel()
+		if err != nil {
+			return paid, fmt.Errorf("refund %d: %w", refund.ID, err)
+		}
+		if refund.Status == string(dto.RefundSucceeded) {
+			paid++
+		}
+	}
+
+	return paid, nil
+}
+
+func (u *refundUsecase) lockConfirmed(ctx context.Context, bookingID int64) (*domain.Booking, error) {
+	b, err := u.bookRepo.GetForUpdate(ctx, bookingID)
+	if err != nil {
+		return nil, errs.ErrBookingNotFound
+	}
+
+	if b.Status != string(dto.StatusConfirmed) {
+		return nil, errs.ErrBookingNotConfirmed
+	}
+
+	return b, nil
+}
+
+// reserve takes the refund off the payment, releases the seat and records
+// the refund, all inside the caller's transaction. Refunds that return money
+// are recorded pending; payOut sends them to the provider after the commit.
+func (u *refundUsecase) reserve(ctx context.Context, b *domain.Booking, refund *domain.Refund) error {
+	p, err := u.payRepo.GetSettledForUpdate(ctx, b.ID, b.OrderID)
+	if err != nil && !errors.Is(err, errs.ErrPaymentNotFound) {
+		return err
+	}
+
+	if p == nil && refund.Amount > 0 {
+		return errs.ErrPaymentNotFound
+	}
+
+	refund.Status = string(dto.RefundSucceeded)
+
+	if p != nil {
+		refund.PaymentID = &p.ID
+
+		if refund.Amount > 0 {
+			if p.Provider != u.provider.Name() {
+				return fmt.Errorf("payment %d was collected by provider %s", p.ID, p.Provider)
+			}
+
+			// reserve the amount first so a payment is never refunded twice
+			if err := u.payRepo.AddRefunded(ctx, p.ID, refund.Amount); err != nil {
+				return err
+			}
+			refund.Status = string(dto.RefundPending)
+		}
+	}
+
+	if err := u.bookRepo.Refund(ctx, b.ID); err != nil {
+		return err
+	}
+
+	return u.refundRepo.Create(ctx, refund)
+}
+
+// payOut asks the provider for a pending refund and records the outcome. The
+// refund ID is the idempotency key, so paying out the same refund again
+// never returns the money twice. A provider error leaves the refund pending
+// for RetryRefunds and is not returned; it must not be called inside a
+// transaction.
+func (u *refundUsecase) payOut(ctx context.Context, refund *domain.Refund) error {
+	if refund.Status != string(dto.RefundPending) || refund.PaymentID == nil {
+		return nil
+	}
+
+	p, err := u.payRepo.GetByID(ctx, *refund.PaymentID)
+	if err != nil {
+		return err
+	}
+
+	r, err := u.provider.Refund(ctx, p.ProviderRef, refund.Amount, fmt.Sprintf("refund:%d", refund.ID))
+	if err != nil {
+		msg := err.Error()
+		refund.Attempts++
+		refund.LastError = &msg
+		if refund.Attempts >= maxRefundAttempts {
+			refund.Status = string(dto.RefundFailed)
+		}
+		return u.refundRepo.MarkAttemptFailed(ctx, refund.ID, msg, maxRefundAttempts)
+	}
+
+	if err := u.refundRepo.MarkSucceeded(ctx, refund.ID, r.ID); err != nil {
+		return err
+	}
+
+	refund.Status = string(dto.RefundSucceeded)
+	refund.ProviderRef = &r.ID
+	refund.LastError = nil
+	return nil
+}
+
+func refundAmount(price *float64, percent int) float64 {
+	if price == nil {
+		return 0
+	}
+	return math.Round(*price*float64(percent)) / 100
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+)
+
+const (
+	maxGeneratedRows  = 200
+	maxGeneratedSeats = 10000
+)
+
+// planSeats expands a generate request into the seats to insert. It returns
+// the row labels used and how many seats the number and seat skip lists
+// removed.
+func planSeats(req *dto.GenerateSeatsRequest) ([]*domain.Seat, []string, int, error) {
+	rows, err := expandRowLabels(req.RowFrom, req.RowTo)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	skipRows := map[string]bool{}
+	for _, r := range req.SkipRows {
+		skipRows[strings.ToUpper(r)] = true
+	}
+	skipNumbers := intSet(req.SkipNumbers)
+	skipSeats := map[dto.SeatPosition]bool{}
+	for _, p := range req.SkipSeats {
+		p.RowLabel = strings.ToUpper(p.RowLabel)
+		skipSeats[p] = true
+	}
+
+	var seats []*domain.Seat
+	var used []string
+	skipped := 0
+
+	for _, row := range rows {
+		if skipRows[row] {
+			continue
+		}
+		used = append(used, row)
+
+		for n := req.SeatFrom; n <= req.SeatTo; n++ {
+			if !numberingMatches(req.Numbering, n) {
+				continue
+			}
+			if skipNumbers[n] || skipSeats[dto.SeatPosition{RowLabel: row, SeatNumber: n}] {
+				skipped++
+				continue
+			}
+
+			seats = append(seats, &domain.Seat{
+				SectionID:    req.SectionID,
+				RowLabel:     row,
+				SeatNumber:   n,
+				IsAvailable:  true,
+				TicketTypeID: req.TicketTypeID,
+			})
+			if len(seats) > maxGeneratedSeats {
+				return nil, nil, 0, invalidSeatSpec("more than %d seats", maxGeneratedSeats)
+			}
+		}
+	}
+
+	if len(seats) == 0 {
+		return nil, nil, 0, invalidSeatSpec("no seats left after skip rules")
+	}
+
+	return seats, used, skipped, nil
+}
+
+func numberingMatches(numbering dto.SeatNumbering, n int) bool {
+	switch numbering {
+	case dto.NumberingOdd:
+		return n%2 == 1
+	case dto.NumberingEven:
+		return n%2 == 0
+	default:
+		return true
+	}
+}
+
+// expandRowLabels lists the rows from..to inclusive. Numeric labels count
+// up as numbers, letter labels go A..Z, AA..AZ and so on.
+func expandRowLabels(from, to string) ([]string, error) {
+	from = strings.ToUpper(strings.TrimSpace(from))
+	to = strings.ToUpper(strings.TrimSpace(to))
+
+	var first, last int
+	var label func(int) string
+
+	if a, err := strconv.Atoi(from); err == nil {
+		b, err := strconv.Atoi(to)
+		if err != nil || a < 0 {
+			return nil, invalidSeatSpec("rows %q-%q must both be numbers or both letters", from, to)
+		}
+		first, last, label = a, b, strconv.Itoa
+	} else {
+		a, okA := letterRowIndex(from)
+		b, okB := letterRowIndex(to)
+		if !okA || !okB {
+			return nil, invalidSeatSpec("rows %q-%q must both be numbers or both letters", from, to)
+		}
+		first, last, label = a, b, letterRowLabel
+	}
+
+	if last < first {
+		return nil, invalidSeatSpec("row %q comes after %q", from, to)
+	}
+	if last-first+1 > maxGeneratedRows {
+		return nil, invalidSeatSpec("more than %d rows", maxGeneratedRows)
+	}
+
+	rows := make([]string, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		rows = append(rows, label(i))
+	}
+
+	return rows, nil
+}
+
+// letterRowIndex maps A=1 .. Z=26, AA=27 and so on.
+func letterRowIndex(label string) (int, bool) {
+	if label == "" || len(label) > 4 {
+		return 0, false
+	}
+
+	n := 0
+	for _, c := range label {
+		if c < 'A' || c > 'Z' {
+			return 0, false
+		}
+		n = n*26 + int(c-'A'+1)
+	}
+	return n, true
+}
+
+func letterRowLabel(n int) string {
+	var b []byte
+	for n > 0 {
+		n--
+		b = append([]byte{byte('A' + n%26)}, b...)
+		n /= 26
+	}
+	return string(b)
+}
+
+func invalidSeatSpec(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errs.ErrInvalidSeatSpec, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/promotion"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+// seatHolder places pending holds on seats. It is shared by the single
+// booking and order flows and must be called inside a transaction.
+type seatHolder struct {
+	bookRepo  repository.BookingRepository
+	seatRepo  repository.SeatRepository
+	sectRepo  repository.SectionRepository
+	ttRepo    repository.TicketTypeRepository
+	eventRepo repository.EventRepository
+	promoRepo repository.PromotionRepository
+	userRepo  repository.UserRepository
+
+	// allowSoldOut also accepts sold out events, whose freed seats only
+	// go to the waitlist.
+	allowSoldOut bool
+}
+
+func newSeatHolder(
+	bookRepo repository.BookingRepository,
+	seatRepo repository.SeatRepository,
+	sectRepo repository.SectionRepository,
+	ttRepo repository.TicketTypeRepository,
+	eventRepo repository.EventRepository,
+	promoRepo repository.PromotionRepository,
+	userRepo repository.UserRepository,
+) *seatHolder {
+	return &seatHolder{
+		bookRepo:  bookRepo,
+		seatRepo:  seatRepo,
+		sectRepo:  sectRepo,
+		ttRepo:    ttRepo,
+		eventRepo: eventRepo,
+		promoRepo: promoRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// checkOnSale verifies the event is on sale and inside its sales window, and
+// that the user has verified their email if the event asks for it. The
+// event row stays share-locked so its status cannot change mid-booking.
+func (h *seatHolder) checkOnSale(ctx context.Context, eventID, userID int64) error {
+	event, err := h.eventRepo.GetEventForShare(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	open := event.Status == string(dto.EventOnSale) ||
+		(h.allowSoldOut && event.Status == string(dto.EventSoldOut))
+	if !open || !event.InSalesWindow(time.Now()) {
+		return errs.ErrEventNotOnSale
+	}
+
+	if event.RequireVerifiedEmail {
+		verified, err := h.userRepo.IsEmailVerified(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !verified {
+			return errs.ErrEmailNotVerified
+		}
+	}
+
+	return nil
+}
+
+// checkSeat verifies the seat exists, belongs to the event and is not held or
+// booked by someone else.
+func (h *seatHolder) checkSeat(ctx context.Context, seatID, eventID int64) (*domain.Seat, *domain.Section, error) {
+	// get seat
+	seat, err := h.seatRepo.GetSeatByID(ctx, seatID)
+	if err != nil {
+		return nil, nil, errs.ErrSeatNotFound
+	}
+
+	// get section & check event ownership
+	section, err := h.sectRepo.GetByID(ctx, seat.SectionID)
+	if err != nil {
+		return nil, nil, errs.ErrSectionNotFound
+	}
+
+	if section.EventID != eventID {
+		return nil, nil, errs.ErrInvalidSeatEvent
+	}
+
+	// check seat available
+	isAvailable, err := h.bookRepo.IsAvailable(ctx, seat.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !isAvailable {
+		return nil, nil, errs.ErrSeatAlreadyBooked
+	}
+
+	return seat, section, nil
+}
+
+// hold creates a pending booking for b.SeatID until expiresAt, taking one
+// unit of the seat's ticket type inventory and recording its price less
+// any promotions that cover it.
+func (h *seatHolder) hold(ctx context.Context, b *domain.Booking, expiresAt time.Time, promos *promoSet) (*domain.Booking, error) {
+	seat, section, err := h.checkSeat(ctx, b.SeatID, b.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.bookRepo.ExpireSeatHold(ctx, seat.ID); err != nil {
+		return nil, err
+	}
+
+	var tt *domain.TicketType
+	if ttID := resolveTicketType(seat, section); ttID != nil {
+		tt, err = h.ttRepo.Reserve(ctx, *ttID)
+		if err != nil {
+			return nil, err
+		}
+		if tt.EventID != b.EventID {
+			return nil, errs.ErrInvalidTicketTypeEvent
+		}
+
+		b.TicketTypeID = &tt.ID
+		b.Price = &tt.Price
+	}
+
+	if err = h.checkLimits(ctx, b, tt); err != nil {
+		return nil, err
+	}
+
+	lines, err := h.discount(ctx, b, promos)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Status = string(dto.StatusPending)
+	b.ExpiresAt = &expiresAt
+
+	// unique_active_seat_booking rejects concurrent holds on the same seat
+	if err = h.bookRepo.Create(ctx, b); err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		err := h.promoRepo.CreateRedemption(ctx, &domain.PromotionRedemption{
+			PromotionID: line.ID,
+			BookingID:   b.ID,
+			UserID:      b.UserID,
+			Discount:    line.Amount,
+		})
+		if err != nil {
+			return nil, err
+		}
+		promos.applied[line.ID] = true
+	}
+
+	return b, nil
+}
+
+// discount takes the promotions covering the booking's ticket type off its
+// price, after checking their usage caps.
+func (h *seatHolder) discount(ctx context.Context, b *domain.Booking, promos *promoSet) ([]promotion.Line, error) {
+	if promos == nil || b.Price == nil {
+		return nil, nil
+	}
+
+	applicable := promos.forTicketType(b.TicketTypeID)
+	if len(applicable) == 0 {
+		return nil, nil
+	}
+
+	discounts := make([]promotion.Discount, 0, len(applicable))
+	for _, p := range applicable {
+		if p.MaxUses != nil || p.MaxUsesPerUser != nil {
+			total, user, err := h.promoRepo.CountUses(ctx, p.ID, b.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if (p.MaxUses != nil && total >= *p.MaxUses) ||
+				(p.MaxUsesPerUser != nil && user >= *p.MaxUsesPerUser) {
+				return nil, errs.ErrPromotionUsageLimit
+			}
+		}
+		discounts = append(discounts, promotionDiscount(p))
+	}
+
+	res, err := promotion.Apply(*b.Price, discounts)
+	if err != nil {
+		return nil, errs.ErrPromotionNotStackable
+	}
+
+	b.Price = &res.Price
+	b.Discount = res.Discount
+	return res.Lines, nil
+}
+
+// checkLimits enforces the event's and the ticket type's per-user limits.
+// Holds placed earlier in the same transaction count too, so a multi-seat
+// order cannot go over the limit either.
+func (h *seatHolder) checkLimits(ctx context.Context, b *domain.Booking, tt *domain.TicketType) error {
+	event, err := h.eventRepo.GetEventByID(ctx, b.EventID)
+	if err != nil {
+		return err
+	}
+
+	var ttLimit *int
+	if tt != nil {
+		ttLimit = tt.MaxPerUser
+	}
+	if event.MaxTicketsPerUser == nil && ttLimit == nil {
+		return nil
+	}
+
+	if err := h.bookRepo.LockUserEvent(ctx, b.UserID, b.EventID); err != nil {
+		return err
+	}
+
+	eventCount, ttCount, err := h.bookRepo.CountActiveByUser(ctx, b.UserID, b.EventID, b.TicketTypeID)
+	if err != nil {
+		return err
+	}
+
+	if event.MaxTicketsPerUser != nil && eventCount >= *event.MaxTicketsPerUser {
+		return errs.ErrEventTicketLimit
+	}
+	if ttLimit != nil && ttCount >= *ttLimit {
+		return errs.ErrTicketTypeLimit
+	}
+
+	return nil
+}
+
+// resolveTicketType returns the seat's ticket type, falling back to the
+// section's.
+func resolveTicketType(seat *domain.Seat, section *domain.Section) *int64 {
+	if seat.TicketTypeID != nil {
+		return seat.TicketTypeID
+	}
+	return section.TicketTypeID
+}
+
+func sameTicketType(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/waitingroom"
+)
+
+// openTestDB connects to the migrated database in TEST_DB_ADDR, skipping the
+// test when it is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	addr := os.Getenv("TEST_DB_ADDR")
+	if addr == "" {
+		t.Skip("TEST_DB_ADDR not set")
+	}
+
+	db, err := database.InitPostgresDB(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+type holdFixture struct {
+	userIDs []int64
+	eventID int64
+	seatID  int64
+}
+
+// seedHoldFixture creates an on-sale event with a single seat and the given
+// number of users, and removes them when the test ends.
+func seedHoldFixture(t *testing.T, db *sql.DB, users int) *holdFixture {
+	t.Helper()
+
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	f := &holdFixture{}
+
+	for i := 0; i < users; i++ {
+		var id int64
+		err := db.QueryRowContext(ctx, `
+			INSERT INTO users (email, password, first_name, last_name, phone)
+			VALUES ($1, 'x', 'Test', 'User', '0000000000') RETURNING id
+		`, fmt.Sprintf("hold-%d-%d@example.com", suffix, i)).Scan(&id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.userIDs = append(f.userIDs, id)
+	}
+
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO events (name, start_time, end_time, status)
+		VALUES ('concurrent hold', NOW() + INTERVAL '1 day', NOW() + INTERVAL '2 days', 'on_sale') RETURNING id
+	`).Scan(&f.eventID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sectionID int64
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO sections (event_id, name, seat_count) VALUES ($1, 'A', 1) RETURNING id
+	`, f.eventID).Scan(&sectionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO seats (section_id, row_label, seat_number) VALUES ($1, 'A', 1) RETURNING id
+	`, sectionID).Scan(&f.seatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM bookings WHERE seat_id = $1`, f.seatID)
+		db.Exec(`DELETE FROM seats WHERE id = $1`, f.seatID)
+		db.Exec(`DELETE FROM events WHERE id = $1`, f.eventID)
+		for _, id := range f.userIDs {
+			db.Exec(`DELETE FROM users WHERE id = $1`, id)
+		}
+	})
+
+	return f
+}
+
+// TestSeatHolderConcurrentHold races many users booking the same seat through
+// the booking flow, with the winner confirming while the rest still try to
+// hold it. Exactly one booking may end up active.
+func TestSeatHolderConcurrentHold(t *testing.T) {
+	const users = 24
+
+	db := openTestDB(t)
+	f := seedHoldFixture(t, db, users)
+
+	tx := database.NewSqlTxManager(db)
+	eventRepo := repository.NewEventRepository(db)
+	gate := NewWaitingRoomUsecase(tx, repository.NewQueueRepository(db), eventRepo, waitingroom.NewSigner("test"))
+	bookingUc := NewBookingUsecase(
+		tx,
+		repository.NewBookingRepository(db),
+		repository.NewSeatRepository(db),
+		repository.NewSectionRepository(db),
+		repository.NewTicketTypeRepository(db),
+		eventRepo,
+		repository.NewPromotionRepository(db),
+		repository.NewUserRepository(db),
+		gate,
+	)
+
+	var (
+		wg        sync.WaitGroup
+		start     = make(chan struct{})
+		errCh     = make(chan error, users)
+		confirmCh = make(chan error, users)
+	)
+
+	for _, userID := range f.userIDs {
+		wg.Add(1)
+		go func(userID int64) {
+			defer wg.Done()
+			<-start
+
+			ctx := context.Background()
+			err := bookingUc.Create(ctx, &dto.CreateBookingRequest{
+				UserID:  userID,
+				EventID: f.eventID,
+				SeatID:  f.seatID,
+			})
+			errCh <- err
+			if err != nil {
+				return
+			}
+
+			var bookingID int64
+			err = db.QueryRowContext(ctx, `
+				SELECT id FROM bookings WHERE user_id = $1 AND seat_id = $2 AND status = 'pending'
+			`, userID, f.seatID).Scan(&bookingID)
+			if err != nil {
+				confirmCh <- err
+				return
+			}
+			confirmCh <- bookingUc.ConfirmBooking(ctx, bookingID)
+		}(userID)
+	}
+
+	close(start)
+	wg.Wait()
+	close(errCh)
+	close(confirmCh)
+
+	var held, rejected int
+	for err := range errCh {
+		switch {
+		case err == nil:
+			held++
+		case errors.Is(err, errs.ErrSeatAlreadyBooked):
+			rejected++
+		default:
+			t.Fatalf("unexpected booking error: %v", err)
+		}
+	}
+
+	if held != 1 || rejected != users-1 {
+		t.Fatalf("got %d holds and %d rejections, want 1 and %d", held, rejected, users-1)
+	}
+
+	for err := range confirmCh {
+		if err != nil {
+			t.Fatalf("confirm: %v", err)
+		}
+	}
+
+	var active, confirmed int
+	err := db.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE status = 'confirmed')
+		FROM bookings WHERE seat_id = $1 AND status IN ('pending', 'confirmed')
+	`, f.seatID).Scan(&active, &confirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != 1 || confirmed != 1 {
+		t.Fatalf("got %d active and %d confirmed bookings on the seat, want 1 and 1", active, confirmed)
+	}
+}
@@ -0,0 +1,462 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/seatmap"
+)
+
+type SeatMapUsecase interface {
+	Export(ctx context.Context, eventID int64) (*seatmap.Map, error)
+	Import(ctx context.Context, eventID int64, m *seatmap.Map, req *dto.SeatMapImportRequest) (*dto.SeatMapImportReport, error)
+	View(ctx context.Context, eventID int64) (*seatmap.View, string, error)
+	SVG(ctx context.Context, eventID int64) ([]byte, string, error)
+}
+
+// maxCachedSeatMaps bounds the SVG cache; it is emptied when full.
+const maxCachedSeatMaps = 256
+
+// svgCache keeps the last rendered SVG per event. Entries are checked
+// against the ETag of the current seat states, so any booking or seat change
+// makes the next request render again.
+type svgCache struct {
+	mu      sync.Mutex
+	entries map[int64]svgEntry
+}
+
+type svgEntry struct {
+	etag string
+	svg  []byte
+}
+
+func (c *svgCache) get(eventID int64, etag string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[eventID]
+	if !ok || e.etag != etag {
+		return nil, false
+	}
+	return e.svg, true
+}
+
+func (c *svgCache) put(eventID int64, etag string, svg []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCachedSeatMaps {
+		clear(c.entries)
+	}
+	c.entries[eventID] = svgEntry{etag: etag, svg: svg}
+}
+
+type seatMapUsecase struct {
+	tx        database.TxManager
+	eventRepo repository.EventRepository
+	sectRepo  repository.SectionRepository
+	seatRepo  repository.SeatRepository
+	ttRepo    repository.TicketTypeRepository
+	cache     *svgCache
+}
+
+func NewSeatMapUsecase(
+	tx database.TxManager,
+	eventRepo repository.EventRepository,
+	sectRepo repository.SectionRepository,
+	seatRepo repository.SeatRepository,
+	ttRepo repository.TicketTypeRepository,
+) SeatMapUsecase {
+	return &seatMapUsecase{
+		tx:        tx,
+		eventRepo: eventRepo,
+		sectRepo:  sectRepo,
+		seatRepo:  seatRepo,
+		ttRepo:    ttRepo,
+		cache:     &svgCache{entries: map[int64]svgEntry{}},
+	}
+}
+
+// Export returns the event's seats in insertion order, so importing the
+// result into an empty event and exporting again gives the same map.
+func (u *seatMapUsecase) Export(ctx context.Context, eventID int64) (*seatmap.Map, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	if _, err := u.eventRepo.GetEventByID(ctx, eventID); err != nil {
+		return nil, err
+	}
+
+	sections, err := u.sectRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketTypes, err := u.ttRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	categories := make(map[int64]string, len(ticketTypes))
+	for _, tt := range ticketTypes {
+		categories[tt.ID] = tt.Name
+	}
+
+	m := &seatmap.Map{Version: seatmap.Version, Seats: []seatmap.Seat{}}
+	for _, section := range sections {
+		seats, err := u.seatRepo.GetSeatsBySectionID(ctx, section.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range seats {
+			category := ""
+			if s.TicketTypeID != nil {
+				category = categories[*s.TicketTypeID]
+			}
+
+			m.Seats = append(m.Seats, seatmap.Seat{
+				Section:    section.Name,
+				Row:        s.RowLabel,
+				Number:     s.SeatNumber,
+				Category:   category,
+				X:          s.X,
+				Y:          s.Y,
+				Accessible: s.IsAccessible,
+				AisleAfter: s.AisleAfter,
+				Blocked:    !s.IsAvailable,
+			})
+		}
+	}
+
+	return m, nil
+}
+
+// View builds the laid out seat map with live seat states, from the same
+// booking rules GetAvailableSeatsByEvent uses. The returned ETag changes
+// whenever anything on the map does.
+func (u *seatMapUsecase) View(ctx context.Context, eventID int64) (*seatmap.View, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	if _, err := u.eventRepo.GetEventByID(ctx, eventID); err != nil {
+		return nil, "", err
+	}
+
+	sections, err := u.sectRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	states, err := u.seatRepo.ListSeatStatesByEvent(ctx, eventID, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	bySection := map[int64][]seatmap.ViewSeat{}
+	for _, s := range states {
+		seat := seatmap.ViewSeat{
+			ID:         s.ID,
+			Row:        s.RowLabel,
+			Number:     s.SeatNumber,
+			Status:     s.Status,
+			Accessible: s.IsAccessible,
+		}
+		if s.X != nil && s.Y != nil {
+			seat.X, seat.Y, seat.Positioned = *s.X, *s.Y, true
+		}
+		bySection[s.SectionID] = append(bySection[s.SectionID], seat)
+	}
+
+	view := &seatmap.View{EventID: eventID, Sections: []seatmap.ViewSection{}}
+	for _, section := range sections {
+		view.Sections = append(view.Sections, seatmap.ViewSection{
+			ID:    section.ID,
+			Name:  section.Name,
+			Seats: bySection[section.ID],
+		})
+	}
+	view.Layout()
+
+	body, err := json.Marshal(view)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(body)
+
+	return view, fmt.Sprintf(`"%x"`, sum[:16]), nil
+}
+
+// SVG renders the seat map, reusing the last rendering while the ETag is
+// unchanged.
+func (u *seatMapUsecase) SVG(ctx context.Context, eventID int64) ([]byte, string, error) {
+	view, etag, err := u.View(ctx, eventID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if svg, ok := u.cache.get(eventID, etag); ok {
+		return svg, etag, nil
+	}
+
+	var buf bytes.Buffer
+	if err := seatmap.WriteSVG(&buf, view); err != nil {
+		return nil, "", err
+	}
+
+	u.cache.put(eventID, etag, buf.Bytes())
+	return buf.Bytes(), etag, nil
+}
+
+// seatMapPlan is what an import will write.
+type seatMapPlan struct {
+	newSections []*domain.Section
+	// new seats in map order; SectionID is set from sectionIDs once every
+	// section exists
+	newSeats   []*domain.Seat
+	seatNames  []string
+	sectionIDs map[string]int64
+	updates    []*domain.Seat
+}
+
+// Import creates missing sections and seats from the map. Existing seats are
+// conflicts unless req.OnConflict is update. Nothing is written on a dry run
+// or when there is any conflict; the report lists what would happen.
+func (u *seatMapUsecase) Import(ctx context.Context, eventID int64, m *seatmap.Map, req *dto.SeatMapImportRequest) (*dto.SeatMapImportReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	report := &dto.SeatMapImportReport{
+		DryRun:          req.DryRun,
+		SectionsCreated: []string{},
+		Conflicts:       []*dto.SeatMapConflict{},
+	}
+
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		// serializes imports and seat generation for the event
+		event, err := u.eventRepo.GetEventForUpdate(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		if event.Status == string(dto.EventCancelled) {
+			return errs.ErrEventAlreadyCancelled
+		}
+
+		plan, err := u.planImport(ctx, event, m, req.OnConflict, report)
+		if err != nil {
+			return err
+		}
+
+		if len(report.Conflicts) > 0 {
+			if req.DryRun {
+				return nil
+			}
+			return errs.ErrSeatMapConflicts
+		}
+
+		if req.DryRun {
+			return nil
+		}
+
+		for _, section := range plan.newSections {
+			if err := u.sectRepo.Create(ctx, section); err != nil {
+				return err
+			}
+			plan.sectionIDs[section.Name] = section.ID
+		}
+
+		for i, s := range plan.newSeats {
+			s.SectionID = plan.sectionIDs[plan.seatNames[i]]
+		}
+		if len(plan.newSeats) > 0 {
+			if err := u.seatRepo.CreateBatch(ctx, plan.newSeats); err != nil {
+				return err
+			}
+		}
+
+		for _, s := range plan.updates {
+			if err := u.seatRepo.UpdateSeat(ctx, s); err != nil {
+				return err
+			}
+		}
+
+		report.Applied = true
+		return nil
+	})
+	if err != nil {
+		if err == errs.ErrSeatMapConflicts {
+			return report, err
+		}
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (u *seatMapUsecase) planImport(
+	ctx context.Context,
+	event *domain.Event,
+	m *seatmap.Map,
+	mode dto.SeatMapConflictMode,
+	report *dto.SeatMapImportReport,
+) (*seatMapPlan, error) {
+	eventID := int64(event.ID)
+
+	conflict := func(s seatmap.Seat, reason string) {
+		report.Conflicts = append(report.Conflicts, &dto.SeatMapConflict{
+			Section: s.Section,
+			Row:     s.Row,
+			Number:  s.Number,
+			Reason:  reason,
+		})
+	}
+
+	// categories are ticket type names, which must be unambiguous
+	ticketTypes, err := u.ttRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	categories := map[string]int64{}
+	ambiguous := map[string]bool{}
+	for _, tt := range ticketTypes {
+		if _, ok := categories[tt.Name]; ok {
+			ambiguous[tt.Name] = true
+		}
+		categories[tt.Name] = tt.ID
+	}
+
+	sections, err := u.sectRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	plan := &seatMapPlan{sectionIDs: map[string]int64{}}
+	existingSections := make(map[string]*domain.Section, len(sections))
+	existingSeats := map[seatmap.Key]*domain.Seat{}
+	sectionCounts := map[string]int{}
+	for _, section := range sections {
+		existingSections[section.Name] = section
+		plan.sectionIDs[section.Name] = section.ID
+
+		seats, err := u.seatRepo.GetSeatsBySectionID(ctx, section.ID)
+		if err != nil {
+			return nil, err
+		}
+		sectionCounts[section.Name] = len(seats)
+		for _, s := range seats {
+			existingSeats[seatmap.Key{Section: section.Name, Row: s.RowLabel, Number: s.SeatNumber}] = s
+		}
+	}
+
+	added := map[string]int{}
+	var sectionOrder []string
+
+	for _, ms := range m.Seats {
+		seat := &domain.Seat{
+			RowLabel:     ms.Row,
+			SeatNumber:   ms.Number,
+			IsAvailable:  !ms.Blocked,
+			IsAccessible: ms.Accessible,
+			AisleAfter:   ms.AisleAfter,
+			X:            ms.X,
+			Y:            ms.Y,
+		}
+
+		if ms.Category != "" {
+			id, ok := categories[ms.Category]
+			switch {
+			case !ok:
+				conflict(ms, fmt.Sprintf("unknown category %q", ms.Category))
+				continue
+			case ambiguous[ms.Category]:
+				conflict(ms, fmt.Sprintf("several ticket types are named %q", ms.Category))
+				continue
+			}
+			seat.TicketTypeID = &id
+		}
+
+		if existing, ok := existingSeats[ms.Key()]; ok {
+			if mode != dto.ConflictUpdate {
+				conflict(ms, "seat already exists")
+				continue
+			}
+
+			seat.ID = existing.ID
+			seat.SectionID = existing.SectionID
+			if sameSeat(existing, seat) {
+				report.SeatsUnchanged++
+				continue
+			}
+			plan.updates = append(plan.updates, seat)
+			report.SeatsUpdated++
+			continue
+		}
+
+		if _, ok := added[ms.Section]; !ok {
+			sectionOrder = append(sectionOrder, ms.Section)
+		}
+		added[ms.Section]++
+		plan.newSeats = append(plan.newSeats, seat)
+		plan.seatNames = append(plan.seatNames, ms.Section)
+		report.SeatsCreated++
+	}
+
+	for _, name := range sectionOrder {
+		section, ok := existingSections[name]
+		if !ok {
+			plan.newSections = append(plan.newSections, &domain.Section{
+				EventID:   eventID,
+				Name:      name,
+				SeatCount: added[name],
+			})
+			report.SectionsCreated = append(report.SectionsCreated, name)
+			continue
+		}
+
+		if total := sectionCounts[name] + added[name]; total > section.SeatCount {
+			report.Conflicts = append(report.Conflicts, &dto.SeatMapConflict{
+				Section: name,
+				Reason:  fmt.Sprintf("section holds %d seats, import would make it %d", section.SeatCount, total),
+			})
+		}
+	}
+
+	if event.LocationID != 0 && report.SeatsCreated > 0 {
+		location, err := u.eventRepo.GetLocationByID(ctx, int64(event.LocationID))
+		if err != nil {
+			return nil, err
+		}
+		total := len(existingSeats) + report.SeatsCreated
+		if location.Capacity > 0 && int64(total) > location.Capacity {
+			report.Conflicts = append(report.Conflicts, &dto.SeatMapConflict{
+				Reason: fmt.Sprintf("location capacity is %d seats, import would make it %d", location.Capacity, total),
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+func sameSeat(a, b *domain.Seat) bool {
+	return a.IsAvailable == b.IsAvailable &&
+		a.IsAccessible == b.IsAccessible &&
+		a.AisleAfter == b.AisleAfter &&
+		sameTicketType(a.TicketTypeID, b.TicketTypeID) &&
+		sameFloat(a.X, b.X) &&
+		sameFloat(a.Y, b.Y)
+}
+
+func sameFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/seatstream"
+)
+
+// seatChangeRetention is how far back stream clients can resume from.
+const seatChangeRetention = time.Hour * 24
+
+type SeatStreamUsecase interface {
+	CheckEvent(ctx context.Context, eventID int64) error
+	Stream(ctx context.Context, eventID, lastID int64, send func(m *seatstream.Message) error) error
+	PruneSeatChanges(ctx context.Context) (int64, error)
+}
+
+type seatStreamUsecase struct {
+	hub       *seatstream.Hub
+	repo      repository.SeatChangeRepository
+	eventRepo repository.EventRepository
+}
+
+func NewSeatStreamUsecase(hub *seatstream.Hub, repo repository.SeatChangeRepository, eventRepo repository.EventRepository) SeatStreamUsecase {
+	return &seatStreamUsecase{
+		hub:       hub,
+		repo:      repo,
+		eventRepo: eventRepo,
+	}
+}
+
+// CheckEvent makes sure the event exists before a stream is opened for it.
+func (u *seatStreamUsecase) CheckEvent(ctx context.Context, eventID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	_, err := u.eventRepo.GetEventByID(ctx, eventID)
+	return err
+}
+
+// Stream runs for as long as the client stays connected, so only the
+// replay queries are bounded by a timeout.
+func (u *seatStreamUsecase) Stream(ctx context.Context, eventID, lastID int64, send func(m *seatstream.Message) error) error {
+	return u.hub.Serve(ctx, &timeoutLog{u.repo}, eventID, lastID, send)
+}
+
+func (u *seatStreamUsecase) PruneSeatChanges(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.DeleteBefore(ctx, time.Now().Add(-seatChangeRetention))
+}
+
+// timeoutLog bounds each replay query of a long-lived stream.
+type timeoutLog struct {
+	repository.SeatChangeRepository
+}
+
+func (l *timeoutLog) OldestID(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return l.SeatChangeRepository.OldestID(ctx)
+}
+
+func (l *timeoutLog) ListByEventAfter(ctx context.Context, eventID, afterID, upToID int64, limit int) ([]*domain.SeatChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return l.SeatChangeRepository.ListByEventAfter(ctx, eventID, afterID, upToID, limit)
+}
@@ -2,17 +2,19 @@ package usecase
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/domain"
 	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
 	"github.com/codepnw/go-ticket-booking/internal/repository"
 )
 
 type SeatUsecase interface {
-	CreateSeats(ctx context.Context, tx *sql.Tx, req *dto.CreateSeatsRequest) error
+	CreateSeats(ctx context.Context, req *dto.CreateSeatsRequest) error
+	GenerateSeats(ctx context.Context, req *dto.GenerateSeatsRequest) (*dto.GenerateSeatsResponse, error)
 	GetSeatsBySectionID(ctx context.Context, sectionID int64) ([]*domain.Seat, error)
 	GetAvailableSeatsByEvent(ctx context.Context, eventID int64) ([]*domain.Seat, error)
 	UpdateSeat(ctx context.Context, seatID int64, input *dto.UpdateSeatRequest) error
@@ -21,14 +23,30 @@ type SeatUsecase interface {
 }
 
 type seatUsecase struct {
-	repo repository.SeatRepository
+	tx        database.TxManager
+	repo      repository.SeatRepository
+	sectRepo  repository.SectionRepository
+	ttRepo    repository.TicketTypeRepository
+	eventRepo repository.EventRepository
 }
 
-func NewSeatRepository(repo repository.SeatRepository) SeatUsecase {
-	return &seatUsecase{repo: repo}
+func NewSeatRepository(
+	tx database.TxManager,
+	repo repository.SeatRepository,
+	sectRepo repository.SectionRepository,
+	ttRepo repository.TicketTypeRepository,
+	eventRepo repository.EventRepository,
+) SeatUsecase {
+	return &seatUsecase{
+		tx:        tx,
+		repo:      repo,
+		sectRepo:  sectRepo,
+		ttRepo:    ttRepo,
+		eventRepo: eventRepo,
+	}
 }
 
-func (u *seatUsecase) CreateSeats(ctx context.Context, tx *sql.Tx, req *dto.CreateSeatsRequest) error {
+func (u *seatUsecase) CreateSeats(ctx context.Context, req *dto.CreateSeatsRequest) error {
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
@@ -36,18 +54,106 @@ func (u *seatUsecase) CreateSeats(ctx context.Context, tx *sql.Tx, req *dto.Crea
 		return errors.New("no seats to create")
 	}
 
-	for _, seat := range req.Seats {
-		s := domain.Seat{
-			SectionID:  seat.SectionID,
-			RowLabel:   seat.RowLabel,
-			SeatNumber: seat.SeatNumber,
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		seats := make([]*domain.Seat, 0, len(req.Seats))
+		for _, seat := range req.Seats {
+			s := &domain.Seat{
+				SectionID:    seat.SectionID,
+				RowLabel:     seat.RowLabel,
+				SeatNumber:   seat.SeatNumber,
+				IsAvailable:  true,
+				TicketTypeID: seat.TicketTypeID,
+				IsAccessible: seat.IsAccessible,
+				AisleAfter:   seat.AisleAfter,
+				X:            seat.X,
+				Y:            seat.Y,
+			}
+			if err := u.checkTicketType(ctx, s); err != nil {
+				return err
+			}
+			seats = append(seats, s)
+		}
+
+		if err := u.repo.CreateBatch(ctx, seats); err != nil {
+			return fmt.Errorf("create seats failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// GenerateSeats creates a block of seats from a row/range spec in one
+// insert. The totals must stay within the section's seat count and the
+// location's capacity.
+func (u *seatUsecase) GenerateSeats(ctx context.Context, req *dto.GenerateSeatsRequest) (*dto.GenerateSeatsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	seats, rows, skipped, err := planSeats(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.GenerateSeatsResponse{
+		SectionID: req.SectionID,
+		Rows:      rows,
+		Created:   len(seats),
+		Skipped:   skipped,
+	}
+
+	err = u.tx.WithTx(ctx, func(ctx context.Context) error {
+		section, err := u.sectRepo.GetByID(ctx, req.SectionID)
+		if err != nil {
+			return errs.ErrSectionNotFound
+		}
+
+		// serializes seat generation per event so the counts stay right
+		event, err := u.eventRepo.GetEventForUpdate(ctx, section.EventID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkTicketTypeEvent(ctx, u.ttRepo, req.TicketTypeID, section.EventID); err != nil {
+			return err
+		}
+
+		sectionSeats, err := u.repo.CountBySection(ctx, section.ID)
+		if err != nil {
+			return err
+		}
+		if sectionSeats+len(seats) > section.SeatCount {
+			return errs.ErrSectionSeatCountExceeded
+		}
+
+		eventSeats, err := u.repo.CountByEvent(ctx, section.EventID)
+		if err != nil {
+			return err
+		}
+
+		if event.LocationID != 0 {
+			location, err := u.eventRepo.GetLocationByID(ctx, int64(event.LocationID))
+			if err != nil {
+				return err
+			}
+			if location.Capacity > 0 && int64(eventSeats+len(seats)) > location.Capacity {
+				return errs.ErrLocationCapacityExceeded
+			}
+			res.LocationCapacity = location.Capacity
 		}
-		if err := u.repo.Create(ctx, tx, &s); err != nil {
-			return fmt.Errorf("create seats failed: %v", err)
+
+		if err := u.repo.CreateBatch(ctx, seats); err != nil {
+			return err
 		}
+
+		res.SectionSeats = sectionSeats + len(seats)
+		res.SectionSeatCount = section.SeatCount
+		res.EventSeats = eventSeats + len(seats)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return res, nil
 }
 
 func (u *seatUsecase) GetSeatsBySectionID(ctx context.Context, sectionID int64) ([]*domain.Seat, error) {
@@ -73,7 +179,9 @@ func (u *seatUsecase) UpdateSeat(ctx context.Context, seatID int64, req *dto.Upd
 		return err
 	}
 
-	if req.SectionID == nil && req.RowLabel == nil && req.SeatNumber == nil && req.IsAvailable == nil {
+	if req.SectionID == nil && req.RowLabel == nil && req.SeatNumber == nil &&
+		req.IsAvailable == nil && req.TicketTypeID == nil && req.IsAccessible == nil &&
+		req.AisleAfter == nil && req.X == nil && req.Y == nil {
 		return errors.New("no fields to update")
 	}
 
@@ -93,6 +201,30 @@ func (u *seatUsecase) UpdateSeat(ctx context.Context, seatID int64, req *dto.Upd
 		seat.IsAvailable = *req.IsAvailable
 	}
 
+	if req.TicketTypeID != nil {
+		seat.TicketTypeID = req.TicketTypeID
+	}
+
+	if req.IsAccessible != nil {
+		seat.IsAccessible = *req.IsAccessible
+	}
+
+	if req.AisleAfter != nil {
+		seat.AisleAfter = *req.AisleAfter
+	}
+
+	if req.X != nil {
+		seat.X = req.X
+	}
+
+	if req.Y != nil {
+		seat.Y = req.Y
+	}
+
+	if err := u.checkTicketType(ctx, seat); err != nil {
+		return err
+	}
+
 	return u.repo.UpdateSeat(ctx, seat)
 }
 
@@ -109,3 +241,17 @@ func (u *seatUsecase) DeleteSeatsBySection(ctx context.Context, sectionID int64)
 
 	return u.repo.DeleteSeatsBySection(ctx, sectionID)
 }
+
+// checkTicketType verifies the seat's ticket type belongs to its section's event.
+func (u *seatUsecase) checkTicketType(ctx context.Context, seat *domain.Seat) error {
+	if seat.TicketTypeID == nil {
+		return nil
+	}
+
+	section, err := u.sectRepo.GetByID(ctx, seat.SectionID)
+	if err != nil {
+		return errs.ErrSectionNotFound
+	}
+
+	return checkTicketTypeEvent(ctx, u.ttRepo, seat.TicketTypeID, section.EventID)
+}
@@ -19,21 +19,30 @@ type SectionUsecase interface {
 }
 
 type sectionUsecase struct {
-	repo repository.SectionRepository
+	repo   repository.SectionRepository
+	ttRepo repository.TicketTypeRepository
 }
 
-func NewSectionUsecase(repo repository.SectionRepository) SectionUsecase {
-	return &sectionUsecase{repo: repo}
+func NewSectionUsecase(repo repository.SectionRepository, ttRepo repository.TicketTypeRepository) SectionUsecase {
+	return &sectionUsecase{
+		repo:   repo,
+		ttRepo: ttRepo,
+	}
 }
 
 func (u *sectionUsecase) CreateSection(ctx context.Context, req dto.SectionRequest) (*domain.Section, error) {
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
+	if err := checkTicketTypeEvent(ctx, u.ttRepo, req.TicketTypeID, req.EventID); err != nil {
+		return nil, err
+	}
+
 	section := &domain.Section{
-		Name:      req.Name,
-		EventID:   req.EventID,
-		SeatCount: req.SeatCount,
+		Name:         req.Name,
+		EventID:      req.EventID,
+		SeatCount:    req.SeatCount,
+		TicketTypeID: req.TicketTypeID,
 	}
 
 	if err := u.repo.Create(ctx, section); err != nil {
@@ -87,6 +96,14 @@ func (u *sectionUsecase) UpdateSection(ctx context.Context, id int64, req dto.Se
 		section.SeatCount = *req.SeatCount
 	}
 
+	if req.TicketTypeID != nil {
+		section.TicketTypeID = req.TicketTypeID
+	}
+
+	if err := checkTicketTypeEvent(ctx, u.ttRepo, section.TicketTypeID, section.EventID); err != nil {
+		return nil, err
+	}
+
 	section.UpdatedAt = time.Now()
 
 	err = u.repo.Update(ctx, section)
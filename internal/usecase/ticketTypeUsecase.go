@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+type TicketTypeUsecase interface {
+	CreateTicketType(ctx context.Context, eventID int64, req *dto.TicketTypeRequest) (*domain.TicketType, error)
+	ListTicketTypes(ctx context.Context, eventID int64) ([]*domain.TicketType, error)
+	GetTicketType(ctx context.Context, eventID, id int64) (*domain.TicketType, error)
+	UpdateTicketType(ctx context.Context, eventID, id int64, req *dto.TicketTypeUpdateRequest) (*domain.TicketType, error)
+	DeleteTicketType(ctx context.Context, eventID, id int64) error
+}
+
+type ticketTypeUsecase struct {
+	repo      repository.TicketTypeRepository
+	eventRepo repository.EventRepository
+}
+
+func NewTicketTypeUsecase(repo repository.TicketTypeRepository, eventRepo repository.EventRepository) TicketTypeUsecase {
+	return &ticketTypeUsecase{
+		repo:      repo,
+		eventRepo: eventRepo,
+	}
+}
+
+func (u *ticketTypeUsecase) CreateTicketType(ctx context.Context, eventID int64, req *dto.TicketTypeRequest) (*domain.TicketType, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	if _, err := u.eventRepo.GetEventByID(ctx, eventID); err != nil {
+		return nil, err
+	}
+
+	tt := &domain.TicketType{
+		EventID:  eventID,
+		Name:     req.Name,
+		Price:    req.Price,
+		Quantity: req.Quantity,
+
+		MaxPerUser: req.MaxPerUser,
+	}
+
+	if err := u.repo.Create(ctx, tt); err != nil {
+		return nil, err
+	}
+
+	return tt, nil
+}
+
+func (u *ticketTypeUsecase) ListTicketTypes(ctx context.Context, eventID int64) ([]*domain.TicketType, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.ListByEvent(ctx, eventID)
+}
+
+func (u *ticketTypeUsecase) GetTicketType(ctx context.Context, eventID, id int64) (*domain.TicketType, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.getEventTicketType(ctx, eventID, id)
+}
+
+func (u *ticketTypeUsecase) UpdateTicketType(ctx context.Context, eventID, id int64, req *dto.TicketTypeUpdateRequest) (*domain.TicketType, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	if req.Name == nil && req.Price == nil && req.Quantity == nil && req.MaxPerUser == nil {
+		return nil, errs.ErrNoFieldsToUpdate
+	}
+
+	tt, err := u.getEventTicketType(ctx, eventID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		tt.Name = *req.Name
+	}
+
+	if req.Price != nil {
+		tt.Price = *req.Price
+	}
+
+	if req.Quantity != nil {
+		tt.Quantity = *req.Quantity
+	}
+
+	if req.MaxPerUser != nil {
+		tt.MaxPerUser = purchaseLimit(*req.MaxPerUser)
+	}
+
+	if err := u.repo.Update(ctx, tt); err != nil {
+		return nil, err
+	}
+
+	return tt, nil
+}
+
+func (u *ticketTypeUsecase) DeleteTicketType(ctx context.Context, eventID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	if _, err := u.getEventTicketType(ctx, eventID, id); err != nil {
+		return err
+	}
+
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *ticketTypeUsecase) getEventTicketType(ctx context.Context, eventID, id int64) (*domain.TicketType, error) {
+	tt, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tt.EventID != eventID {
+		return nil, errs.ErrTicketTypeNotFound
+	}
+
+	return tt, nil
+}
+
+// checkTicketTypeEvent verifies an optional ticket type binding belongs to the event.
+func checkTicketTypeEvent(ctx context.Context, repo repository.TicketTypeRepository, ticketTypeID *int64, eventID int64) error {
+	if ticketTypeID == nil {
+		return nil
+	}
+
+	tt, err := repo.GetByID(ctx, *ticketTypeID)
+	if err != nil {
+		return err
+	}
+
+	if tt.EventID != eventID {
+		return errs.ErrInvalidTicketTypeEvent
+	}
+
+	return nil
+}
@@ -5,24 +5,32 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
+	"github.com/codepnw/go-ticket-booking/internal/database"
 	"github.com/codepnw/go-ticket-booking/internal/domain"
 	"github.com/codepnw/go-ticket-booking/internal/dto"
 	"github.com/codepnw/go-ticket-booking/internal/errs"
 	"github.com/codepnw/go-ticket-booking/internal/helper"
 	"github.com/codepnw/go-ticket-booking/internal/helper/auth"
 	"github.com/codepnw/go-ticket-booking/internal/helper/security"
+	"github.com/codepnw/go-ticket-booking/internal/mailer"
 	"github.com/codepnw/go-ticket-booking/internal/repository"
 )
 
-const queryTimeOut = time.Second * 5
+const (
+	queryTimeOut         = time.Second * 5
+	emailVerificationTTL = 24 * time.Hour
+)
 
 type UserUsecase interface {
 	CreateUser(ctx context.Context, req *dto.UserRegisterRequest) (*domain.User, error)
 	Login(ctx context.Context, req *dto.UserLoginRequest) (string, string, error)
 	GetUser(ctx context.Context, id int64) (*domain.User, error)
 	UpdateUser(ctx context.Context, id int64, req *dto.UserUpdateRequest) (*domain.User, error)
+	ResendVerification(ctx context.Context, id int64) error
+	VerifyEmail(ctx context.Context, token string) error
 
 	// Admin
 	GetUsers(ctx context.Context, limit, offset int) ([]*domain.User, error)
@@ -30,16 +38,32 @@ type UserUsecase interface {
 }
 
 type userUsecase struct {
-	userRepo repository.UserRepository
-	authRepo repository.AuthRepository
-	auth     auth.Auth
+	tx        database.TxManager
+	userRepo  repository.UserRepository
+	authRepo  repository.AuthRepository
+	auth      auth.Auth
+	mailer    mailer.Mailer
+	verifier  *security.VerificationSigner
+	publicURL string
 }
 
-func NewUserUsecase(userRepo repository.UserRepository, authRepo repository.AuthRepository, auth auth.Auth) UserUsecase {
+func NewUserUsecase(
+	tx database.TxManager,
+	userRepo repository.UserRepository,
+	authRepo repository.AuthRepository,
+	auth auth.Auth,
+	mailer mailer.Mailer,
+	verifier *security.VerificationSigner,
+	publicURL string,
+) UserUsecase {
 	return &userUsecase{
-		userRepo: userRepo,
-		authRepo: authRepo,
-		auth:     auth,
+		tx:        tx,
+		userRepo:  userRepo,
+		authRepo:  authRepo,
+		auth:      auth,
+		mailer:    mailer,
+		verifier:  verifier,
+		publicURL: publicURL,
 	}
 }
 
@@ -47,6 +71,10 @@ func (u *userUsecase) CreateUser(ctx context.Context, req *dto.UserRegisterReque
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
 
+	if err := security.CheckPasswordPolicy(req.Password, req.Email); err != nil {
+		return nil, err
+	}
+
 	hashed, err := security.GenenrateHashPassword(req.Password)
 	if err != nil {
 		return nil, err
@@ -67,6 +95,10 @@ func (u *userUsecase) CreateUser(ctx context.Context, req *dto.UserRegisterReque
 		return nil, fmt.Errorf("create user failed: %v", err)
 	}
 
+	if err := u.sendVerification(created); err != nil {
+		return nil, err
+	}
+
 	// utc time -> thai time
 	t, err := helper.LoadThaiTime(created.CreatedAt)
 	if err != nil {
@@ -77,6 +109,78 @@ func (u *userUsecase) CreateUser(ctx context.Context, req *dto.UserRegisterReque
 	return created, nil
 }
 
+// ResendVerification mails a new verification link. Earlier links keep
+// working until they expire.
+func (u *userUsecase) ResendVerification(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	user, err := u.userRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrUserNotFound
+		}
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return errs.ErrEmailAlreadyVerified
+	}
+
+	return u.sendVerification(user)
+}
+
+// VerifyEmail marks the email in a verification link as verified. The link
+// no longer works once the user's email has changed.
+func (u *userUsecase) VerifyEmail(ctx context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	v, err := u.verifier.Verify(token, time.Now())
+	if err != nil {
+		return errs.ErrInvalidVerificationLink
+	}
+
+	user, err := u.userRepo.FindByID(ctx, v.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrInvalidVerificationLink
+		}
+		return err
+	}
+
+	if user.Email != v.Email {
+		return errs.ErrInvalidVerificationLink
+	}
+
+	return u.userRepo.MarkEmailVerified(ctx, user.ID, time.Now())
+}
+
+// sendVerification signs a verification link for the user's current email
+// and mails it in the background.
+func (u *userUsecase) sendVerification(user *domain.User) error {
+	token, err := u.verifier.Sign(&security.EmailVerification{
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(emailVerificationTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", u.publicURL, url.QueryEscape(token))
+	go sendMail(u.mailer, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address with the link below. It expires in %d hours.\n\n%s\n\nIf you did not create an account, you can ignore this email.",
+			user.FirstName, int(emailVerificationTTL.Hours()), link,
+		),
+	})
+
+	return nil
+}
+
 func (u *userUsecase) Login(ctx context.Context, req *dto.UserLoginRequest) (string, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
 	defer cancel()
@@ -97,23 +201,17 @@ func (u *userUsecase) Login(ctx context.Context, req *dto.UserLoginRequest) (str
 	now := time.Now().UTC()
 	user.LastLoginAt = &now
 
-	// update last login
-	if err := u.userRepo.UpdateLastLogin(ctx, user); err != nil {
-		return "", "", err
-	}
-
-	accessToken, err := u.auth.GenerateAccessToken(user.ID, user.Email, user.Role)
-	if err != nil {
-		return "", "", err
-	}
-
-	refreshToken, exp, err := u.auth.GenerateRefreshToken(user.ID, user.Email, user.Role)
-	if err != nil {
-		return "", "", err
-	}
+	var accessToken, refreshToken string
+	err = u.tx.WithTx(ctx, func(ctx context.Context) error {
+		// update last login
+		if err := u.userRepo.UpdateLastLogin(ctx, user); err != nil {
+			return err
+		}
 
-	// save refresh token
-	err = u.authRepo.SaveRefreshToken(ctx, user.ID, refreshToken, exp)
+		// new session for this device
+		accessToken, refreshToken, err = startSession(ctx, u.authRepo, &u.auth, user, &req.Device)
+		return err
+	})
 	if err != nil {
 		return "", "", err
 	}
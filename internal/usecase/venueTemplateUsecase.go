@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+const (
+	maxSectionNameLen = 50
+	maxRowLabelLen    = 10
+	maxSeatNumber     = 1000
+)
+
+type VenueTemplateUsecase interface {
+	Create(ctx context.Context, locationID int64, req *dto.VenueTemplateRequest) (*domain.VenueTemplate, error)
+	GetByID(ctx context.Context, id int64) (*domain.VenueTemplate, error)
+	ListByLocation(ctx context.Context, locationID int64) ([]*domain.VenueTemplate, error)
+	Update(ctx context.Context, id int64, req *dto.VenueTemplateUpdateRequest) (*domain.VenueTemplate, error)
+	Delete(ctx context.Context, id int64) error
+	ApplyToEvent(ctx context.Context, eventID, templateID int64) (*dto.ApplyTemplateResponse, error)
+}
+
+type venueTemplateUsecase struct {
+	tx        database.TxManager
+	repo      repository.VenueTemplateRepository
+	eventRepo repository.EventRepository
+	sectRepo  repository.SectionRepository
+	seatRepo  repository.SeatRepository
+}
+
+func NewVenueTemplateUsecase(
+	tx database.TxManager,
+	repo repository.VenueTemplateRepository,
+	eventRepo repository.EventRepository,
+	sectRepo repository.SectionRepository,
+	seatRepo repository.SeatRepository,
+) VenueTemplateUsecase {
+	return &venueTemplateUsecase{
+		tx:        tx,
+		repo:      repo,
+		eventRepo: eventRepo,
+		sectRepo:  sectRepo,
+		seatRepo:  seatRepo,
+	}
+}
+
+func (u *venueTemplateUsecase) Create(ctx context.Context, locationID int64, req *dto.VenueTemplateRequest) (*domain.VenueTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	t := &domain.VenueTemplate{
+		LocationID:  locationID,
+		Name:        req.Name,
+		Description: req.Description,
+		Layout:      req.Layout,
+	}
+
+	if err := u.checkLayout(ctx, t); err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (u *venueTemplateUsecase) GetByID(ctx context.Context, id int64) (*domain.VenueTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.GetByID(ctx, id)
+}
+
+func (u *venueTemplateUsecase) ListByLocation(ctx context.Context, locationID int64) ([]*domain.VenueTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	if _, err := u.eventRepo.GetLocationByID(ctx, locationID); err != nil {
+		return nil, err
+	}
+
+	return u.repo.ListByLocation(ctx, locationID)
+}
+
+// Update edits the template only. Events it was applied to keep their own
+// copy of the sections and seats.
+func (u *venueTemplateUsecase) Update(ctx context.Context, id int64, req *dto.VenueTemplateUpdateRequest) (*domain.VenueTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	if req.Name == nil && req.Description == nil && req.Layout == nil {
+		return nil, errs.ErrNoFieldsToUpdate
+	}
+
+	t, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		t.Name = *req.Name
+	}
+
+	if req.Description != nil {
+		t.Description = *req.Description
+	}
+
+	if req.Layout != nil {
+		t.Layout = *req.Layout
+		if err := u.checkLayout(ctx, t); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.repo.Update(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (u *venueTemplateUsecase) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.repo.Delete(ctx, id)
+}
+
+// ApplyToEvent copies the template's sections and seats into the event. The
+// event must be at the template's location and have no sections yet.
+func (u *venueTemplateUsecase) ApplyToEvent(ctx context.Context, eventID, templateID int64) (*dto.ApplyTemplateResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	res := &dto.ApplyTemplateResponse{TemplateID: templateID}
+
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		// locks the event so two applies cannot both see it empty
+		event, err := u.eventRepo.GetEventForUpdate(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		if event.Status == string(dto.EventCancelled) {
+			return errs.ErrEventAlreadyCancelled
+		}
+
+		count, err := u.sectRepo.CountByEvent(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errs.ErrEventHasSeatMap
+		}
+
+		t, err := u.repo.GetByID(ctx, templateID)
+		if err != nil {
+			return err
+		}
+
+		if t.LocationID != int64(event.LocationID) {
+			return errs.ErrTemplateWrongLocation
+		}
+
+		res.TemplateVersion = t.Version
+
+		for _, ls := range t.Layout.Sections {
+			section := &domain.Section{
+				EventID:         eventID,
+				Name:            ls.Name,
+				SeatCount:       ls.SeatCount(),
+				TemplateID:      &t.ID,
+				TemplateVersion: &t.Version,
+			}
+			if err := u.sectRepo.Create(ctx, section); err != nil {
+				return err
+			}
+
+			var seats []*domain.Seat
+			for _, lr := range ls.Rows {
+				aisles := intSet(lr.AislesAfter)
+				accessible := intSet(lr.Accessible)
+
+				for _, n := range lr.SeatNumbers() {
+					seats = append(seats, &domain.Seat{
+						SectionID:    section.ID,
+						RowLabel:     lr.Label,
+						SeatNumber:   n,
+						IsAvailable:  true,
+						IsAccessible: accessible[n],
+						AisleAfter:   aisles[n],
+					})
+				}
+			}
+
+			if err := u.seatRepo.CreateBatch(ctx, seats); err != nil {
+				return err
+			}
+
+			res.Sections = append(res.Sections, section)
+			res.SeatCount += section.SeatCount
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// checkLayout validates the layout and that it fits the location.
+func (u *venueTemplateUsecase) checkLayout(ctx context.Context, t *domain.VenueTemplate) error {
+	if err := validateLayout(t.Layout); err != nil {
+		return err
+	}
+
+	location, err := u.eventRepo.GetLocationByID(ctx, t.LocationID)
+	if err != nil {
+		return err
+	}
+
+	if location.Capacity > 0 && int64(t.Layout.SeatCount()) > location.Capacity {
+		return errs.ErrLocationCapacityExceeded
+	}
+
+	return nil
+}
+
+func validateLayout(l domain.VenueLayout) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", errs.ErrInvalidVenueLayout, fmt.Sprintf(format, args...))
+	}
+
+	if len(l.Sections) == 0 {
+		return invalid("at least one section is required")
+	}
+
+	total := 0
+	sections := map[string]bool{}
+	for _, s := range l.Sections {
+		if s.Name == "" || utf8.RuneCountInString(s.Name) > maxSectionNameLen {
+			return invalid("section name must be 1-%d characters", maxSectionNameLen)
+		}
+		if sections[s.Name] {
+			return invalid("duplicate section %q", s.Name)
+		}
+		sections[s.Name] = true
+
+		if len(s.Rows) == 0 {
+			return invalid("section %q has no rows", s.Name)
+		}
+
+		rows := map[string]bool{}
+		for _, r := range s.Rows {
+			if r.Label == "" || utf8.RuneCountInString(r.Label) > maxRowLabelLen {
+				return invalid("section %q: row label must be 1-%d characters", s.Name, maxRowLabelLen)
+			}
+			if rows[r.Label] {
+				return invalid("section %q: duplicate row %q", s.Name, r.Label)
+			}
+			rows[r.Label] = true
+
+			if len(r.Ranges) == 0 {
+				return invalid("section %q row %q has no seats", s.Name, r.Label)
+			}
+
+			for _, rg := range r.Ranges {
+				if rg.From < 1 || rg.To < rg.From || rg.To > maxSeatNumber {
+					return invalid("section %q row %q: bad range %d-%d, seats are numbered 1-%d",
+						s.Name, r.Label, rg.From, rg.To, maxSeatNumber)
+				}
+			}
+
+			// check the size before expanding the ranges
+			count := r.SeatCount()
+			if count > maxSeatNumber {
+				return invalid("section %q row %q: more than %d seats", s.Name, r.Label, maxSeatNumber)
+			}
+			if total += count; total > maxGeneratedSeats {
+				return invalid("more than %d seats", maxGeneratedSeats)
+			}
+
+			seats := make(map[int]bool, count)
+			for _, rg := range r.Ranges {
+				for n := rg.From; n <= rg.To; n++ {
+					if seats[n] {
+						return invalid("section %q row %q: seat %d listed twice", s.Name, r.Label, n)
+					}
+					seats[n] = true
+				}
+			}
+
+			for _, marked := range [][]int{r.AislesAfter, r.Accessible} {
+				for _, n := range marked {
+					if !seats[n] {
+						return invalid("section %q row %q: seat %d does not exist", s.Name, r.Label, n)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func intSet(values []int) map[int]bool {
+	set := make(map[int]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
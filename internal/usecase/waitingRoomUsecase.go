@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+	"github.com/codepnw/go-ticket-booking/internal/waitingroom"
+)
+
+const (
+	// queueSettingsTTL is how long booking checks trust a cached queue
+	// setting, so an on-sale does not hit Postgres on every check.
+	queueSettingsTTL = time.Second * 5
+	// maxCachedQueues bounds the settings cache; it is emptied when full.
+	maxCachedQueues = 1024
+)
+
+// AdmissionGate decides whether a user may enter the booking flow of an
+// event right now.
+type AdmissionGate interface {
+	CheckAdmission(ctx context.Context, eventID, userID int64, token string) error
+}
+
+type WaitingRoomUsecase interface {
+	AdmissionGate
+	Configure(ctx context.Context, eventID int64, req *dto.QueueSettingsRequest) (*domain.EventQueue, error)
+	GetSettings(ctx context.Context, eventID int64) (*domain.EventQueue, error)
+	Join(ctx context.Context, eventID, userID int64) (*dto.QueueStatusResponse, error)
+	Status(ctx context.Context, eventID, userID int64) (*dto.QueueStatusResponse, error)
+	AdmitQueued(ctx context.Context) (int64, error)
+}
+
+type waitingRoomUsecase struct {
+	tx        database.TxManager
+	store     waitingroom.Store
+	eventRepo repository.EventRepository
+	signer    *waitingroom.Signer
+
+	mu       sync.Mutex
+	settings map[int64]cachedQueue
+}
+
+type cachedQueue struct {
+	enabled bool
+	at      time.Time
+}
+
+func NewWaitingRoomUsecase(
+	tx database.TxManager,
+	store waitingroom.Store,
+	eventRepo repository.EventRepository,
+	signer *waitingroom.Signer,
+) WaitingRoomUsecase {
+	return &waitingRoomUsecase{
+		tx:        tx,
+		store:     store,
+		eventRepo: eventRepo,
+		signer:    signer,
+		settings:  make(map[int64]cachedQueue),
+	}
+}
+
+// Configure turns the event's waiting room on or off and sets its rate.
+// Turning it on starts admitting from now, without credit for the time it
+// was off.
+func (u *waitingRoomUsecase) Configure(ctx context.Context, eventID int64, req *dto.QueueSettingsRequest) (*domain.EventQueue, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	if _, err := u.eventRepo.GetEventByID(ctx, eventID); err != nil {
+		return nil, err
+	}
+
+	var q *domain.EventQueue
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		current, err := u.store.GetQueueForUpdate(ctx, eventID)
+		if err != nil && err != errs.ErrQueueNotFound {
+			return err
+		}
+
+		q = &domain.EventQueue{
+			EventID:          eventID,
+			Enabled:          req.Enabled,
+			RatePerMinute:    req.RatePerMinute,
+			AdmissionMinutes: req.AdmissionMinutes,
+			AdmittedUntil:    time.Now(),
+		}
+		if current != nil && current.Enabled {
+			q.AdmittedUntil = current.AdmittedUntil
+		}
+
+		return u.store.SaveQueue(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	delete(u.settings, eventID)
+	u.mu.Unlock()
+
+	return q, nil
+}
+
+func (u *waitingRoomUsecase) GetSettings(ctx context.Context, eventID int64) (*domain.EventQueue, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.store.GetQueue(ctx, eventID)
+}
+
+// Join puts the user in line, or reports where they already stand.
+func (u *waitingRoomUsecase) Join(ctx context.Context, eventID, userID int64) (*dto.QueueStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	q, err := u.store.GetQueue(ctx, eventID)
+	if err != nil {
+		if err == errs.ErrQueueNotFound {
+			return nil, errs.ErrQueueClosed
+		}
+		return nil, err
+	}
+	if !q.Enabled {
+		return nil, errs.ErrQueueClosed
+	}
+
+	entry, err := u.store.Join(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		if entry, err = u.store.GetEntry(ctx, eventID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.status(ctx, q, entry)
+}
+
+func (u *waitingRoomUsecase) Status(ctx context.Context, eventID, userID int64) (*dto.QueueStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	q, err := u.store.GetQueue(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := u.store.GetEntry(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.status(ctx, q, entry)
+}
+
+func (u *waitingRoomUsecase) status(ctx context.Context, q *domain.EventQueue, entry *domain.QueueEntry) (*dto.QueueStatusResponse, error) {
+	res := &dto.QueueStatusResponse{
+		EventID:  entry.EventID,
+		Status:   waitingroom.EntryStatus(entry, time.Now()),
+		Position: entry.Position,
+	}
+
+	switch res.Status {
+	case waitingroom.StatusWaiting:
+		ahead, err := u.store.CountAhead(ctx, entry.EventID, entry.Position)
+		if err != nil {
+			return nil, err
+		}
+		res.Ahead = ahead
+		res.EstimatedWaitSeconds = (ahead + 1) * 60 / int64(q.RatePerMinute)
+
+	case waitingroom.StatusAdmitted:
+		token, err := u.signer.Sign(&waitingroom.Admission{
+			EventID:   entry.EventID,
+			UserID:    entry.UserID,
+			Position:  entry.Position,
+			ExpiresAt: entry.ExpiresAt.Unix(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		res.AdmissionToken = token
+		res.ExpiresAt = entry.ExpiresAt
+	}
+
+	return res, nil
+}
+
+// AdmitQueued lets the next users in on every open waiting room, as many
+// as each room's rate allows since it last ran.
+func (u *waitingRoomUsecase) AdmitQueued(ctx context.Context) (int64, error) {
+	ids, err := u.listEnabled(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, eventID := range ids {
+		n, err := u.admit(ctx, eventID)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+
+	return total, nil
+}
+
+func (u *waitingRoomUsecase) listEnabled(ctx context.Context) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.store.ListEnabledQueues(ctx)
+}
+
+func (u *waitingRoomUsecase) admit(ctx context.Context, eventID int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	var admitted int64
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		// the row lock keeps instances from admitting the same slot twice
+		q, err := u.store.GetQueueForUpdate(ctx, eventID)
+		if err != nil || !q.Enabled {
+			return err
+		}
+
+		now := time.Now()
+		n, clock := waitingroom.Allowance(q.RatePerMinute, q.AdmittedUntil, now)
+		if n == 0 {
+			return nil
+		}
+
+		expiresAt := now.Add(time.Duration(q.AdmissionMinutes) * time.Minute)
+		admitted, err = u.store.AdmitNext(ctx, eventID, n, expiresAt)
+		if err != nil {
+			return err
+		}
+
+		// nobody left waiting: no credit builds up for later
+		if admitted < int64(n) {
+			clock = now
+		}
+		return u.store.SetAdmissionClock(ctx, eventID, clock)
+	})
+
+	return admitted, err
+}
+
+// CheckAdmission lets everyone through unless the event's waiting room is
+// on; then it wants a valid admission token for this user and event.
+func (u *waitingRoomUsecase) CheckAdmission(ctx context.Context, eventID, userID int64, token string) error {
+	enabled, err := u.queueEnabled(ctx, eventID)
+	if err != nil || !enabled {
+		return err
+	}
+
+	if token == "" {
+		return errs.ErrAdmissionRequired
+	}
+
+	a, err := u.signer.Verify(token, time.Now())
+	if err != nil || a.EventID != eventID || a.UserID != userID {
+		return errs.ErrInvalidAdmission
+	}
+
+	return nil
+}
+
+func (u *waitingRoomUsecase) queueEnabled(ctx context.Context, eventID int64) (bool, error) {
+	u.mu.Lock()
+	c, ok := u.settings[eventID]
+	u.mu.Unlock()
+
+	if ok && time.Since(c.at) < queueSettingsTTL {
+		return c.enabled, nil
+	}
+
+	q, err := u.store.GetQueue(ctx, eventID)
+	if err != nil && err != errs.ErrQueueNotFound {
+		return false, err
+	}
+
+	c = cachedQueue{enabled: q != nil && q.Enabled, at: time.Now()}
+
+	u.mu.Lock()
+	if len(u.settings) >= maxCachedQueues {
+		clear(u.settings)
+	}
+	u.settings[eventID] = c
+	u.mu.Unlock()
+
+	return c.enabled, nil
+}
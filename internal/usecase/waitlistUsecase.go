@@ -0,0 +1,342 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/database"
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+	"github.com/codepnw/go-ticket-booking/internal/dto"
+	"github.com/codepnw/go-ticket-booking/internal/errs"
+	"github.com/codepnw/go-ticket-booking/internal/notification"
+	"github.com/codepnw/go-ticket-booking/internal/repository"
+)
+
+const (
+	waitlistOfferTTL = time.Minute * 10
+
+	// maxOffersPerRun bounds how long one event can keep the runner busy.
+	maxOffersPerRun = 500
+)
+
+type WaitlistUsecase interface {
+	Join(ctx context.Context, eventID, userID int64, req *dto.JoinWaitlistRequest) (*dto.WaitlistResponse, error)
+	Get(ctx context.Context, eventID, userID int64) (*dto.WaitlistResponse, error)
+	Leave(ctx context.Context, eventID, userID int64) error
+	AcceptOffer(ctx context.Context, eventID, userID int64) (*dto.BookingResponse, error)
+	DeclineOffer(ctx context.Context, eventID, userID int64) error
+	ProcessWaitlists(ctx context.Context) (int64, error)
+}
+
+type waitlistUsecase struct {
+	tx         database.TxManager
+	waitRepo   repository.WaitlistRepository
+	bookRepo   repository.BookingRepository
+	seatRepo   repository.SeatRepository
+	sectRepo   repository.SectionRepository
+	eventRepo  repository.EventRepository
+	notifyRepo repository.NotificationRepository
+	holder     *seatHolder
+}
+
+func NewWaitlistUsecase(
+	tx database.TxManager,
+	waitRepo repository.WaitlistRepository,
+	bookRepo repository.BookingRepository,
+	seatRepo repository.SeatRepository,
+	sectRepo repository.SectionRepository,
+	ttRepo repository.TicketTypeRepository,
+	eventRepo repository.EventRepository,
+	userRepo repository.UserRepository,
+	notifyRepo repository.NotificationRepository,
+) WaitlistUsecase {
+	holder := newSeatHolder(bookRepo, seatRepo, sectRepo, ttRepo, eventRepo, nil, userRepo) // offers take no promo codes
+	holder.allowSoldOut = true
+
+	return &waitlistUsecase{
+		tx:         tx,
+		waitRepo:   waitRepo,
+		bookRepo:   bookRepo,
+		seatRepo:   seatRepo,
+		sectRepo:   sectRepo,
+		eventRepo:  eventRepo,
+		notifyRepo: notifyRepo,
+		holder:     holder,
+	}
+}
+
+func (u *waitlistUsecase) Join(ctx context.Context, eventID, userID int64, req *dto.JoinWaitlistRequest) (*dto.WaitlistResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	event, err := u.eventRepo.GetEventByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Status != string(dto.EventOnSale) && event.Status != string(dto.EventSoldOut) {
+		return nil, errs.ErrWaitlistClosed
+	}
+
+	if req.SectionID != nil {
+		section, err := u.sectRepo.GetByID(ctx, *req.SectionID)
+		if err != nil || section.EventID != eventID {
+			return nil, errs.ErrSectionNotFound
+		}
+	}
+
+	entry := &domain.WaitlistEntry{
+		EventID:   eventID,
+		SectionID: req.SectionID,
+		UserID:    userID,
+	}
+
+	if err = u.waitRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return u.response(ctx, entry)
+}
+
+func (u *waitlistUsecase) Get(ctx context.Context, eventID, userID int64) (*dto.WaitlistResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	entry, err := u.waitRepo.GetActive(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.response(ctx, entry)
+}
+
+func (u *waitlistUsecase) Leave(ctx context.Context, eventID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		entry, err := u.waitRepo.GetActiveForUpdate(ctx, eventID, userID)
+		if err != nil {
+			return err
+		}
+
+		if entry.BookingID != nil {
+			if err := u.bookRepo.ExpireHold(ctx, *entry.BookingID); err != nil {
+				return err
+			}
+		}
+
+		return u.waitRepo.UpdateStatus(ctx, entry.ID, string(dto.WaitlistCancelled))
+	})
+}
+
+// AcceptOffer turns the offered seat into a regular pending booking, giving
+// the user the normal hold time to pay.
+func (u *waitlistUsecase) AcceptOffer(ctx context.Context, eventID, userID int64) (*dto.BookingResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	var bookingID int64
+
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		entry, err := u.openOffer(ctx, eventID, userID)
+		if err != nil {
+			return err
+		}
+
+		bookingID = *entry.BookingID
+		if err := u.bookRepo.ExtendHold(ctx, bookingID, time.Now().Add(bookingHoldTTL)); err != nil {
+			return err
+		}
+
+		return u.waitRepo.UpdateStatus(ctx, entry.ID, string(dto.WaitlistAccepted))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.bookRepo.GetByID(ctx, bookingID)
+}
+
+// DeclineOffer releases the offered seat; the runner offers it to the next
+// person in line.
+func (u *waitlistUsecase) DeclineOffer(ctx context.Context, eventID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		entry, err := u.openOffer(ctx, eventID, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.bookRepo.ExpireHold(ctx, *entry.BookingID); err != nil {
+			return err
+		}
+
+		return u.waitRepo.UpdateStatus(ctx, entry.ID, string(dto.WaitlistDeclined))
+	})
+}
+
+// ProcessWaitlists closes lapsed offers and offers every seat that has been
+// freed since the last run, by cancellation, refund or hold expiry, to the
+// next person in line. It returns the number of offers made.
+func (u *waitlistUsecase) ProcessWaitlists(ctx context.Context) (int64, error) {
+	err := u.expireOffers(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	events, err := u.waitRepo.ListWaitingEvents(listCtx)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	var offers int64
+	for _, eventID := range events {
+		for i := 0; i < maxOffersPerRun; i++ {
+			offered, err := u.offerNext(ctx, eventID)
+			if err != nil {
+				return offers, fmt.Errorf("event %d: %w", eventID, err)
+			}
+			if !offered {
+				break
+			}
+			offers++
+		}
+	}
+
+	return offers, nil
+}
+
+func (u *waitlistUsecase) expireOffers(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		lapsed, err := u.waitRepo.ExpireOffers(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range lapsed {
+			if err := u.bookRepo.ExpireHold(ctx, *entry.BookingID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// offerNext holds a free seat for the first servable person in line and
+// tells them about it. It reports false once nobody more can be served.
+func (u *waitlistUsecase) offerNext(ctx context.Context, eventID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeOut)
+	defer cancel()
+
+	var entryID int64
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		entry, err := u.waitRepo.NextServableForUpdate(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		entryID = entry.ID
+
+		// sales may have closed since the user joined
+		if err := u.holder.checkOnSale(ctx, eventID, entry.UserID); err != nil {
+			return err
+		}
+
+		seat, err := u.seatRepo.FindAvailableSeat(ctx, eventID, entry.SectionID)
+		if err != nil {
+			return err
+		}
+
+		event, err := u.eventRepo.GetEventForShare(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		expiresAt := time.Now().Add(waitlistOfferTTL)
+		booking, err := u.holder.hold(ctx, &domain.Booking{
+			UserID:  entry.UserID,
+			EventID: eventID,
+			SeatID:  seat.ID,
+		}, expiresAt, nil)
+		if err != nil {
+			return err
+		}
+
+		entry.SeatID = &seat.ID
+		entry.BookingID = &booking.ID
+		entry.OfferExpiresAt = &expiresAt
+		if err := u.waitRepo.MarkOffered(ctx, entry); err != nil {
+			return err
+		}
+
+		return u.notifyRepo.Create(ctx, &domain.Notification{
+			UserID:  entry.UserID,
+			EventID: &eventID,
+			Kind:    notification.KindWaitlistOffer,
+			RefID:   entry.ID,
+			Subject: fmt.Sprintf("A seat for %s is waiting for you", event.Name),
+			Body: fmt.Sprintf(
+				"Seat %s%d is held for you until %s. Accept the offer before then to keep it.",
+				seat.RowLabel, seat.SeatNumber, expiresAt.Format("2 Jan 2006 15:04"),
+			),
+		})
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, errs.ErrWaitlistEntryNotFound),
+		errors.Is(err, errs.ErrSeatNotFound),
+		errors.Is(err, errs.ErrSeatAlreadyBooked),
+		errors.Is(err, errs.ErrTicketTypeSoldOut),
+		errors.Is(err, errs.ErrEventNotOnSale):
+		// nothing to offer right now, try again on the next run
+		return false, nil
+	case errors.Is(err, errs.ErrEventTicketLimit),
+		errors.Is(err, errs.ErrTicketTypeLimit),
+		errors.Is(err, errs.ErrEmailNotVerified):
+		// the user cannot buy this ticket, so move on to the next in line
+		if err := u.waitRepo.UpdateStatus(ctx, entryID, string(dto.WaitlistCancelled)); err != nil {
+			return false, err
+		}
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
+func (u *waitlistUsecase) openOffer(ctx context.Context, eventID, userID int64) (*domain.WaitlistEntry, error) {
+	entry, err := u.waitRepo.GetActiveForUpdate(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Status != string(dto.WaitlistOffered) || entry.BookingID == nil ||
+		!entry.OfferExpiresAt.After(time.Now()) {
+		return nil, errs.ErrNoWaitlistOffer
+	}
+
+	return entry, nil
+}
+
+func (u *waitlistUsecase) response(ctx context.Context, entry *domain.WaitlistEntry) (*dto.WaitlistResponse, error) {
+	res := &dto.WaitlistResponse{WaitlistEntry: entry}
+
+	if entry.Status == string(dto.WaitlistWaiting) {
+		position, err := u.waitRepo.Position(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+		res.Position = position
+	}
+
+	return res, nil
+}
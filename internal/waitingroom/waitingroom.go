@@ -0,0 +1,64 @@
+// Package waitingroom meters users into the booking flow of high-demand
+// events. Users join an event's queue, are admitted at the event's rate and
+// then book with a signed admission token.
+package waitingroom
+
+import (
+	"context"
+	"time"
+
+	"github.com/codepnw/go-ticket-booking/internal/domain"
+)
+
+// TokenHeader carries the admission token on booking requests.
+const TokenHeader = "X-Admission-Token"
+
+// Entry statuses. An admitted entry whose admission ran out reads as
+// expired; joining again puts the user at the back of the line.
+const (
+	StatusWaiting  = "waiting"
+	StatusAdmitted = "admitted"
+	StatusExpired  = "expired"
+)
+
+// Store keeps queue state. It must survive restarts and be shared by every
+// server instance; the Postgres implementation is repository.QueueRepository.
+type Store interface {
+	GetQueue(ctx context.Context, eventID int64) (*domain.EventQueue, error)
+	GetQueueForUpdate(ctx context.Context, eventID int64) (*domain.EventQueue, error)
+	SaveQueue(ctx context.Context, q *domain.EventQueue) error
+	ListEnabledQueues(ctx context.Context) ([]int64, error)
+	SetAdmissionClock(ctx context.Context, eventID int64, at time.Time) error
+	// Join adds the user at the back of the line unless they hold a place
+	// already, in which case it returns nil.
+	Join(ctx context.Context, eventID, userID int64) (*domain.QueueEntry, error)
+	GetEntry(ctx context.Context, eventID, userID int64) (*domain.QueueEntry, error)
+	CountAhead(ctx context.Context, eventID, position int64) (int64, error)
+	AdmitNext(ctx context.Context, eventID int64, n int, expiresAt time.Time) (int64, error)
+}
+
+// Allowance returns how many users may be admitted at now, at ratePerMinute
+// since the admission clock last stood at clock, and where the clock moves
+// to once they are. Credit left over from idle time is capped at a minute
+// so an idle queue does not let a burst through.
+func Allowance(ratePerMinute int, clock, now time.Time) (int, time.Time) {
+	if ratePerMinute <= 0 || !now.After(clock) {
+		return 0, clock
+	}
+
+	if earliest := now.Add(-time.Minute); clock.Before(earliest) {
+		clock = earliest
+	}
+
+	every := time.Minute / time.Duration(ratePerMinute)
+	n := int(now.Sub(clock) / every)
+	return n, clock.Add(time.Duration(n) * every)
+}
+
+// EntryStatus reports the entry's status as of now.
+func EntryStatus(e *domain.QueueEntry, now time.Time) string {
+	if e.Status == StatusAdmitted && e.ExpiresAt != nil && !now.Before(*e.ExpiresAt) {
+		return StatusExpired
+	}
+	return e.Status
+}
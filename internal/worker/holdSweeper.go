@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const holdSweepInterval = time.Minute
+
+// HoldExpirer is implemented by usecases that keep time-limited holds.
+type HoldExpirer interface {
+	ExpireHolds(ctx context.Context) (int64, error)
+}
+
+// StartHoldSweeper periodically expires pending holds whose time has passed,
+// until ctx is cancelled.
+func StartHoldSweeper(ctx context.Context, expirers ...HoldExpirer) {
+	ticker := time.NewTicker(holdSweepInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, e := range expirers {
+					expired, err := e.ExpireHolds(ctx)
+					if err != nil {
+						log.Printf("expire holds failed: %v", err)
+						continue
+					}
+					if expired > 0 {
+						log.Printf("expired %d holds", expired)
+					}
+				}
+			}
+		}
+	}()
+}
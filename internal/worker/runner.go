@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	cancellationInterval = time.Second * 10
+	notificationInterval = time.Second * 15
+	waitlistInterval     = time.Second * 10
+	seatChangeInterval   = time.Hour
+	queueInterval        = time.Second * 5
+	paymentInterval      = time.Minute
+	refundInterval       = time.Minute
+)
+
+// EventCanceller finishes event cancellations left running.
+type EventCanceller interface {
+	ResumeCancellations(ctx context.Context) (int64, error)
+}
+
+// NotificationDispatcher delivers queued notifications.
+type NotificationDispatcher interface {
+	DispatchPending(ctx context.Context) (int64, error)
+}
+
+// WaitlistProcessor offers released seats to waiting users.
+type WaitlistProcessor interface {
+	ProcessWaitlists(ctx context.Context) (int64, error)
+}
+
+// SeatChangePruner deletes seat changes too old to resume from.
+type SeatChangePruner interface {
+	PruneSeatChanges(ctx context.Context) (int64, error)
+}
+
+// QueueAdmitter lets queued users into the booking flow.
+type QueueAdmitter interface {
+	AdmitQueued(ctx context.Context) (int64, error)
+}
+
+// PaymentSettler finishes captures and refunds the provider did not
+// complete.
+type PaymentSettler interface {
+	SettlePayments(ctx context.Context) (int64, error)
+}
+
+// RefundRetrier pays out refunds the provider did not pay yet.
+type RefundRetrier interface {
+	RetryRefunds(ctx context.Context) (int64, error)
+}
+
+// StartWaitlistRunner rolls lapsed offers over and offers freed seats to the
+// next people in line until ctx is cancelled.
+func StartWaitlistRunner(ctx context.Context, p WaitlistProcessor) {
+	runEvery(ctx, waitlistInterval, "waitlist", "made %d waitlist offers", p.ProcessWaitlists)
+}
+
+// StartCancellationRunner resumes unfinished event cancellations right away,
+// which picks up work interrupted by a restart, and then on every tick.
+func StartCancellationRunner(ctx context.Context, c EventCanceller) {
+	runEvery(ctx, cancellationInterval, "event cancellation", "released %d bookings", c.ResumeCancellations)
+}
+
+// StartNotificationDispatcher sends queued notifications until ctx is cancelled.
+func StartNotificationDispatcher(ctx context.Context, d NotificationDispatcher) {
+	runEvery(ctx, notificationInterval, "notification dispatch", "sent %d notifications", d.DispatchPending)
+}
+
+// StartSeatChangePruner trims the seat change log until ctx is cancelled.
+func StartSeatChangePruner(ctx context.Context, p SeatChangePruner) {
+	runEvery(ctx, seatChangeInterval, "seat change pruning", "deleted %d seat changes", p.PruneSeatChanges)
+}
+
+// StartQueueAdmitter admits users from waiting rooms at each room's rate
+// until ctx is cancelled.
+func StartQueueAdmitter(ctx context.Context, a QueueAdmitter) {
+	runEvery(ctx, queueInterval, "queue admission", "admitted %d users", a.AdmitQueued)
+}
+
+// StartPaymentSettler retries pending provider captures and refunds until
+// ctx is cancelled.
+func StartPaymentSettler(ctx context.Context, s PaymentSettler) {
+	runEvery(ctx, paymentInterval, "payment settlement", "settled %d payments", s.SettlePayments)
+}
+
+func runEvery(ctx context.Context, interval time.Duration, name, done string, fn func(ctx context.Context) (int64, error)) {
+	run := func() {
+		n, err := fn(ctx)
+		if err != nil {
+			log.Printf("%s failed: %v", name, err)
+		}
+		if n > 0 {
+			log.Printf(done, n)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		run()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
+
+// StartRefundRetrier retries pending refunds at the provider until ctx is
+// cancelled.
+func StartRefundRetrier(ctx context.Context, r RefundRetrier) {
+	runEvery(ctx, refundInterval, "refund retry", "paid out %d refunds", r.RetryRefunds)
+}